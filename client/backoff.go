@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffPolicy decides how transports retry after losing their
+// connection: the SSE client reconnects its stream and the stdio client
+// restarts the server process. Zero fields take the defaults of
+// DefaultBackoffPolicy.
+type BackoffPolicy struct {
+	// Initial is the delay before the first retry
+	Initial time.Duration
+	// Max caps the delay between retries
+	Max time.Duration
+	// Multiplier grows the delay after every failed attempt
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it, so many
+	// clients do not retry in lockstep
+	Jitter float64
+	// MaxAttempts stops retrying after this many attempts. Zero retries
+	// until the client is closed.
+	MaxAttempts int
+	// OnRetry is called before each attempt is made, with the error that
+	// caused it
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultBackoffPolicy retries from half a second up to thirty seconds,
+// doubling with 20% jitter, until the client is closed
+func DefaultBackoffPolicy() BackoffPolicy {
+	return BackoffPolicy{
+		Initial:    500 * time.Millisecond,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// WithBackoff makes the client recover from a lost connection, retrying
+// as policy describes. Without it a lost connection is final.
+func WithBackoff(policy BackoffPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.backoff = &policy
+	}
+}
+
+// Delay returns how long to wait before attempt, counting from 1
+func (p BackoffPolicy) Delay(attempt int) time.Duration {
+	defaults := DefaultBackoffPolicy()
+	initial, maxDelay, multiplier := p.Initial, p.Max, p.Multiplier
+	if initial <= 0 {
+		initial = defaults.Initial
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaults.Max
+	}
+	if multiplier < 1 {
+		multiplier = defaults.Multiplier
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(max(attempt-1, 0)))
+	delay = min(delay, float64(maxDelay))
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retry calls attempt until it succeeds, the policy gives up or ctx ends.
+// cause is the error that made the retry necessary.
+func (p BackoffPolicy) retry(ctx context.Context, cause error, attempt func() error) error {
+	err := cause
+	for n := 1; p.MaxAttempts == 0 || n <= p.MaxAttempts; n++ {
+		delay := p.Delay(n)
+		if p.OnRetry != nil {
+			p.OnRetry(n, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err = attempt(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("gave up after %d attempts: %w", p.MaxAttempts, err)
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffPolicyDelay(t *testing.T) {
+	policy := BackoffPolicy{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 4*time.Second, policy.Delay(3))
+	assert.Equal(t, 5*time.Second, policy.Delay(4))
+
+	policy.Jitter = 0.5
+	for range 20 {
+		delay := policy.Delay(1)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+}
+
+func TestBackoffPolicyRetry(t *testing.T) {
+	var retries []int
+	policy := BackoffPolicy{
+		Initial:     time.Millisecond,
+		MaxAttempts: 3,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			retries = append(retries, attempt)
+		},
+	}
+
+	err := policy.retry(context.Background(), errors.New("lost"), func() error {
+		return errors.New("still down")
+	})
+	assert.ErrorContains(t, err, "still down")
+	assert.Equal(t, []int{1, 2, 3}, retries)
+
+	calls := 0
+	err = policy.retry(context.Background(), errors.New("lost"), func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("still down")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = BackoffPolicy{Initial: time.Hour}.retry(ctx, nil, func() error { return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSSEMCPClientReconnect(t *testing.T) {
+	var connections atomic.Int32
+	resumed := make(chan string, 1)
+	lastEventID := make(chan string, 1)
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := connections.Add(1)
+			if n > 1 {
+				lastEventID <- r.Header.Get("Last-Event-ID")
+				resumed <- r.Header.Get("Mcp-Session-Id")
+			}
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: endpoint\ndata: http://%s/message?sessionId=session-1\n\n", r.Host)
+			if n == 1 {
+				fmt.Fprint(w, "id: 7\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/test\"}\n\n")
+			}
+			w.(http.Flusher).Flush()
+			if n == 1 {
+				// Drop the first stream right away
+				return
+			}
+			<-r.Context().Done()
+		},
+	))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var retries int
+	client, err := NewSSEMCPClient(testServer.URL+"/sse", WithBackoff(BackoffPolicy{
+		Initial: 10 * time.Millisecond,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			mu.Lock()
+			retries++
+			mu.Unlock()
+		},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+
+	select {
+	case sessionID := <-resumed:
+		assert.Equal(t, "session-1", sessionID)
+		assert.Equal(t, "7", <-lastEventID)
+	case <-ctx.Done():
+		t.Fatal("client did not reconnect")
+	}
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	mu.Lock()
+	assert.Equal(t, 1, retries)
+	mu.Unlock()
+
+	require.NoError(t, client.Close())
+	client.Wait()
+	assert.Equal(t, int32(2), connections.Load())
+}
+
+func TestSSEMCPClientReconnectFailureLogged(t *testing.T) {
+	var connections atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if connections.Add(1) > 1 {
+				http.Error(w, "gone", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: endpoint\ndata: http://%s/message\n\n", r.Host)
+			w.(http.Flusher).Flush()
+		},
+	))
+	defer testServer.Close()
+
+	var logs syncBuffer
+	client, err := NewSSEMCPClient(
+		testServer.URL+"/sse",
+		WithBackoff(BackoffPolicy{Initial: time.Millisecond, MaxAttempts: 2}),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Start(ctx))
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "failed to reconnect SSE stream")
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestStdioMCPClientRestart(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_restart")
+	require.NoError(t, compileTestServer(mockServerPath))
+	defer os.Remove(mockServerPath)
+
+	var retries atomic.Int32
+	var logs syncBuffer
+	client, err := NewStdioMCPClientWithOptions(
+		mockServerPath,
+		nil,
+		WithBackoff(BackoffPolicy{
+			Initial: 10 * time.Millisecond,
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				retries.Add(1)
+			},
+		}),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	_, err = client.CallTool(ctx, "crash", nil)
+	assert.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := client.CallTool(ctx, "test-tool", nil)
+		return err == nil
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, int32(1), retries.Load())
+	// The server exiting is expected, the restarted one initializes cleanly
+	assert.Empty(t, logs.String())
+}
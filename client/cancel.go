@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ErrRequestCancelled is returned by a request stopped with CancelRequest
+var ErrRequestCancelled = errors.New("request cancelled")
+
+type requestIDKey struct{}
+
+// withRequestID makes the next request sent with ctx use id, which an async
+// call has already handed to its caller and tracked
+func withRequestID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// outstandingRequests holds a cancel function for every request that is
+// waiting for its response
+type outstandingRequests struct {
+	mu      sync.Mutex
+	cancels map[int64]context.CancelCauseFunc
+}
+
+func newOutstandingRequests() *outstandingRequests {
+	return &outstandingRequests{cancels: make(map[int64]context.CancelCauseFunc)}
+}
+
+// track returns a context that CancelRequest can cancel and a function to
+// call once the request is finished
+func (o *outstandingRequests) track(ctx context.Context, id int64) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	o.mu.Lock()
+	o.cancels[id] = cancel
+	o.mu.Unlock()
+	return ctx, func() {
+		o.mu.Lock()
+		delete(o.cancels, id)
+		o.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// start returns the ID of a request about to be sent with ctx, tracked
+// until the returned function is called. An ID reserved in ctx is already
+// tracked by its async call.
+func (o *outstandingRequests) start(
+	ctx context.Context,
+	counter *atomic.Int64,
+) (context.Context, int64, func()) {
+	if id, ok := ctx.Value(requestIDKey{}).(int64); ok {
+		return ctx, id, func() {}
+	}
+	id := counter.Add(1)
+	ctx, untrack := o.track(ctx, id)
+	return ctx, id, untrack
+}
+
+// cancel stops the request locally. It reports false if the request is
+// not outstanding.
+func (o *outstandingRequests) cancel(id int64, reason string) bool {
+	o.mu.Lock()
+	cancel, ok := o.cancels[id]
+	delete(o.cancels, id)
+	o.mu.Unlock()
+	if !ok {
+		return false
+	}
+	cause := ErrRequestCancelled
+	if reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrRequestCancelled, reason)
+	}
+	cancel(cause)
+	return true
+}
+
+// cancelRequest implements CancelRequest for both clients
+func cancelRequest(
+	ctx context.Context,
+	c MCPClient,
+	outstanding *outstandingRequests,
+	id int64,
+	reason string,
+) error {
+	if !outstanding.cancel(id, reason) {
+		return fmt.Errorf("no outstanding request with ID %d", id)
+	}
+
+	params := mcp.CancelledNotificationParams{
+		RequestId: mcp.RequestId(id),
+		Reason:    reason,
+	}
+	return c.SendNotification(ctx, mcp.MethodNotificationCancelled, params)
+}
+
+// abandonRequest returns the error for a request whose ctx ended before
+// the response arrived, telling the server to stop unless CancelRequest
+// already did
+func abandonRequest(ctx context.Context, c MCPClient, id int64) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrRequestCancelled) {
+		return cause
+	}
+	notifyCancelled(ctx, c, id, ctx.Err())
+	return ctx.Err()
+}
+
+// PendingCall is a tool call started with CallToolAsync
+type PendingCall struct {
+	// ID is the JSON-RPC request ID, for use with CancelRequest
+	ID int64
+
+	done   chan struct{}
+	result *mcp.CallToolResult
+	err    error
+}
+
+// callToolAsync reserves an ID for call and runs it in the background. The
+// ID is tracked before it is returned, so CancelRequest works right away.
+func callToolAsync(
+	ctx context.Context,
+	counter *atomic.Int64,
+	outstanding *outstandingRequests,
+	call func(ctx context.Context) (*mcp.CallToolResult, error),
+) *PendingCall {
+	id := counter.Add(1)
+	ctx, untrack := outstanding.track(ctx, id)
+	pending := &PendingCall{ID: id, done: make(chan struct{})}
+	go func() {
+		defer close(pending.done)
+		defer untrack()
+		pending.result, pending.err = call(withRequestID(ctx, id))
+	}()
+	return pending
+}
+
+// Done returns a channel that is closed when the call has finished
+func (p *PendingCall) Done() <-chan struct{} {
+	return p.done
+}
+
+// Result blocks until the call has finished and returns its outcome
+func (p *PendingCall) Result() (*mcp.CallToolResult, error) {
+	<-p.done
+	return p.result, p.err
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/huangyul/go-mcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEMCPClientCancelRequest(t *testing.T) {
+	recorder := &paramsRecorder{
+		MCPServer: server.NewDefaultServer("test-server", "1.0.0", server.WithDiagnostics()),
+		params:    make(map[string]json.RawMessage),
+	}
+	_, testServer := server.NewTestServer(recorder)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	assert.Error(t, client.CancelRequest(ctx, 1000, "unknown"))
+
+	call := client.CallToolAsync(ctx, "mcp_sleep", map[string]interface{}{
+		"duration_ms": 30000,
+	})
+	assert.NotZero(t, call.ID)
+
+	// Wait until the server is working on the call before cancelling it
+	require.Eventually(t, func() bool {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		_, ok := recorder.params["tools/call"]
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, client.CancelRequest(ctx, call.ID, "user pressed stop"))
+
+	select {
+	case <-call.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not finish after CancelRequest")
+	}
+	_, err = call.Result()
+	assert.ErrorIs(t, err, ErrRequestCancelled)
+	assert.ErrorContains(t, err, "user pressed stop")
+
+	// A call can be cancelled as soon as CallToolAsync returns
+	immediate := client.CallToolAsync(ctx, "mcp_sleep", map[string]interface{}{
+		"duration_ms": 30000,
+	})
+	require.NoError(t, client.CancelRequest(ctx, immediate.ID, "changed my mind"))
+	select {
+	case <-immediate.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not finish after CancelRequest")
+	}
+	_, err = immediate.Result()
+	assert.ErrorIs(t, err, ErrRequestCancelled)
+	assert.ErrorContains(t, err, "changed my mind")
+
+	require.Eventually(t, func() bool {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		_, ok := recorder.params["notifications/cancelled"]
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	var params struct {
+		RequestID int64  `json:"requestId"`
+		Reason    string `json:"reason"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.params["notifications/cancelled"], &params))
+	assert.Equal(t, call.ID, params.RequestID)
+	assert.Equal(t, "user pressed stop", params.Reason)
+}
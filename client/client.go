@@ -2,6 +2,10 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
 
 	"github.com/huangyul/go-mcp/mcp"
 )
@@ -16,26 +20,52 @@ type MCPClient interface {
 		protocolVersion string,
 	) (*mcp.InitializeResult, error)
 
+	// SendNotification sends a JSON-RPC notification, which gets no response
+	SendNotification(ctx context.Context, method string, params any) error
+
 	// Ping checks if the server is alive
 	Ping(ctx context.Context) error
 
 	// ListResources requests a list of available resources from the server
 	ListResources(
 		ctx context.Context,
-		cursor *string,
+		cursor mcp.Cursor,
 	) (*mcp.ListResourcesResult, error)
 
+	// ListResourceTemplates requests a list of available resource templates
+	ListResourceTemplates(
+		ctx context.Context,
+		cursor mcp.Cursor,
+	) (*mcp.ListResourceTemplatesResult, error)
+
 	// ReadResource reads a specific resource from the server
 	ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
 
+	// ReadResourceWithProgress reads a resource and reports byte progress
+	// for huge resources. Cancelling ctx stops the read on the server.
+	ReadResourceWithProgress(
+		ctx context.Context,
+		uri string,
+		onProgress ReadProgressFunc,
+	) (*mcp.ReadResourceResult, error)
+
 	// Subscribe requests notifications for changes to a specific resource
 	Subscribe(ctx context.Context, uri string) error
 
+	// SubscribeWithHandler subscribes to a resource and calls handler with
+	// its URI whenever the server reports it updated. Handlers run one at a
+	// time off the reading goroutine, so they may call the client.
+	SubscribeWithHandler(ctx context.Context, uri string, handler ResourceUpdatedFunc) error
+
+	// SubscribeChannel subscribes to a resource and delivers its URI on the
+	// returned channel whenever the server reports it updated
+	SubscribeChannel(ctx context.Context, uri string) (<-chan string, error)
+
 	// Unsubscribe cancels notifications for a specific resource
 	Unsubscribe(ctx context.Context, uri string) error
 
 	// ListPrompts requests a list of available prompts from the server
-	ListPrompts(ctx context.Context, cursor *string) (*mcp.ListPromptsResult, error)
+	ListPrompts(ctx context.Context, cursor mcp.Cursor) (*mcp.ListPromptsResult, error)
 
 	// GetPrompt retrieves a specific prompt from the server
 	GetPrompt(
@@ -45,7 +75,7 @@ type MCPClient interface {
 	) (*mcp.GetPromptResult, error)
 
 	// ListTools requests a list of available tools from the server
-	ListTools(ctx context.Context, cursor *string) (*mcp.ListToolsResult, error)
+	ListTools(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolDefinitionsResult, error)
 
 	// CallTool invokes a specific tool on the server
 	CallTool(
@@ -54,6 +84,24 @@ type MCPClient interface {
 		arguments map[string]interface{},
 	) (*mcp.CallToolResult, error)
 
+	// CallToolRaw invokes a tool with pre-encoded JSON arguments
+	CallToolRaw(
+		ctx context.Context,
+		name string,
+		arguments json.RawMessage,
+	) (*mcp.CallToolResult, error)
+
+	// CallToolAsync starts a tool call in the background and returns it
+	// with its request ID
+	CallToolAsync(
+		ctx context.Context,
+		name string,
+		arguments map[string]interface{},
+	) *PendingCall
+
+	// CancelRequest stops an outstanding request and tells the server why
+	CancelRequest(ctx context.Context, id int64, reason string) error
+
 	// SetLevel sets the logging level for the server
 	SetLevel(ctx context.Context, level mcp.LoggingLevel) error
 
@@ -63,4 +111,145 @@ type MCPClient interface {
 		ref interface{},
 		argument mcp.CompleteRequestParamsArgument,
 	) (*mcp.CompleteResult, error)
+
+	// CompletePrompt requests completions for an argument of a prompt
+	CompletePrompt(
+		ctx context.Context,
+		promptName string,
+		argument mcp.CompleteRequestParamsArgument,
+	) (*mcp.CompleteResult, error)
+
+	// CompleteResourceTemplate requests completions for a variable of a
+	// resource URI template
+	CompleteResourceTemplate(
+		ctx context.Context,
+		uriTemplate string,
+		argument mcp.CompleteRequestParamsArgument,
+	) (*mcp.CompleteResult, error)
+}
+
+// ClientOption configures optional client behavior
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	skipInitializedNotification bool
+	onSessionID                 func(sessionID string)
+	tracer                      Tracer
+	framing                     StdioFraming
+	maxMessageSize              int
+	logger                      *slog.Logger
+	logHandler                  LogHandler
+	backoff                     *BackoffPolicy
+}
+
+func newClientOptions(opts []ClientOption) clientOptions {
+	o := clientOptions{
+		maxMessageSize: defaultMaxMessageSize,
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithoutInitializedNotification stops Initialize from sending
+// notifications/initialized, for servers that predate the lifecycle spec
+func WithoutInitializedNotification() ClientOption {
+	return func(o *clientOptions) {
+		o.skipInitializedNotification = true
+	}
+}
+
+// WithSessionIDHandler calls fn whenever an HTTP client is assigned a
+// session ID, so it can be persisted and passed to Resume later
+func WithSessionIDHandler(fn func(sessionID string)) ClientOption {
+	return func(o *clientOptions) {
+		o.onSessionID = fn
+	}
+}
+
+// WithLogger logs errors the client cannot return to a caller, such as
+// malformed notifications from the server, to logger. They go to
+// slog.Default otherwise. Server log entries are delivered with
+// WithLogHandler instead.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// background tracks the goroutines a client owns, so Wait can block until
+// they have all returned after Close
+type background struct {
+	wg      sync.WaitGroup
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func newBackground() *background {
+	return &background{stopped: make(chan struct{})}
+}
+
+// Go runs fn in a tracked goroutine
+func (b *background) Go(fn func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn()
+	}()
+}
+
+// stop closes the stopped channel once every tracked goroutine has returned.
+// It does not block, so it is safe to call from a tracked goroutine.
+func (b *background) stop() {
+	b.once.Do(func() {
+		go func() {
+			b.wg.Wait()
+			close(b.stopped)
+		}()
+	})
+}
+
+// contextUntil returns a context that is also cancelled once done closes
+func contextUntil(parent context.Context, done <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// response is the outcome of a request: its result, or the error the
+// server answered with
+type response struct {
+	result json.RawMessage
+	err    error
+}
+
+// errRequestFailed fails requests whose connection went away before they
+// were answered
+var errRequestFailed = errors.New("request failed")
+
+// newResponse returns the outcome of a request the server answered with
+// result or rpcErr
+func newResponse(result json.RawMessage, rpcErr *mcp.JSONRPCErrorError) *response {
+	if rpcErr != nil {
+		return &response{err: rpcErr}
+	}
+	return &response{result: result}
+}
+
+// deliverResponse hands a response to the caller waiting on ch. Response
+// channels hold one value, so it never blocks a read loop: a second value
+// for the same request is dropped.
+func deliverResponse(ch chan *response, r *response) {
+	select {
+	case ch <- r:
+	default:
+	}
 }
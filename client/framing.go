@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/huangyul/go-mcp/internal/framing"
+)
+
+// StdioFraming selects how messages are delimited on a stdio connection
+type StdioFraming int
+
+const (
+	// FramingNewline writes newline-delimited JSON, as the spec requires.
+	// It is the default.
+	FramingNewline StdioFraming = iota
+	// FramingContentLength writes LSP style Content-Length framing, for
+	// servers that reuse LSP plumbing
+	FramingContentLength
+)
+
+// defaultMaxMessageSize bounds the size of a message read from a server
+// when no limit is configured
+const defaultMaxMessageSize = 4 << 20
+
+// errMessageTooLarge reports a message over the size limit, which was
+// skipped
+var errMessageTooLarge = errors.New("message too large")
+
+// WithStdioFraming sets the framing used to write to a stdio server,
+// FramingNewline by default. A server cannot be relied on to speak first,
+// so servers that only understand Content-Length framing must be chosen
+// explicitly. Reading always accepts both framings.
+func WithStdioFraming(framing StdioFraming) ClientOption {
+	return func(o *clientOptions) {
+		o.framing = framing
+	}
+}
+
+// WithMaxMessageSize bounds the size in bytes of a message read from a
+// stdio server. A larger message is skipped without being buffered, and
+// as its request cannot be told, every request waiting for a response
+// fails. It defaults to 4 MiB, where clients used to read messages of any
+// size; zero or less lifts the limit.
+func WithMaxMessageSize(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxMessageSize = size
+	}
+}
+
+// readFrame reads one message of at most max bytes in either framing and
+// reports whether it was Content-Length framed. A larger message is
+// discarded and errMessageTooLarge returned, reading can carry on with
+// the next one.
+func readFrame(r *bufio.Reader, max int) ([]byte, bool, error) {
+	for {
+		line, err := readLine(r, max)
+		if err != nil && (line == "" || errors.Is(err, errMessageTooLarge)) {
+			return nil, false, err
+		}
+
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "" {
+			continue
+		}
+		if !framing.HasContentLength(trimmed) {
+			return []byte(trimmed), false, nil
+		}
+
+		length, err := framing.ReadHeaders(r, trimmed)
+		if err != nil {
+			return nil, true, err
+		}
+		if max > 0 && length > max {
+			if _, err := r.Discard(length); err != nil {
+				return nil, true, fmt.Errorf("failed to read message body: %w", err)
+			}
+			return nil, true, errMessageTooLarge
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, true, fmt.Errorf("failed to read message body: %w", err)
+		}
+		return body, true, nil
+	}
+}
+
+// readLine reads a line of at most max bytes, counting the newline, without
+// buffering more than that. The rest of a longer line is discarded and
+// errMessageTooLarge returned.
+func readLine(r *bufio.Reader, max int) (string, error) {
+	var line []byte
+	tooLarge := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if max > 0 && len(line)+len(chunk) > max {
+			tooLarge, line = true, nil
+		}
+		if !tooLarge {
+			line = append(line, chunk...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if tooLarge && err == nil {
+			return "", errMessageTooLarge
+		}
+		return string(line), err
+	}
+}
+
+// writeFrame writes data in the requested framing
+func writeFrame(w io.Writer, data []byte, contentLength bool) error {
+	if contentLength {
+		header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))
+		_, err := w.Write(append([]byte(header), data...))
+		return err
+	}
+	_, err := w.Write(append(data, '\n'))
+	return err
+}
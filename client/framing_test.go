@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	input := "{\"id\":1}\n" +
+		"Content-Length: 8\r\nContent-Type: application/json\r\n\r\n{\"id\":2}" +
+		"\n{\"id\":3}\n"
+	r := bufio.NewReader(strings.NewReader(input))
+
+	frame, framed, err := readFrame(r, 0)
+	require.NoError(t, err)
+	assert.False(t, framed)
+	assert.Equal(t, `{"id":1}`, string(frame))
+
+	frame, framed, err = readFrame(r, 0)
+	require.NoError(t, err)
+	assert.True(t, framed)
+	assert.Equal(t, `{"id":2}`, string(frame))
+
+	frame, framed, err = readFrame(r, 0)
+	require.NoError(t, err)
+	assert.False(t, framed)
+	assert.Equal(t, `{"id":3}`, string(frame))
+
+	_, _, err = readFrame(r, 0)
+	assert.Error(t, err)
+}
+
+func TestReadFrameLimit(t *testing.T) {
+	// Larger messages are skipped and the next one is read
+	for _, input := range []string{
+		"{\"data\":\"" + strings.Repeat("x", 64) + "\"}\n",
+		"Content-Length: 100\r\n\r\n" + strings.Repeat(" ", 100),
+	} {
+		r := bufio.NewReaderSize(strings.NewReader(input+"{\"id\":1}\n"), 16)
+		_, _, err := readFrame(r, 32)
+		assert.ErrorIs(t, err, errMessageTooLarge)
+
+		frame, _, err := readFrame(r, 32)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":1}`, string(frame))
+	}
+}
+
+func TestStdioMCPClientMessageTooLarge(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_large")
+	require.NoError(t, compileTestServer(mockServerPath))
+	defer os.Remove(mockServerPath)
+
+	var logs syncBuffer
+	client, err := NewStdioMCPClientWithOptions(
+		mockServerPath,
+		nil,
+		WithMaxMessageSize(32),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The initialize result is over the limit, the request fails rather
+	// than wait for it
+	_, err = client.Initialize(
+		context.Background(),
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	assert.ErrorIs(t, err, errRequestFailed)
+	assert.Contains(t, logs.String(), "skipped message over the size limit")
+}
+
+func TestWriteFrame(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, []byte(`{"id":1}`), false))
+	require.NoError(t, writeFrame(&buf, []byte(`{"id":2}`), true))
+	assert.Equal(
+		t,
+		"{\"id\":1}\nContent-Length: 8\r\n\r\n{\"id\":2}",
+		buf.String(),
+	)
+}
+
+func TestStdioMCPClientContentLengthFraming(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_server_lsp")
+	require.NoError(t, compileTestServer(mockServerPath))
+	defer os.Remove(mockServerPath)
+
+	client, err := NewStdioMCPClientWithOptions(
+		mockServerPath,
+		[]string{"--lsp"},
+		WithStdioFraming(FramingContentLength),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"1.0",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "mock-server", result.ServerInfo.Name)
+	require.NoError(t, client.Ping(ctx))
+}
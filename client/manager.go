@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ManagerSeparator joins a server name and an entity name, as in
+// "github.create_issue"
+const ManagerSeparator = "."
+
+// Manager connects to several MCP servers, which may use different
+// transports, and exposes their catalogs as one. Tools and prompts are
+// namespaced with the name the server was added under; resources keep their
+// URIs and are routed to the server that listed them.
+type Manager struct {
+	clientInfo      mcp.Implementation
+	capabilities    mcp.ClientCapabilities
+	protocolVersion string
+
+	mu      sync.RWMutex
+	servers map[string]*managedServer
+}
+
+type managedServer struct {
+	client   MCPClient
+	snapshot *ServerSnapshot
+}
+
+func NewManager(clientInfo mcp.Implementation, capabilities mcp.ClientCapabilities) *Manager {
+	return &Manager{
+		clientInfo:      clientInfo,
+		capabilities:    capabilities,
+		protocolVersion: mcp.LatestProtocolVersion,
+		servers:         make(map[string]*managedServer),
+	}
+}
+
+// Add initializes c and fetches its catalogs under name. SSE clients must be
+// started before they are added.
+func (m *Manager) Add(ctx context.Context, name string, c MCPClient) error {
+	if name == "" || strings.Contains(name, ManagerSeparator) {
+		return fmt.Errorf("invalid server name %q", name)
+	}
+
+	m.mu.RLock()
+	_, exists := m.servers[name]
+	m.mu.RUnlock()
+	if exists {
+		return fmt.Errorf("server %q already added", name)
+	}
+
+	initResult, err := c.Initialize(ctx, m.capabilities, m.clientInfo, m.protocolVersion)
+	if err != nil {
+		return fmt.Errorf("failed to initialize server %q: %w", name, err)
+	}
+	snapshot, err := takeSnapshot(ctx, c, initResult)
+	if err != nil {
+		return fmt.Errorf("failed to fetch catalogs of server %q: %w", name, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.servers[name]; exists {
+		return fmt.Errorf("server %q already added", name)
+	}
+	m.servers[name] = &managedServer{client: c, snapshot: snapshot}
+	return nil
+}
+
+// Refresh fetches the catalogs of the named server again, for use after it
+// reports a list change
+func (m *Manager) Refresh(ctx context.Context, name string) error {
+	// The snapshot is replaced by concurrent refreshes, read it locked
+	m.mu.RLock()
+	server, ok := m.servers[name]
+	var client MCPClient
+	var previous *ServerSnapshot
+	if ok {
+		client, previous = server.client, server.snapshot
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown server %q", name)
+	}
+
+	snapshot, err := takeSnapshot(ctx, client, &mcp.InitializeResult{
+		ServerInfo:      previous.ServerInfo,
+		ProtocolVersion: previous.ProtocolVersion,
+		Capabilities:    previous.Capabilities,
+		Instructions:    previous.Instructions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch catalogs of server %q: %w", name, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.servers[name]; ok && current.client == client {
+		current.snapshot = snapshot
+	}
+	return nil
+}
+
+// Remove drops the named server without closing its client. It reports
+// whether the server was known.
+func (m *Manager) Remove(name string) (MCPClient, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.servers[name]
+	if !ok {
+		return nil, false
+	}
+	delete(m.servers, name)
+	return server.client, true
+}
+
+// Client returns the client added under name
+func (m *Manager) Client(name string) (MCPClient, bool) {
+	server, err := m.server(name)
+	if err != nil {
+		return nil, false
+	}
+	return server.client, true
+}
+
+// Servers returns the names of all added servers in sorted order
+func (m *Manager) Servers() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListTools returns the tools of every server with namespaced names
+func (m *Manager) ListTools() []mcp.ToolDefinition {
+	tools := []mcp.ToolDefinition{}
+	m.each(func(name string, server *managedServer) {
+		for _, tool := range server.snapshot.Tools {
+			tool.Name = name + ManagerSeparator + tool.Name
+			tools = append(tools, tool)
+		}
+	})
+	return tools
+}
+
+// ListPrompts returns the prompts of every server with namespaced names
+func (m *Manager) ListPrompts() []mcp.Prompt {
+	prompts := []mcp.Prompt{}
+	m.each(func(name string, server *managedServer) {
+		for _, prompt := range server.snapshot.Prompts {
+			prompt.Name = name + ManagerSeparator + prompt.Name
+			prompts = append(prompts, prompt)
+		}
+	})
+	return prompts
+}
+
+// ListResources returns the resources of every server with namespaced
+// names. URIs are left as the server reported them.
+func (m *Manager) ListResources() []mcp.Resource {
+	resources := []mcp.Resource{}
+	m.each(func(name string, server *managedServer) {
+		for _, resource := range server.snapshot.Resources {
+			resource.Name = name + ManagerSeparator + resource.Name
+			resources = append(resources, resource)
+		}
+	})
+	return resources
+}
+
+// CallTool routes a namespaced tool call to the server that owns it
+func (m *Manager) CallTool(
+	ctx context.Context,
+	name string,
+	arguments map[string]interface{},
+) (*mcp.CallToolResult, error) {
+	server, tool, err := m.route(name)
+	if err != nil {
+		return nil, err
+	}
+	return server.client.CallTool(ctx, tool, arguments)
+}
+
+// GetPrompt routes a namespaced prompt request to the server that owns it
+func (m *Manager) GetPrompt(
+	ctx context.Context,
+	name string,
+	arguments map[string]string,
+) (*mcp.GetPromptResult, error) {
+	server, prompt, err := m.route(name)
+	if err != nil {
+		return nil, err
+	}
+	return server.client.GetPrompt(ctx, prompt, arguments)
+}
+
+// ReadResource reads uri from the first server, by name, that listed it
+func (m *Manager) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	var owner MCPClient
+	m.each(func(_ string, server *managedServer) {
+		if owner != nil {
+			return
+		}
+		for _, resource := range server.snapshot.Resources {
+			if resource.Uri == uri {
+				owner = server.client
+				return
+			}
+		}
+	})
+	if owner == nil {
+		return nil, fmt.Errorf("no server provides resource %q", uri)
+	}
+	return owner.ReadResource(ctx, uri)
+}
+
+// Close closes every added client that can be closed and forgets them all
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	servers := m.servers
+	m.servers = make(map[string]*managedServer)
+	m.mu.Unlock()
+
+	var errs []error
+	for name, server := range servers {
+		if closer, ok := server.client.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close server %q: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) server(name string) (*managedServer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	server, ok := m.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown server %q", name)
+	}
+	return server, nil
+}
+
+// route splits a namespaced name into its server and the server-local name
+func (m *Manager) route(name string) (*managedServer, string, error) {
+	serverName, local, ok := strings.Cut(name, ManagerSeparator)
+	if !ok || local == "" {
+		return nil, "", fmt.Errorf("name %q is not namespaced", name)
+	}
+	server, err := m.server(serverName)
+	if err != nil {
+		return nil, "", err
+	}
+	return server, local, nil
+}
+
+// each calls fn for every server in name order while holding the read lock
+func (m *Manager) each(fn func(name string, server *managedServer)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn(name, m.servers[name])
+	}
+}
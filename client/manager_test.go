@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/huangyul/go-mcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mockServerPath := filepath.Join("testdata", "mockstdio_manager")
+	require.NoError(t, compileTestServer(mockServerPath))
+	defer os.Remove(mockServerPath)
+
+	stdioClient, err := NewStdioMCPClient(mockServerPath)
+	require.NoError(t, err)
+
+	_, testServer := server.NewTestServer(
+		server.NewDefaultServer("test-server", "1.0.0", server.WithDiagnostics()),
+	)
+	defer testServer.Close()
+
+	sseClient, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, sseClient.Start(ctx))
+	require.NoError(t, waitForEndpoint(sseClient, 2*time.Second))
+
+	manager := NewManager(
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		mcp.ClientCapabilities{},
+	)
+	defer manager.Close()
+
+	require.NoError(t, manager.Add(ctx, "mock", stdioClient))
+	require.NoError(t, manager.Add(ctx, "diag", sseClient))
+	assert.Error(t, manager.Add(ctx, "mock", stdioClient))
+	assert.Error(t, manager.Add(ctx, "bad.name", stdioClient))
+	assert.Equal(t, []string{"diag", "mock"}, manager.Servers())
+
+	var names []string
+	for _, tool := range manager.ListTools() {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{
+		"diag.mcp_echo",
+		"diag.mcp_server_stats",
+		"diag.mcp_sleep",
+		"mock.test-tool",
+	}, names)
+
+	result, err := manager.CallTool(ctx, "mock.test-tool", nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Content, 1)
+
+	result, err = manager.CallTool(ctx, "diag.mcp_echo", map[string]interface{}{"x": "y"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"x":"y"`)
+
+	_, err = manager.CallTool(ctx, "missing.tool", nil)
+	assert.Error(t, err)
+	_, err = manager.CallTool(ctx, "test-tool", nil)
+	assert.Error(t, err)
+
+	// Refreshes may run concurrently with each other and with listing
+	var refreshes sync.WaitGroup
+	for range 3 {
+		refreshes.Add(1)
+		go func() {
+			defer refreshes.Done()
+			assert.NoError(t, manager.Refresh(ctx, "diag"))
+			assert.Len(t, manager.ListTools(), 4)
+		}()
+	}
+	refreshes.Wait()
+	assert.Error(t, manager.Refresh(ctx, "missing"))
+
+	prompts := manager.ListPrompts()
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "mock.test-prompt", prompts[0].Name)
+	prompt, err := manager.GetPrompt(ctx, "mock.test-prompt", nil)
+	require.NoError(t, err)
+	assert.Len(t, prompt.Messages, 1)
+
+	resources := manager.ListResources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "mock.test-resource", resources[0].Name)
+	contents, err := manager.ReadResource(ctx, "test://resource")
+	require.NoError(t, err)
+	assert.Len(t, contents.Contents, 1)
+	_, err = manager.ReadResource(ctx, "test://unknown")
+	assert.Error(t, err)
+
+	removed, ok := manager.Remove("mock")
+	assert.True(t, ok)
+	assert.Equal(t, MCPClient(stdioClient), removed)
+	assert.Len(t, manager.ListTools(), 3)
+	assert.NoError(t, stdioClient.Close())
+}
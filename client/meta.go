@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+type requestMetaKey struct{}
+
+// WithRequestMeta returns a context whose requests carry the fields of
+// meta in their _meta, such as a progress token or extension data. Fields
+// already on ctx are kept unless meta sets them too; trace context added
+// by the client takes precedence.
+func WithRequestMeta(ctx context.Context, meta mcp.Meta) context.Context {
+	merged := make(mcp.Meta)
+	for k, v := range requestMetaFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range meta {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, requestMetaKey{}, merged)
+}
+
+func requestMetaFromContext(ctx context.Context) mcp.Meta {
+	meta, _ := ctx.Value(requestMetaKey{}).(mcp.Meta)
+	return meta
+}
+
+// requestParams returns params with the _meta fields of ctx added
+func requestParams(ctx context.Context, params any) (any, error) {
+	meta := requestMetaFromContext(ctx)
+	if len(meta) == 0 {
+		return params, nil
+	}
+	withRequestMeta, err := withMeta(params, meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add _meta: %w", err)
+	}
+	return withRequestMeta, nil
+}
+
+// withMeta merges fields into the _meta object of params
+func withMeta(params any, fields mcp.Meta) (json.RawMessage, error) {
+	object := make(map[string]json.RawMessage)
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) != "null" {
+			if err := json.Unmarshal(data, &object); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	meta := make(mcp.Meta)
+	if existing, ok := object["_meta"]; ok {
+		if err := json.Unmarshal(existing, &meta); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range fields {
+		meta[k] = v
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	object["_meta"] = data
+	return json.Marshal(object)
+}
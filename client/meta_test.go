@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/huangyul/go-mcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMeta(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "meta", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			data, err := json.Marshal(server.RequestMetaFromContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(string(data))}}, nil
+		})
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	metaCtx := WithRequestMeta(ctx, mcp.Meta{"tenant": "acme", "progressToken": "a"})
+	metaCtx = WithRequestMeta(metaCtx, mcp.Meta{"progressToken": "b"})
+	result, err := client.CallTool(metaCtx, "meta", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tenant": "acme", "progressToken": "b"}`, result.Content[0].(mcp.TextContent).Text)
+
+	// Requests without _meta see none
+	result, err = client.CallTool(ctx, "meta", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null", result.Content[0].(mcp.TextContent).Text)
+}
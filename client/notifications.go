@@ -0,0 +1,350 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// LogHandler receives the log entries a server sends with
+// notifications/message
+type LogHandler func(params mcp.LoggingMessageNotificationParams)
+
+// WithLogHandler delivers server log entries to fn. fn runs on the
+// goroutine reading from the server, which waits for it to return, so a
+// slow fn should hand entries off rather than block.
+func WithLogHandler(fn LogHandler) ClientOption {
+	return func(o *clientOptions) {
+		o.logHandler = fn
+	}
+}
+
+// WithSlogLogger delivers server log entries to logger, mapping the MCP
+// levels onto slog levels
+func WithSlogLogger(logger *slog.Logger) ClientOption {
+	return WithLogHandler(func(params mcp.LoggingMessageNotificationParams) {
+		attrs := []slog.Attr{slog.String("mcpLevel", string(params.Level))}
+		if params.Logger != "" {
+			attrs = append(attrs, slog.String("logger", params.Logger))
+		}
+
+		msg, ok := params.Data.(string)
+		if !ok {
+			msg = "server log"
+			attrs = append(attrs, slog.Any("data", params.Data))
+		}
+		logger.LogAttrs(context.Background(), slogLevel(params.Level), msg, attrs...)
+	})
+}
+
+// slogLevel maps the eight syslog style MCP levels onto the four slog ones
+func slogLevel(level mcp.LoggingLevel) slog.Level {
+	switch level {
+	case mcp.LoggingLevelDebug:
+		return slog.LevelDebug
+	case mcp.LoggingLevelInfo:
+		return slog.LevelInfo
+	case mcp.LoggingLevelNotice:
+		return slog.LevelInfo + 2
+	case mcp.LoggingLevelWarning:
+		return slog.LevelWarn
+	case mcp.LoggingLevelError:
+		return slog.LevelError
+	case mcp.LoggingLevelCritical:
+		return slog.LevelError + 2
+	case mcp.LoggingLevelAlert:
+		return slog.LevelError + 4
+	case mcp.LoggingLevelEmergency:
+		return slog.LevelError + 6
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// cancelNotificationTimeout bounds how long notifying the server about an
+// abandoned request may take
+const cancelNotificationTimeout = 5 * time.Second
+
+// notifyCancelled tells the server to stop working on a request the caller
+// gave up on. It is best effort, the server may already have answered.
+func notifyCancelled(ctx context.Context, c MCPClient, id int64, cause error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelNotificationTimeout)
+	defer cancel()
+
+	params := mcp.CancelledNotificationParams{
+		RequestId: mcp.RequestId(id),
+		Reason:    cause.Error(),
+	}
+	_ = c.SendNotification(ctx, mcp.MethodNotificationCancelled, params)
+}
+
+// ReadProgressFunc receives the bytes read so far during a resource read.
+// total is 0 when the server does not know the size.
+type ReadProgressFunc func(read, total int64)
+
+// ResourceUpdatedFunc is called when a subscribed resource changes
+type ResourceUpdatedFunc func(uri string)
+
+// resourceSubscription is a handler or a channel waiting for updates to
+// one resource
+type resourceSubscription struct {
+	fn ResourceUpdatedFunc
+	ch chan string
+}
+
+// callbackQueue runs callbacks in order on a goroutine of its own, so the
+// goroutine reading from the server never waits for them and they may
+// make requests of their own
+type callbackQueue struct {
+	mu     sync.Mutex
+	queued []func()
+	closed bool
+	wake   chan struct{}
+	done   chan struct{}
+}
+
+// newCallbackQueue returns a queue whose run method the caller starts
+func newCallbackQueue() *callbackQueue {
+	return &callbackQueue{
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+}
+
+// push queues fn. Callbacks pushed after close are dropped.
+func (q *callbackQueue) push(fn func()) {
+	q.mu.Lock()
+	if !q.closed {
+		q.queued = append(q.queued, fn)
+	}
+	q.mu.Unlock()
+	q.signal()
+}
+
+// close makes run return once the callbacks already queued have run
+func (q *callbackQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *callbackQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *callbackQueue) run() {
+	defer close(q.done)
+	for range q.wake {
+		q.mu.Lock()
+		queued, closed := q.queued, q.closed
+		q.queued = nil
+		q.mu.Unlock()
+
+		for _, fn := range queued {
+			fn()
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// notificationRouter hands notifications from the server to the handlers
+// registered for them
+type notificationRouter struct {
+	options       clientOptions
+	mu            sync.Mutex
+	progress      map[mcp.AnyProgressToken]*progressTracker
+	nextToken     atomic.Int64
+	subscriptions map[string][]*resourceSubscription
+	// updates runs the resource update handlers, the client starts it
+	updates *callbackQueue
+}
+
+// progressTracker runs the progress callbacks of one read on their own
+// queue, which is drained before the read returns
+type progressTracker struct {
+	fn    ReadProgressFunc
+	queue *callbackQueue
+}
+
+func newNotificationRouter(options clientOptions) *notificationRouter {
+	return &notificationRouter{
+		options:       options,
+		progress:      make(map[mcp.AnyProgressToken]*progressTracker),
+		subscriptions: make(map[string][]*resourceSubscription),
+		updates:       newCallbackQueue(),
+	}
+}
+
+// close stops running resource update handlers once the queued ones ran
+func (r *notificationRouter) close() {
+	r.updates.close()
+}
+
+// subscribe adds sub to the subscriptions of uri and returns a function
+// that removes it again
+func (r *notificationRouter) subscribe(uri string, sub *resourceSubscription) func() {
+	r.mu.Lock()
+	r.subscriptions[uri] = append(r.subscriptions[uri], sub)
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		subs := r.subscriptions[uri]
+		for i, s := range subs {
+			if s == sub {
+				r.subscriptions[uri] = append(subs[:i], subs[i+1:]...)
+				if sub.ch != nil {
+					close(sub.ch)
+				}
+				break
+			}
+		}
+		if len(r.subscriptions[uri]) == 0 {
+			delete(r.subscriptions, uri)
+		}
+	}
+}
+
+// unsubscribeAll drops every handler for uri and closes its channels
+func (r *notificationRouter) unsubscribeAll(uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subscriptions[uri] {
+		if sub.ch != nil {
+			close(sub.ch)
+		}
+	}
+	delete(r.subscriptions, uri)
+}
+
+// trackProgress registers fn under a new progress token until release is
+// called. release waits for the callbacks already received to run.
+func (r *notificationRouter) trackProgress(fn ReadProgressFunc) (int64, func()) {
+	token := r.nextToken.Add(1)
+	key := mcp.IntProgressToken(token)
+	tracker := &progressTracker{fn: fn, queue: newCallbackQueue()}
+	go tracker.queue.run()
+
+	r.mu.Lock()
+	r.progress[key] = tracker
+	r.mu.Unlock()
+
+	return token, func() {
+		r.mu.Lock()
+		delete(r.progress, key)
+		r.mu.Unlock()
+		tracker.queue.close()
+		<-tracker.queue.done
+	}
+}
+
+// handle routes a notification to the handler registered for it. Unknown
+// notifications are ignored. Progress and resource update handlers are
+// queued rather than run here, so they may call the client.
+func (r *notificationRouter) handle(method string, params json.RawMessage) {
+	switch method {
+	case mcp.MethodNotificationMessage:
+		if r.options.logHandler == nil {
+			return
+		}
+		var entry mcp.LoggingMessageNotificationParams
+		if err := json.Unmarshal(params, &entry); err != nil {
+			r.options.logger.Warn("failed to decode notification", "method", method, "error", err)
+			return
+		}
+		r.options.logHandler(entry)
+
+	case mcp.MethodNotificationProgress:
+		var p mcp.ProgressParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			r.options.logger.Warn("failed to decode notification", "method", method, "error", err)
+			return
+		}
+
+		r.mu.Lock()
+		tracker, ok := r.progress[p.ProgressToken]
+		r.mu.Unlock()
+		if ok {
+			var total int64
+			if p.Total != nil {
+				total = int64(*p.Total)
+			}
+			tracker.queue.push(func() { tracker.fn(int64(p.Progress), total) })
+		}
+
+	case mcp.MethodNotificationResourceUpdated:
+		var p mcp.ResourceUpdatedNotificationParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			r.options.logger.Warn("failed to decode notification", "method", method, "error", err)
+			return
+		}
+
+		// Channels are fed under the lock so they cannot be closed midway,
+		// handlers run outside it so they may unsubscribe
+		var handlers []ResourceUpdatedFunc
+		r.mu.Lock()
+		for _, sub := range r.subscriptions[p.Uri] {
+			if sub.fn != nil {
+				handlers = append(handlers, sub.fn)
+				continue
+			}
+			// An update already waiting says the same thing, drop this one
+			select {
+			case sub.ch <- p.Uri:
+			default:
+			}
+		}
+		r.mu.Unlock()
+
+		if len(handlers) > 0 {
+			r.updates.push(func() {
+				for _, fn := range handlers {
+					fn(p.Uri)
+				}
+			})
+		}
+	}
+}
+
+// serverResponse answers a request the server sent to the client
+type serverResponse struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id"`
+	Result  any                    `json:"result,omitempty"`
+	Error   *mcp.JSONRPCErrorError `json:"error,omitempty"`
+}
+
+// answerServerRequest builds the response to a request the server sent.
+// Pings are answered, anything else is reported as an unknown method.
+func answerServerRequest(id json.RawMessage, method string) serverResponse {
+	response := serverResponse{JSONRPC: "2.0", ID: id}
+	switch method {
+	case mcp.MethodPing:
+		response.Result = struct{}{}
+	default:
+		response.Error = &mcp.JSONRPCErrorError{
+			Code:    mcp.MethodNotFound,
+			Message: fmt.Sprintf("method not found: %s", method),
+		}
+	}
+	return response
+}
+
+// isRequestID reports whether the raw id of a message is set, which tells
+// requests and responses apart from notifications
+func isRequestID(id json.RawMessage) bool {
+	return len(id) > 0 && string(id) != "null"
+}
@@ -0,0 +1,356 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/huangyul/go-mcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer lets the test read what the reader goroutine logs
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStdioMCPClientSlogLogger(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_server_log")
+	require.NoError(t, compileTestServer(mockServerPath))
+	defer os.Remove(mockServerPath)
+
+	var out syncBuffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+	client, err := NewStdioMCPClientWithOptions(
+		mockServerPath,
+		nil,
+		WithSlogLogger(logger),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"1.0",
+	)
+	require.NoError(t, err)
+	require.NoError(t, client.SetLevel(ctx, mcp.LoggingLevelDebug))
+
+	require.Eventually(t, func() bool {
+		return out.String() != ""
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "level changed", entry["msg"])
+	assert.Equal(t, "mock", entry["logger"])
+}
+
+func TestSSEMCPClientLogHandler(t *testing.T) {
+	var entries []mcp.LoggingMessageNotificationParams
+	client, err := NewSSEMCPClient(
+		"http://localhost:8080/sse",
+		WithLogHandler(func(params mcp.LoggingMessageNotificationParams) {
+			entries = append(entries, params)
+		}),
+	)
+	require.NoError(t, err)
+
+	client.HandleSSEEvent(
+		"message",
+		`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"error","data":{"code":7}}}`,
+	)
+	client.HandleSSEEvent(
+		"message",
+		`{"jsonrpc":"2.0","method":"notifications/unknown","params":{}}`,
+	)
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, mcp.LoggingLevelError, entries[0].Level)
+	assert.Equal(t, map[string]any{"code": float64(7)}, entries[0].Data)
+}
+
+func TestSlogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, slogLevel(mcp.LoggingLevelDebug))
+	assert.Equal(t, slog.LevelWarn, slogLevel(mcp.LoggingLevelWarning))
+	assert.Greater(t, slogLevel(mcp.LoggingLevelNotice), slog.LevelInfo)
+	assert.Less(t, slogLevel(mcp.LoggingLevelNotice), slog.LevelWarn)
+	assert.Greater(t, slogLevel(mcp.LoggingLevelEmergency), slogLevel(mcp.LoggingLevelAlert))
+	assert.Greater(t, slogLevel(mcp.LoggingLevelAlert), slogLevel(mcp.LoggingLevelCritical))
+}
+
+func TestNotificationRouterLogsMalformed(t *testing.T) {
+	var logs syncBuffer
+	router := newNotificationRouter(newClientOptions([]ClientOption{
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithLogHandler(func(mcp.LoggingMessageNotificationParams) {}),
+	}))
+
+	router.handle(mcp.MethodNotificationMessage, json.RawMessage(`"not an object"`))
+	assert.Contains(t, logs.String(), "failed to decode notification")
+	assert.Contains(t, logs.String(), "method=notifications/message")
+}
+
+func TestSSEMCPClientReadResourceWithProgress(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	handlerDone := make(chan error, 1)
+	mcpServer.HandleReadResource(
+		func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+			for read := int64(1); read <= 3; read++ {
+				if err := server.ReportReadProgress(ctx, read*1024, 3*1024); err != nil {
+					return nil, err
+				}
+			}
+			if uri == "file:///stuck" {
+				<-ctx.Done()
+				handlerDone <- ctx.Err()
+				return nil, ctx.Err()
+			}
+			return &mcp.ReadResourceResult{
+				Contents: []interface{}{
+					mcp.TextResourceContents{Uri: uri, Text: "done"},
+				},
+			}, nil
+		},
+	)
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	t.Run("Completed", func(t *testing.T) {
+		var reads []int64
+		result, err := client.ReadResourceWithProgress(
+			ctx,
+			"file:///huge",
+			func(read, total int64) {
+				assert.Equal(t, int64(3*1024), total)
+				reads = append(reads, read)
+			},
+		)
+		require.NoError(t, err)
+		assert.Len(t, result.Contents, 1)
+		assert.Equal(t, []int64{1024, 2048, 3072}, reads)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		readCtx, cancelRead := context.WithCancel(ctx)
+		progressed := make(chan struct{}, 3)
+		go func() {
+			<-progressed
+			cancelRead()
+		}()
+
+		result, err := client.ReadResourceWithProgress(
+			readCtx,
+			"file:///stuck",
+			func(read, total int64) { progressed <- struct{}{} },
+		)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		select {
+		case err := <-handlerDone:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(2 * time.Second):
+			t.Fatal("server kept reading after cancellation")
+		}
+	})
+}
+
+func TestSSEMCPClientResourceSubscriptions(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	handled := make(chan string, 4)
+	require.NoError(t, client.SubscribeWithHandler(ctx, "file:///a", func(uri string) {
+		handled <- uri
+	}))
+	updates, err := client.SubscribeChannel(ctx, "file:///a")
+	require.NoError(t, err)
+
+	update := func(uri string) {
+		client.HandleSSEEvent(
+			"message",
+			`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"`+uri+`"}}`,
+		)
+	}
+	update("file:///a")
+	update("file:///b")
+	// Coalesced with the pending update
+	update("file:///a")
+
+	for range 2 {
+		select {
+		case uri := <-handled:
+			assert.Equal(t, "file:///a", uri)
+		case <-ctx.Done():
+			t.Fatal("handler was not called")
+		}
+	}
+	assert.Equal(t, "file:///a", <-updates)
+	select {
+	case <-updates:
+		t.Fatal("pending updates were not coalesced")
+	default:
+	}
+
+	require.NoError(t, client.Unsubscribe(ctx, "file:///a"))
+	_, open := <-updates
+	assert.False(t, open)
+
+	update("file:///a")
+	assert.Empty(t, handled)
+}
+
+func TestSSEMCPClientHandlerReadsResource(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddResource(mcp.Resource{Uri: "file:///a", Name: "a"},
+		func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []interface{}{mcp.TextResourceContents{Uri: request.Params.Uri, Text: "fresh"}},
+			}, nil
+		})
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	// Handlers do not run on the reader, so they may wait for a response
+	read := make(chan *mcp.ReadResourceResult, 1)
+	require.NoError(t, client.SubscribeWithHandler(ctx, "file:///a", func(uri string) {
+		result, err := client.ReadResource(ctx, uri)
+		if assert.NoError(t, err) {
+			read <- result
+		}
+	}))
+	require.NoError(t, mcpServer.NotifyResourceUpdated("file:///a"))
+
+	select {
+	case result := <-read:
+		assert.Equal(t, "fresh", result.Contents[0].(mcp.TextResourceContents).Text)
+	case <-ctx.Done():
+		t.Fatal("handler could not read the resource")
+	}
+}
+
+func TestSSEMCPClientAnswersServerRequests(t *testing.T) {
+	answers := make(chan string, 2)
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, _ := io.ReadAll(r.Body)
+				answers <- string(body)
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: endpoint\ndata: http://%s/message?sessionId=s1\n\n", r.Host)
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":7,\"method\":\"ping\"}\n\n")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":\"x\",\"method\":\"unknown\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		},
+	))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+
+	var got []string
+	for range 2 {
+		select {
+		case answer := <-answers:
+			got = append(got, answer)
+		case <-ctx.Done():
+			t.Fatal("server requests were not answered")
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		`{"jsonrpc":"2.0","id":7,"result":{}}`,
+		`{"jsonrpc":"2.0","id":"x","error":{"code":-32601,"message":"method not found: unknown"}}`,
+	}, got)
+}
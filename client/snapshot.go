@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ServerSnapshot is a point-in-time export of everything a server offers.
+// It is plain data and can be serialized to JSON as is.
+type ServerSnapshot struct {
+	ServerInfo        mcp.Implementation     `json:"serverInfo"`
+	ProtocolVersion   string                 `json:"protocolVersion"`
+	Capabilities      mcp.ServerCapabilities `json:"capabilities"`
+	Instructions      string                 `json:"instructions,omitempty"`
+	Tools             []mcp.ToolDefinition   `json:"tools"`
+	Prompts           []mcp.Prompt           `json:"prompts"`
+	Resources         []mcp.Resource         `json:"resources"`
+	ResourceTemplates []mcp.ResourceTemplate `json:"resourceTemplates"`
+}
+
+// takeSnapshot fetches every catalog the server advertises concurrently,
+// following pagination cursors until each list is exhausted
+func takeSnapshot(
+	ctx context.Context,
+	c MCPClient,
+	initResult *mcp.InitializeResult,
+) (*ServerSnapshot, error) {
+	if initResult == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	snapshot := &ServerSnapshot{
+		ServerInfo:        initResult.ServerInfo,
+		ProtocolVersion:   initResult.ProtocolVersion,
+		Capabilities:      initResult.Capabilities,
+		Instructions:      initResult.Instructions,
+		Tools:             []mcp.ToolDefinition{},
+		Prompts:           []mcp.Prompt{},
+		Resources:         []mcp.Resource{},
+		ResourceTemplates: []mcp.ResourceTemplate{},
+	}
+
+	var fetches []func() error
+	if initResult.Capabilities.Tools != nil {
+		fetches = append(fetches, func() error {
+			return paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+				result, err := c.ListTools(ctx, cursor)
+				if err != nil {
+					return "", fmt.Errorf("failed to list tools: %w", err)
+				}
+				snapshot.Tools = append(snapshot.Tools, result.Tools...)
+				return mcp.Cursor(result.NextCursor), nil
+			})
+		})
+	}
+	if initResult.Capabilities.Prompts != nil {
+		fetches = append(fetches, func() error {
+			return paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+				result, err := c.ListPrompts(ctx, cursor)
+				if err != nil {
+					return "", fmt.Errorf("failed to list prompts: %w", err)
+				}
+				snapshot.Prompts = append(snapshot.Prompts, result.Prompts...)
+				return mcp.Cursor(result.NextCursor), nil
+			})
+		})
+	}
+	if initResult.Capabilities.Resources != nil {
+		fetches = append(fetches, func() error {
+			return paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+				result, err := c.ListResources(ctx, cursor)
+				if err != nil {
+					return "", fmt.Errorf("failed to list resources: %w", err)
+				}
+				snapshot.Resources = append(snapshot.Resources, result.Resources...)
+				return mcp.Cursor(result.NextCursor), nil
+			})
+		}, func() error {
+			return paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+				result, err := c.ListResourceTemplates(ctx, cursor)
+				if err != nil {
+					return "", fmt.Errorf("failed to list resource templates: %w", err)
+				}
+				snapshot.ResourceTemplates = append(
+					snapshot.ResourceTemplates,
+					result.ResourceTemplates...,
+				)
+				return mcp.Cursor(result.NextCursor), nil
+			})
+		})
+	}
+
+	// Each fetch only touches its own field of the snapshot
+	errs := make([]error, len(fetches))
+	var wg sync.WaitGroup
+	for i, fetch := range fetches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fetch()
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// maxSnapshotPages bounds how many pages of one list a snapshot fetches,
+// in case a server keeps handing out new cursors
+const maxSnapshotPages = 10000
+
+// paginate calls page until it returns an empty next cursor. A server
+// that repeats a cursor or exceeds maxSnapshotPages fails it rather than
+// looping forever.
+func paginate(page func(cursor mcp.Cursor) (mcp.Cursor, error)) error {
+	var cursor mcp.Cursor
+	seen := map[mcp.Cursor]bool{}
+	for range maxSnapshotPages {
+		next, err := page(cursor)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		if seen[next] {
+			return fmt.Errorf("server repeated cursor %q", next)
+		}
+		seen[next] = true
+		cursor = next
+	}
+	return fmt.Errorf("more than %d pages", maxSnapshotPages)
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[mcp.Cursor]mcp.Cursor{"": "2", "2": "3", "3": ""}
+	var seen []mcp.Cursor
+
+	err := paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+		seen = append(seen, cursor)
+		return pages[cursor], nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []mcp.Cursor{"", "2", "3"}, seen)
+
+	err = paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+		return "", errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+
+	// A cursor handed out twice would loop forever
+	calls := 0
+	err = paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+		calls++
+		return "same", nil
+	})
+	assert.EqualError(t, err, `server repeated cursor "same"`)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = paginate(func(cursor mcp.Cursor) (mcp.Cursor, error) {
+		calls++
+		return mcp.Cursor(fmt.Sprint(calls)), nil
+	})
+	assert.EqualError(t, err, fmt.Sprintf("more than %d pages", maxSnapshotPages))
+	assert.Equal(t, maxSnapshotPages, calls)
+}
@@ -17,88 +17,297 @@ import (
 	"github.com/huangyul/go-mcp/mcp"
 )
 
+// ErrSessionNotResumed is returned by Resume when the server starts a new
+// session instead of continuing the requested one
+var ErrSessionNotResumed = errors.New("session not resumed")
+
+// sessionIDHeader carries the session ID on streamable HTTP transports
+const sessionIDHeader = "Mcp-Session-Id"
+
 type SSEMCPClient struct {
-	baseURL     *url.URL
-	endpoint    *url.URL
-	httpClient  *http.Client
-	requestID   atomic.Int64
-	responses   map[int64]chan *json.RawMessage
-	mu          sync.RWMutex
-	done        chan struct{}
-	initialized bool
+	baseURL       *url.URL
+	endpoint      *url.URL
+	endpointReady chan struct{}
+	sessionID     string
+	// lastEventID is the ID of the last event received, presented as
+	// Last-Event-ID when reconnecting so the server can replay the rest
+	lastEventID   string
+	httpClient    *http.Client
+	requestID     atomic.Int64
+	responses     map[int64]chan *response
+	mu            sync.RWMutex
+	done          chan struct{}
+	initialized   atomic.Bool
+	initResult    atomic.Pointer[mcp.InitializeResult]
+	options       clientOptions
+	notifications *notificationRouter
+	background    *background
+	outstanding   *outstandingRequests
+	// cancelStream stops the current SSE stream
+	cancelStream context.CancelFunc
 }
 
-func NewSSEMCPClient(baseURL string) (*SSEMCPClient, error) {
+func NewSSEMCPClient(baseURL string, opts ...ClientOption) (*SSEMCPClient, error) {
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %s", baseURL)
 	}
 
-	return &SSEMCPClient{
-		baseURL:    parsedURL,
-		httpClient: &http.Client{},
-		responses:  make(map[int64]chan *json.RawMessage),
-		done:       make(chan struct{}),
-	}, nil
+	options := newClientOptions(opts)
+	client := &SSEMCPClient{
+		baseURL:       parsedURL,
+		endpointReady: make(chan struct{}),
+		httpClient:    &http.Client{},
+		responses:     make(map[int64]chan *response),
+		done:          make(chan struct{}),
+		options:       options,
+		notifications: newNotificationRouter(options),
+		background:    newBackground(),
+		outstanding:   newOutstandingRequests(),
+	}
+	client.background.Go(client.notifications.updates.run)
+	return client, nil
 }
 
+// Start opens the SSE stream and waits for the server to send the endpoint
+// requests are posted to
 func (c *SSEMCPClient) Start(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String(), nil)
+	ready, ended, err := c.connect(ctx, "")
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
+	}
+	return c.awaitEndpoint(ctx, ready, ended)
+}
+
+// Resume reconnects presenting a previously assigned session ID instead of
+// initializing again. It returns ErrSessionNotResumed if the server starts
+// a new session.
+func (c *SSEMCPClient) Resume(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return fmt.Errorf("session ID is required")
+	}
+
+	ready, ended, err := c.connect(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if err := c.awaitEndpoint(ctx, ready, ended); err != nil {
+		return err
+	}
+
+	if got := c.SessionID(); got != sessionID {
+		return fmt.Errorf("%w: server assigned %s", ErrSessionNotResumed, got)
+	}
+
+	c.initialized.Store(true)
+	return nil
+}
+
+// connect opens the SSE stream and returns a channel that is closed once
+// the endpoint event arrives and one that is closed once the stream ends
+func (c *SSEMCPClient) connect(
+	ctx context.Context,
+	sessionID string,
+) (ready, ended <-chan struct{}, err error) {
+	streamURL := *c.baseURL
+	if sessionID != "" {
+		query := streamURL.Query()
+		query.Set("sessionId", sessionID)
+		streamURL.RawQuery = query.Encode()
+	}
+
+	// The stream outlives this call, Close cancels it through streamCtx
+	streamCtx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, streamURL.String(), nil)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Cache-Control", "no-cache")
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+		c.mu.RLock()
+		if c.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", c.lastEventID)
+		}
+		c.mu.RUnlock()
+	}
+
+	endpointReady := make(chan struct{})
+	c.mu.Lock()
+	if c.cancelStream != nil {
+		c.cancelStream()
+	}
+	c.cancelStream = cancel
+	c.endpoint = nil
+	c.endpointReady = endpointReady
+	c.sessionID = ""
+	c.mu.Unlock()
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to sse stream: %w", err)
+		return nil, nil, fmt.Errorf("failed to connect to sse stream: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	go c.readSSE(resp.Body)
-	return nil
+	if id := resp.Header.Get(sessionIDHeader); id != "" {
+		c.setSessionID(id)
+	}
+
+	select {
+	case <-c.done:
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("client closed")
+	default:
+	}
+	streamEnded := make(chan struct{})
+	c.background.Go(func() {
+		err := c.readSSE(resp.Body)
+		close(streamEnded)
+		c.reconnect(ctx, err)
+	})
+	return endpointReady, streamEnded, nil
+}
+
+// awaitEndpoint waits for the endpoint event of a stream opened by connect
+func (c *SSEMCPClient) awaitEndpoint(ctx context.Context, ready, ended <-chan struct{}) error {
+	select {
+	case <-ready:
+		return nil
+	case <-ended:
+		// The endpoint may have come just before the stream ended
+		select {
+		case <-ready:
+			return nil
+		default:
+			return fmt.Errorf("sse stream closed before the endpoint event")
+		}
+	case <-c.done:
+		return fmt.Errorf("client closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reconnect reopens a dropped stream when a backoff policy is set,
+// presenting the current session ID so the server can resume the session
+func (c *SSEMCPClient) reconnect(parent context.Context, cause error) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	if c.options.backoff == nil || parent.Err() != nil {
+		return
+	}
+
+	ctx, cancel := contextUntil(parent, c.done)
+	defer cancel()
+	sessionID := c.SessionID()
+	var ready, ended <-chan struct{}
+	err := c.options.backoff.retry(ctx, cause, func() error {
+		var err error
+		ready, ended, err = c.connect(parent, sessionID)
+		return err
+	})
+	if err != nil {
+		c.failPending()
+		if ctx.Err() == nil {
+			c.options.logger.Error("failed to reconnect SSE stream", "error", err)
+		}
+		return
+	}
+
+	// Responses to pending requests can still arrive when the server gives
+	// the session back and replays the events missed
+	if err := c.awaitEndpoint(ctx, ready, ended); err != nil {
+		c.failPending()
+		return
+	}
+	if sessionID == "" || c.SessionID() != sessionID {
+		c.failPending()
+	}
+}
+
+// failPending fails every request waiting for a response on a stream that
+// has gone away
+func (c *SSEMCPClient) failPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range c.responses {
+		deliverResponse(ch, &response{err: errRequestFailed})
+		delete(c.responses, id)
+	}
+}
+
+// SessionID returns the session ID assigned by the server, or an empty
+// string if none has been received yet
+func (c *SSEMCPClient) SessionID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sessionID
+}
+
+func (c *SSEMCPClient) setSessionID(sessionID string) {
+	c.mu.Lock()
+	changed := c.sessionID != sessionID
+	c.sessionID = sessionID
+	c.mu.Unlock()
+
+	if changed && c.options.onSessionID != nil {
+		c.options.onSessionID(sessionID)
+	}
 }
 
-func (c *SSEMCPClient) readSSE(r io.ReadCloser) {
+// readSSE handles events until the stream ends and returns why it ended
+func (c *SSEMCPClient) readSSE(r io.ReadCloser) error {
 	defer r.Close()
 
 	reader := bufio.NewReader(r)
-	var event, data string
+	var id, event, data string
 
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				break
+				return err
 			}
 
 			select {
 			case <-c.done:
-				return
 			default:
-				fmt.Printf("SSE stream error: %v\n", err)
+				c.options.logger.Warn("SSE stream failed", "error", err)
 			}
+			return err
 		}
 
 		line = strings.TrimRight(line, "\r\n")
 		if line == "" {
 			// represent a event
 			if data != "" && event != "" {
+				if id != "" {
+					c.mu.Lock()
+					c.lastEventID = id
+					c.mu.Unlock()
+				}
 				c.HandleSSEEvent(event, data)
+				id = ""
 				event = ""
 				data = ""
 			}
 			continue
 		}
 
-		if after, ok := strings.CutPrefix(line, "event:"); ok {
+		if after, ok := strings.CutPrefix(line, "id:"); ok {
+			id = strings.TrimSpace(after)
+		} else if after, ok := strings.CutPrefix(line, "event:"); ok {
 			event = strings.TrimSpace(after)
 		} else if after, ok := strings.CutPrefix(line, "data:"); ok {
 			data = strings.TrimSpace(after)
@@ -111,43 +320,67 @@ func (c *SSEMCPClient) HandleSSEEvent(event, data string) {
 	case "endpoint":
 		endpoint, err := url.Parse(data)
 		if err != nil {
-			fmt.Printf("Error parsing endpoint URL: %v\n", err)
+			c.options.logger.Error("failed to parse endpoint URL", "error", err)
 			return
 		}
 		if endpoint.Host != c.baseURL.Host {
-			fmt.Printf("Endpoint origin not match connection origin\n")
+			c.options.logger.Error("endpoint origin does not match connection origin", "endpoint", data)
 			return
 		}
+		if sessionID := endpoint.Query().Get("sessionId"); sessionID != "" {
+			c.setSessionID(sessionID)
+		}
+
+		c.mu.Lock()
 		c.endpoint = endpoint
+		select {
+		case <-c.endpointReady:
+		default:
+			close(c.endpointReady)
+		}
+		c.mu.Unlock()
 	case "message":
 		var response struct {
-			ID     int64           `json:"id"`
-			Result json.RawMessage `json:"result,omitempty"`
-			Error  *struct {
-				Code    int    `json:"code"`
-				Message string `json:"message"`
-			} `json:"error,omitempty"`
+			ID     json.RawMessage        `json:"id"`
+			Method string                 `json:"method"`
+			Params json.RawMessage        `json:"params"`
+			Result json.RawMessage        `json:"result,omitempty"`
+			Error  *mcp.JSONRPCErrorError `json:"error,omitempty"`
 		}
 
 		err := json.Unmarshal([]byte(data), &response)
 		if err != nil {
-			fmt.Printf("Error unmarshaling response: %v\n", err)
+			c.options.logger.Warn("failed to decode message", "error", err)
 			return
 		}
 
-		c.mu.RLock()
-		ch, ok := c.responses[response.ID]
-		c.mu.RUnlock()
+		if !isRequestID(response.ID) {
+			if response.Method != "" {
+				c.notifications.handle(response.Method, response.Params)
+			}
+			return
+		}
+		if response.Method != "" {
+			// Answer off the reader, posting may take a while
+			c.background.Go(func() {
+				c.answer(answerServerRequest(response.ID, response.Method))
+			})
+			return
+		}
+
+		var id int64
+		if err := json.Unmarshal(response.ID, &id); err != nil {
+			return
+		}
+		// Claim the waiter under the lock, so a duplicate response finds
+		// nobody and a caller that stopped waiting can never block us
+		c.mu.Lock()
+		ch, ok := c.responses[id]
+		delete(c.responses, id)
+		c.mu.Unlock()
 
 		if ok {
-			if response.Error != nil {
-				ch <- nil
-			} else {
-				ch <- &response.Result
-			}
-			c.mu.Lock()
-			delete(c.responses, response.ID)
-			c.mu.Unlock()
+			deliverResponse(ch, newResponse(response.Result, response.Error))
 		}
 	}
 }
@@ -156,16 +389,28 @@ func (c *SSEMCPClient) sendRequest(
 	ctx context.Context,
 	method string,
 	params any,
-) (*json.RawMessage, error) {
-	if !c.initialized && method != "initialize" {
+) (result *json.RawMessage, err error) {
+	if !c.initialized.Load() && method != mcp.MethodInitialize {
 		return nil, fmt.Errorf("client not initialized")
 	}
 
-	if c.endpoint == nil {
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
 		return nil, fmt.Errorf("endpoint not received")
 	}
 
-	id := c.requestID.Add(1)
+	ctx, id, untrack := c.outstanding.start(ctx, &c.requestID)
+	defer untrack()
+	// A call cancelled before it was sent is not sent at all
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	params, err = requestParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	ctx, params, endSpan := c.options.startSpan(ctx, method, id, params)
+	defer func() { endSpan(err) }()
 
 	request := struct {
 		JSONRPC string `json:"jsonrpc"`
@@ -184,24 +429,39 @@ func (c *SSEMCPClient) sendRequest(
 		return nil, fmt.Errorf("failed to parse request: %w", err)
 	}
 
-	responseCh := make(chan *json.RawMessage)
+	// Buffered so a response to an abandoned request never blocks the reader
+	responseCh := make(chan *response, 1)
 	c.mu.Lock()
 	c.responses[id] = responseCh
 	c.mu.Unlock()
+	// Requests that fail are never answered, stop waiting for them
+	defer func() {
+		if err != nil {
+			c.mu.Lock()
+			delete(c.responses, id)
+			c.mu.Unlock()
+		}
+	}()
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
-		c.endpoint.String(),
+		endpoint.String(),
 		bytes.NewBuffer(requestBytes),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if sessionID := c.SessionID(); sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			err = abandonRequest(ctx, c, id)
+		}
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
@@ -209,18 +469,109 @@ func (c *SSEMCPClient) sendRequest(
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, body)
 	}
+	// Servers may answer in the body rather than on the stream
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		c.HandleSSEEvent("message", string(body))
+	}
 
 	select {
 	case <-ctx.Done():
-		c.mu.Lock()
-		delete(c.responses, id)
-		c.mu.Unlock()
-		return nil, ctx.Err()
-	case response := <-responseCh:
-		if response == nil {
-			return nil, fmt.Errorf("request failed")
+		return nil, abandonRequest(ctx, c, id)
+	case resp := <-responseCh:
+		if resp == nil {
+			return nil, errRequestFailed
+		}
+		if resp.err != nil {
+			return nil, resp.err
 		}
-		return response, nil
+		return &resp.result, nil
+	}
+}
+
+func (c *SSEMCPClient) SendNotification(
+	ctx context.Context,
+	method string,
+	params any,
+) error {
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
+		return fmt.Errorf("endpoint not received")
+	}
+
+	notification := struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+	}{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	return c.postMessage(ctx, endpoint, notificationBytes, "notification")
+}
+
+// postMessage posts a message that gets no JSON-RPC response to the
+// message endpoint. kind names the message in errors.
+func (c *SSEMCPClient) postMessage(
+	ctx context.Context,
+	endpoint *url.URL,
+	data []byte,
+	kind string,
+) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		endpoint.String(),
+		bytes.NewBuffer(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID := c.SessionID(); sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", kind, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s failed with status %d: %s", kind, resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// answer posts the response to a server request
+func (c *SSEMCPClient) answer(response serverResponse) {
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := contextUntil(context.Background(), c.done)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, cancelNotificationTimeout)
+	defer cancelTimeout()
+	if err := c.postMessage(ctx, endpoint, data, "response"); err != nil && ctx.Err() == nil {
+		c.options.logger.Warn("failed to answer server request", "error", err)
 	}
 }
 
@@ -230,17 +581,13 @@ func (c *SSEMCPClient) Initialize(
 	clientInfo mcp.Implementation,
 	protocolVersion string,
 ) (*mcp.InitializeResult, error) {
-	params := struct {
-		Capabilities    mcp.ClientCapabilities `json:"capabilities"`
-		ClientInfo      mcp.Implementation     `json:"clientInfo"`
-		ProtocolVersion string                 `json:"protocolVersion"`
-	}{
+	params := mcp.InitializeRequestParams{
 		Capabilities:    capabilities,
 		ClientInfo:      clientInfo,
 		ProtocolVersion: protocolVersion,
 	}
 
-	response, err := c.sendRequest(ctx, "initialize", params)
+	response, err := c.sendRequest(ctx, mcp.MethodInitialize, params)
 	if err != nil {
 		return nil, err
 	}
@@ -250,26 +597,30 @@ func (c *SSEMCPClient) Initialize(
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	c.initialized = true
+	c.initResult.Store(&result)
+	c.initialized.Store(true)
+
+	if !c.options.skipInitializedNotification {
+		if err := c.SendNotification(ctx, mcp.MethodNotificationInitialized, nil); err != nil {
+			return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+		}
+	}
+
 	return &result, nil
 }
 
 func (c *SSEMCPClient) Ping(ctx context.Context) error {
-	_, err := c.sendRequest(ctx, "ping", nil)
+	_, err := c.sendRequest(ctx, mcp.MethodPing, nil)
 	return err
 }
 
 func (c *SSEMCPClient) ListResources(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListResourcesResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	params := mcp.ListResourcesRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "resources/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesList, params)
 	if err != nil {
 		return nil, err
 	}
@@ -282,23 +633,66 @@ func (c *SSEMCPClient) ListResources(
 	return &result, nil
 }
 
+func (c *SSEMCPClient) ListResourceTemplates(
+	ctx context.Context,
+	cursor mcp.Cursor,
+) (*mcp.ListResourceTemplatesResult, error) {
+	params := mcp.ListResourceTemplatesRequestParams{Cursor: string(cursor)}
+
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesTemplatesList, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ListResourceTemplatesResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
 func (c *SSEMCPClient) ReadResource(
 	ctx context.Context,
 	uri string,
 ) (*mcp.ReadResourceResult, error) {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
+	params := mcp.ReadResourceRequestParams{Uri: uri}
+
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesRead, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ReadResourceResult
+	if err := mcp.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	response, err := c.sendRequest(ctx, "resources/read", params)
+	return &result, nil
+}
+
+// ReadResourceWithProgress reads a resource, calling onProgress as the
+// server reports bytes read. Cancelling ctx stops the read on the server
+// and discards whatever was read so far.
+func (c *SSEMCPClient) ReadResourceWithProgress(
+	ctx context.Context,
+	uri string,
+	onProgress ReadProgressFunc,
+) (*mcp.ReadResourceResult, error) {
+	token, release := c.notifications.trackProgress(onProgress)
+	defer release()
+
+	meta := mcp.Meta{}
+	meta.SetProgressToken(mcp.IntProgressToken(token))
+	params := mcp.ReadResourceRequestParams{Uri: uri}
+
+	response, err := c.sendRequest(WithRequestMeta(ctx, meta), mcp.MethodResourcesRead, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.ReadResourceResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -306,38 +700,61 @@ func (c *SSEMCPClient) ReadResource(
 }
 
 func (c *SSEMCPClient) Subscribe(ctx context.Context, uri string) error {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
-	}
+	params := mcp.SubscribeRequestParams{Uri: uri}
 
-	_, err := c.sendRequest(ctx, "resources/subscribe", params)
+	_, err := c.sendRequest(ctx, mcp.MethodResourcesSubscribe, params)
 	return err
 }
 
 func (c *SSEMCPClient) Unsubscribe(ctx context.Context, uri string) error {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
-	}
+	params := mcp.UnsubscribeRequestParams{Uri: uri}
 
-	_, err := c.sendRequest(ctx, "resources/unsubscribe", params)
+	_, err := c.sendRequest(ctx, mcp.MethodResourcesUnsubscribe, params)
+	if err == nil {
+		c.notifications.unsubscribeAll(uri)
+	}
 	return err
 }
 
+// SubscribeWithHandler subscribes to uri and calls handler every time the
+// server reports it updated, until Unsubscribe
+func (c *SSEMCPClient) SubscribeWithHandler(
+	ctx context.Context,
+	uri string,
+	handler ResourceUpdatedFunc,
+) error {
+	remove := c.notifications.subscribe(uri, &resourceSubscription{fn: handler})
+	if err := c.Subscribe(ctx, uri); err != nil {
+		remove()
+		return err
+	}
+	return nil
+}
+
+// SubscribeChannel subscribes to uri and delivers its URI on the returned
+// channel every time the server reports it updated. Updates that arrive
+// while one is still pending are coalesced. The channel is closed by
+// Unsubscribe.
+func (c *SSEMCPClient) SubscribeChannel(
+	ctx context.Context,
+	uri string,
+) (<-chan string, error) {
+	ch := make(chan string, 1)
+	remove := c.notifications.subscribe(uri, &resourceSubscription{ch: ch})
+	if err := c.Subscribe(ctx, uri); err != nil {
+		remove()
+		return nil, err
+	}
+	return ch, nil
+}
+
 func (c *SSEMCPClient) ListPrompts(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListPromptsResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	params := mcp.ListPromptsRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "prompts/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodPromptsList, params)
 	if err != nil {
 		return nil, err
 	}
@@ -355,21 +772,18 @@ func (c *SSEMCPClient) GetPrompt(
 	name string,
 	arguments map[string]string,
 ) (*mcp.GetPromptResult, error) {
-	params := struct {
-		Name      string            `json:"name"`
-		Arguments map[string]string `json:"arguments,omitempty"`
-	}{
+	params := mcp.GetPromptRequestParams{
 		Name:      name,
 		Arguments: arguments,
 	}
 
-	response, err := c.sendRequest(ctx, "prompts/get", params)
+	response, err := c.sendRequest(ctx, mcp.MethodPromptsGet, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.GetPromptResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -378,20 +792,16 @@ func (c *SSEMCPClient) GetPrompt(
 
 func (c *SSEMCPClient) ListTools(
 	ctx context.Context,
-	cursor *string,
-) (*mcp.ListToolsResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	cursor mcp.Cursor,
+) (*mcp.ListToolDefinitionsResult, error) {
+	params := mcp.ListToolsRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "tools/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodToolsList, params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result mcp.ListToolsResult
+	var result mcp.ListToolDefinitionsResult
 	if err := json.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
@@ -404,38 +814,81 @@ func (c *SSEMCPClient) CallTool(
 	name string,
 	arguments map[string]interface{},
 ) (*mcp.CallToolResult, error) {
+	params := mcp.CallToolRequestParams{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	response, err := c.sendRequest(ctx, mcp.MethodToolsCall, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CallToolResult
+	if err := mcp.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// CallToolRaw invokes a tool with arguments that are already JSON encoded,
+// passing them through untouched so large numbers keep their precision
+func (c *SSEMCPClient) CallToolRaw(
+	ctx context.Context,
+	name string,
+	arguments json.RawMessage,
+) (*mcp.CallToolResult, error) {
+	if len(arguments) > 0 && !json.Valid(arguments) {
+		return nil, fmt.Errorf("invalid tool arguments: not valid JSON")
+	}
+
 	params := struct {
-		Name      string                 `json:"name"`
-		Arguments map[string]interface{} `json:"arguments,omitempty"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments,omitempty"`
 	}{
 		Name:      name,
 		Arguments: arguments,
 	}
 
-	response, err := c.sendRequest(ctx, "tools/call", params)
+	response, err := c.sendRequest(ctx, mcp.MethodToolsCall, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.CallToolResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	return &result, nil
 }
 
+// CallToolAsync starts a tool call in the background. The returned call
+// carries the request ID, so the call can be stopped with CancelRequest.
+func (c *SSEMCPClient) CallToolAsync(
+	ctx context.Context,
+	name string,
+	arguments map[string]interface{},
+) *PendingCall {
+	return callToolAsync(ctx, &c.requestID, c.outstanding, func(ctx context.Context) (*mcp.CallToolResult, error) {
+		return c.CallTool(ctx, name, arguments)
+	})
+}
+
+// CancelRequest stops waiting for the outstanding request id and sends
+// notifications/cancelled with reason to the server
+func (c *SSEMCPClient) CancelRequest(ctx context.Context, id int64, reason string) error {
+	return cancelRequest(ctx, c, c.outstanding, id, reason)
+}
+
 func (c *SSEMCPClient) SetLevel(
 	ctx context.Context,
 	level mcp.LoggingLevel,
 ) error {
-	params := struct {
-		Level mcp.LoggingLevel `json:"level"`
-	}{
-		Level: level,
-	}
+	params := mcp.SetLevelRequestParams{Level: level}
 
-	_, err := c.sendRequest(ctx, "logging/setLevel", params)
+	_, err := c.sendRequest(ctx, mcp.MethodLoggingSetLevel, params)
 	return err
 }
 
@@ -444,15 +897,12 @@ func (c *SSEMCPClient) Complete(
 	ref interface{},
 	argument mcp.CompleteRequestParamsArgument,
 ) (*mcp.CompleteResult, error) {
-	params := struct {
-		Ref      interface{}                       `json:"ref"`
-		Argument mcp.CompleteRequestParamsArgument `json:"argument"`
-	}{
+	params := mcp.CompleteRequestParams{
 		Ref:      ref,
 		Argument: argument,
 	}
 
-	response, err := c.sendRequest(ctx, "completion/complete", params)
+	response, err := c.sendRequest(ctx, mcp.MethodCompletionComplete, params)
 	if err != nil {
 		return nil, err
 	}
@@ -465,7 +915,33 @@ func (c *SSEMCPClient) Complete(
 	return &result, nil
 }
 
+// CompletePrompt requests completions for an argument of the named prompt
+func (c *SSEMCPClient) CompletePrompt(
+	ctx context.Context,
+	promptName string,
+	argument mcp.CompleteRequestParamsArgument,
+) (*mcp.CompleteResult, error) {
+	return c.Complete(ctx, mcp.NewPromptReference(promptName), argument)
+}
+
+// CompleteResourceTemplate requests completions for a variable of a
+// resource URI template
+func (c *SSEMCPClient) CompleteResourceTemplate(
+	ctx context.Context,
+	uriTemplate string,
+	argument mcp.CompleteRequestParamsArgument,
+) (*mcp.CompleteResult, error) {
+	return c.Complete(ctx, mcp.NewResourceReference(uriTemplate), argument)
+}
+
+// Snapshot exports the server's info, capabilities and full catalogs
+func (c *SSEMCPClient) Snapshot(ctx context.Context) (*ServerSnapshot, error) {
+	return takeSnapshot(ctx, c, c.initResult.Load())
+}
+
 func (c *SSEMCPClient) GetEndpoint() *url.URL {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.endpoint
 }
 
@@ -476,14 +952,31 @@ func (c *SSEMCPClient) Close() error {
 	default:
 		close(c.done)
 	}
+	defer c.background.stop()
+	c.notifications.close()
 
 	// Clean up any pending responses
 	c.mu.Lock()
+	if c.cancelStream != nil {
+		c.cancelStream()
+	}
 	for _, ch := range c.responses {
 		close(ch)
 	}
-	c.responses = make(map[int64]chan *json.RawMessage)
+	c.responses = make(map[int64]chan *response)
 	c.mu.Unlock()
 
 	return nil
 }
+
+// Done returns a channel that is closed once the client has been closed and
+// its SSE reader has stopped
+func (c *SSEMCPClient) Done() <-chan struct{} {
+	return c.background.stopped
+}
+
+// Wait blocks until the client has been closed and its SSE reader has
+// stopped
+func (c *SSEMCPClient) Wait() {
+	<-c.background.stopped
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -70,7 +72,7 @@ func TestSSEMCPClient(t *testing.T) {
 	})
 
 	t.Run("ListResources", func(t *testing.T) {
-		result, err := client.ListResources(ctx, nil)
+		result, err := client.ListResources(ctx, "")
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Empty(t, result.Resources)
@@ -92,7 +94,7 @@ func TestSSEMCPClient(t *testing.T) {
 	})
 
 	t.Run("ListPrompts", func(t *testing.T) {
-		result, err := client.ListPrompts(ctx, nil)
+		result, err := client.ListPrompts(ctx, "")
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Empty(t, result.Prompts)
@@ -106,7 +108,7 @@ func TestSSEMCPClient(t *testing.T) {
 	})
 
 	t.Run("ListTools", func(t *testing.T) {
-		result, err := client.ListTools(ctx, nil)
+		result, err := client.ListTools(ctx, "")
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Empty(t, result.Tools)
@@ -132,6 +134,14 @@ func TestSSEMCPClient(t *testing.T) {
 		assert.NotNil(t, result)
 		assert.Empty(t, result.Completion.Values)
 	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		snapshot, err := client.Snapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "test-server", snapshot.ServerInfo.Name)
+		assert.Empty(t, snapshot.Resources)
+		assert.Empty(t, snapshot.ResourceTemplates)
+	})
 }
 
 func TestSSEMCPClientErrors(t *testing.T) {
@@ -147,7 +157,7 @@ func TestSSEMCPClientErrors(t *testing.T) {
 	client, err := NewSSEMCPClient("http://localhost:8080/sse")
 	require.NoError(t, err)
 
-	_, err = client.ListResources(ctx, nil)
+	_, err = client.ListResources(ctx, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "client not initialized")
 
@@ -185,7 +195,215 @@ func TestSSEMCPClientErrors(t *testing.T) {
 		mcp.Implementation{},
 		"",
 	)
-	assert.Error(t, err)
+	var rpcErr *mcp.JSONRPCErrorError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.InvalidParams, rpcErr.Code)
+}
+
+func TestSSEMCPClientStart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	// Start returns once requests can be posted
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	assert.NotNil(t, client.GetEndpoint())
+
+	// A stream that ends without an endpoint fails Start rather than hang
+	noEndpoint := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+	defer noEndpoint.Close()
+
+	client, err = NewSSEMCPClient(noEndpoint.URL + "/sse")
+	require.NoError(t, err)
+	defer client.Close()
+	err = client.Start(ctx)
+	assert.ErrorContains(t, err, "before the endpoint event")
+	assert.NoError(t, ctx.Err())
+}
+
+func TestSSEMCPClientRejectedRequest(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: endpoint\ndata: http://%s/message?sessionId=s1\n\n", r.Host)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		},
+	))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	assert.ErrorContains(t, err, "status 503")
+
+	// Rejected requests are not left waiting for a response
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	assert.Empty(t, client.responses)
+}
+
+func TestSSEMCPClientInitializedNotification(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []ClientOption
+		expected bool
+	}{
+		{name: "Default", expected: true},
+		{name: "OptOut", opts: []ClientOption{WithoutInitializedNotification()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			received := make(chan struct{}, 1)
+			mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+			mcpServer.HandleNotification(
+				"initialized",
+				func(ctx context.Context, args any) (any, error) {
+					received <- struct{}{}
+					return nil, nil
+				},
+			)
+			_, testServer := server.NewTestServer(mcpServer)
+			defer testServer.Close()
+
+			// Cancelled before the test server closes so the SSE stream ends
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			client, err := NewSSEMCPClient(testServer.URL+"/sse", tc.opts...)
+			require.NoError(t, err)
+			require.NoError(t, client.Start(ctx))
+			defer client.Close()
+			require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+			_, err = client.Initialize(
+				ctx,
+				mcp.ClientCapabilities{},
+				mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+				"2024-11-05",
+			)
+			require.NoError(t, err)
+
+			select {
+			case <-received:
+				assert.True(t, tc.expected, "unexpected initialized notification")
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tc.expected, "initialized notification not received")
+			}
+		})
+	}
+}
+
+func TestSSEMCPClientResume(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saved := make(chan string, 1)
+	client, err := NewSSEMCPClient(
+		testServer.URL+"/sse",
+		WithSessionIDHandler(func(sessionID string) { saved <- sessionID }),
+	)
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	sessionID := <-saved
+	assert.NotEmpty(t, sessionID)
+	assert.Equal(t, sessionID, client.SessionID())
+
+	t.Run("NotResumed", func(t *testing.T) {
+		// The default SSE server always starts a new session
+		other, err := NewSSEMCPClient(testServer.URL + "/sse")
+		require.NoError(t, err)
+		defer other.Close()
+
+		err = other.Resume(ctx, sessionID)
+		assert.ErrorIs(t, err, ErrSessionNotResumed)
+		_, err = other.ListTools(ctx, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Resumed", func(t *testing.T) {
+		resumable := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				id := r.Header.Get("Mcp-Session-Id")
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprintf(w, "event: endpoint\ndata: http://%s/message?sessionId=%s\n\n", r.Host, id)
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+		))
+		defer resumable.Close()
+
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		defer cancelStream()
+
+		resumed, err := NewSSEMCPClient(resumable.URL + "/sse")
+		require.NoError(t, err)
+		defer resumed.Close()
+
+		require.NoError(t, resumed.Resume(streamCtx, sessionID))
+		assert.Equal(t, sessionID, resumed.SessionID())
+		assert.True(t, resumed.initialized.Load())
+	})
+}
+
+func TestSSEMCPClientWait(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	// A context that outlives the client, so only Close can stop the stream
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(context.Background()))
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	select {
+	case <-client.Done():
+		t.Fatal("Done closed before Close")
+	default:
+	}
+
+	require.NoError(t, client.Close())
+	select {
+	case <-client.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("SSE reader did not stop after Close")
+	}
+	client.Wait()
+	assert.NoError(t, client.Close())
 }
 
 func waitForEndpoint(client *SSEMCPClient, timeout time.Duration) error {
@@ -198,3 +416,70 @@ func waitForEndpoint(client *SSEMCPClient, timeout time.Duration) error {
 	}
 	return fmt.Errorf("timeout waiting for endpoint")
 }
+
+func TestSSEMCPClientToolContents(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "report", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []interface{}{
+				mcp.NewTextContent("see attached"),
+				mcp.NewEmbeddedTextResource("file:///report.txt", "text/plain", "all good"),
+				mcp.NewResourceLink(mcp.Resource{Uri: "file:///log.txt", Name: "log"}),
+			}}, nil
+		})
+	_, testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+
+	result, err := client.CallTool(ctx, "report", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		mcp.NewTextContent("see attached"),
+		mcp.NewEmbeddedTextResource("file:///report.txt", "text/plain", "all good"),
+		mcp.NewResourceLink(mcp.Resource{Uri: "file:///log.txt", Name: "log"}),
+	}, result.Content)
+}
+
+func TestSSEMCPClientResponseInBody(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	// Compression is negotiated by the HTTP client on its own
+	_, testServer := server.NewTestServer(mcpServer,
+		server.WithResponseDelivery(server.ResponseInBody),
+		server.WithCompression(server.CompressionConfig{Streams: true}),
+	)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := NewSSEMCPClient(testServer.URL + "/sse")
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	result, err := client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "test-server", result.ServerInfo.Name)
+	assert.NoError(t, client.Ping(ctx))
+}
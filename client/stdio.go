@@ -15,20 +15,42 @@ import (
 )
 
 type StdioMCPClient struct {
-	cmd         *exec.Cmd
-	stdin       io.WriteCloser
-	stdout      *bufio.Reader
-	requestID   atomic.Int64
-	response    map[int64]chan *json.RawMessage
-	mu          sync.Mutex
-	done        chan struct{}
-	initialized bool
+	command   string
+	args      []string
+	process   *stdioProcess
+	requestID atomic.Int64
+	response  map[int64]chan *response
+	mu        sync.Mutex
+	// broken fails requests once reading from the server has stopped for
+	// good. It is guarded by mu.
+	broken error
+	// writeMu serializes writes and guards process
+	writeMu       sync.Mutex
+	done          chan struct{}
+	init          atomic.Pointer[stdioInit]
+	options       clientOptions
+	notifications *notificationRouter
+	background    *background
+	outstanding   *outstandingRequests
 }
 
-func NewStdioMCPClient(
-	command string,
-	args ...string,
-) (*StdioMCPClient, error) {
+// stdioInit is the outcome of Initialize, repeated against a restarted
+// server
+type stdioInit struct {
+	params *mcp.InitializeRequestParams
+	result *mcp.InitializeResult
+}
+
+// stdioProcess is one run of the server command
+type stdioProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	once   sync.Once
+	err    error
+}
+
+func startProcess(command string, args []string) (*stdioProcess, error) {
 	cmd := exec.Command(command, args...)
 
 	stdin, err := cmd.StdinPipe()
@@ -41,75 +63,263 @@ func NewStdioMCPClient(
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	client := &StdioMCPClient{
-		cmd:      cmd,
-		stdin:    stdin,
-		stdout:   bufio.NewReader(stdout),
-		response: make(map[int64]chan *json.RawMessage),
-		done:     make(chan struct{}),
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
 
-	if err := client.cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start command: %w", err)
+	return &stdioProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// wait reaps the process. Both Close and a restart may call it.
+func (p *stdioProcess) wait() error {
+	p.once.Do(func() {
+		p.err = p.cmd.Wait()
+	})
+	return p.err
+}
+
+func NewStdioMCPClient(
+	command string,
+	args ...string,
+) (*StdioMCPClient, error) {
+	return NewStdioMCPClientWithOptions(command, args)
+}
+
+// NewStdioMCPClientWithOptions starts command with args and applies opts
+func NewStdioMCPClientWithOptions(
+	command string,
+	args []string,
+	opts ...ClientOption,
+) (*StdioMCPClient, error) {
+	process, err := startProcess(command, args)
+	if err != nil {
+		return nil, err
 	}
 
-	go client.readResponses()
+	client := &StdioMCPClient{
+		command:     command,
+		args:        args,
+		process:     process,
+		response:    make(map[int64]chan *response),
+		done:        make(chan struct{}),
+		options:     newClientOptions(opts),
+		background:  newBackground(),
+		outstanding: newOutstandingRequests(),
+	}
+	client.notifications = newNotificationRouter(client.options)
+
+	client.background.Go(client.readResponses)
+	client.background.Go(client.notifications.updates.run)
 
 	return client, nil
 }
 
 func (c *StdioMCPClient) Close() error {
-	close(c.done)
+	select {
+	case <-c.done:
+		return nil // Already closed
+	default:
+		close(c.done)
+	}
+	defer c.background.stop()
+	c.notifications.close()
+
+	c.writeMu.Lock()
+	process := c.process
+	c.writeMu.Unlock()
 
-	if err := c.stdin.Close(); err != nil {
+	if err := process.stdin.Close(); err != nil {
 		return fmt.Errorf("failed to close stdin: %w", err)
 	}
-	return c.cmd.Wait()
+	return process.wait()
+}
+
+// Done returns a channel that is closed once the client has been closed and
+// its background reader has stopped
+func (c *StdioMCPClient) Done() <-chan struct{} {
+	return c.background.stopped
 }
 
+// Wait blocks until the client has been closed and its background reader
+// has stopped
+func (c *StdioMCPClient) Wait() {
+	<-c.background.stopped
+}
+
+// readResponses reads from the server until the client is closed. When the
+// server exits and a backoff policy is set, it restarts the server and
+// carries on reading from the new process.
 func (c *StdioMCPClient) readResponses() {
 	for {
+		c.writeMu.Lock()
+		process := c.process
+		c.writeMu.Unlock()
+
+		err := c.readFrames(process.stdout)
 		select {
 		case <-c.done:
 			return
 		default:
-			line, err := c.stdout.ReadString('\n')
-			if err != nil {
-				if !errors.Is(err, io.EOF) {
-					fmt.Printf("Error reading response: %v\n", err)
-				}
-			}
+		}
+		if !errors.Is(err, io.EOF) {
+			c.options.logger.Error("failed to read from server", "error", err)
+		}
+		if c.options.backoff == nil {
+			c.stopReading(err)
+			return
+		}
 
-			var response struct {
-				ID     int64           `json:"id"`
-				Result json.RawMessage `json:"result,omitempty"`
-				Error  *struct {
-					Code    int    `json:"code"`
-					Message string `json:"message"`
-				} `json:"error,omitempty"`
+		c.failPending()
+		ctx, cancel := contextUntil(context.Background(), c.done)
+		err = c.options.backoff.retry(ctx, err, func() error {
+			return c.restart(process)
+		})
+		cancel()
+		if err != nil {
+			select {
+			case <-c.done:
+			default:
+				c.options.logger.Error("failed to restart server", "error", err)
 			}
+			c.stopReading(err)
+			return
+		}
 
-			err = json.Unmarshal([]byte(line), &response)
-			if err != nil {
-				continue
-			}
+		if c.init.Load() != nil {
+			c.background.Go(c.reinitialize)
+		}
+	}
+}
+
+// readFrames handles messages from stdout until reading fails
+func (c *StdioMCPClient) readFrames(stdout *bufio.Reader) error {
+	for {
+		frame, _, err := readFrame(stdout, c.options.maxMessageSize)
+		if errors.Is(err, errMessageTooLarge) {
+			// The response it carried cannot be matched, fail them all
+			// rather than leave its caller waiting
+			c.options.logger.Error("skipped message over the size limit", "limit", c.options.maxMessageSize)
+			c.failPending()
+			continue
+		}
+		if err != nil {
+			return err
+		}
 
-			c.mu.Lock()
-			ch, ok := c.response[response.ID]
-			c.mu.Unlock()
+		var response struct {
+			ID     json.RawMessage        `json:"id"`
+			Method string                 `json:"method"`
+			Params json.RawMessage        `json:"params"`
+			Result json.RawMessage        `json:"result,omitempty"`
+			Error  *mcp.JSONRPCErrorError `json:"error,omitempty"`
+		}
 
-			if ok {
-				if response.Error != nil {
-					ch <- nil
-				} else {
-					ch <- &response.Result
-				}
+		err = json.Unmarshal(frame, &response)
+		if err != nil {
+			continue
+		}
 
-				c.mu.Lock()
-				delete(c.response, response.ID)
-				c.mu.Unlock()
+		if !isRequestID(response.ID) {
+			if response.Method != "" {
+				c.notifications.handle(response.Method, response.Params)
 			}
+			continue
 		}
+		if response.Method != "" {
+			// Answer off the reader, so a server that is busy writing to
+			// us cannot deadlock the exchange
+			answer := answerServerRequest(response.ID, response.Method)
+			c.background.Go(func() {
+				data, err := json.Marshal(answer)
+				if err == nil {
+					err = c.writeMessage(data)
+				}
+				if err != nil {
+					c.options.logger.Warn("failed to answer server request", "error", err)
+				}
+			})
+			continue
+		}
+
+		var id int64
+		if err := json.Unmarshal(response.ID, &id); err != nil {
+			continue
+		}
+		// Claim the waiter under the lock, so a duplicate response finds
+		// nobody and a caller that stopped waiting can never block us
+		c.mu.Lock()
+		ch, ok := c.response[id]
+		delete(c.response, id)
+		c.mu.Unlock()
+
+		if ok {
+			deliverResponse(ch, newResponse(response.Result, response.Error))
+		}
+	}
+}
+
+// failPending fails every request waiting for a response from a server
+// that has gone away
+func (c *StdioMCPClient) failPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range c.response {
+		deliverResponse(ch, &response{err: errRequestFailed})
+		delete(c.response, id)
+	}
+}
+
+// stopReading fails the pending requests and every later one, once the
+// server is gone for good
+func (c *StdioMCPClient) stopReading(cause error) {
+	c.mu.Lock()
+	c.broken = fmt.Errorf("%w: server connection lost: %v", errRequestFailed, cause)
+	c.mu.Unlock()
+	c.failPending()
+}
+
+// restart replaces the exited process with a new run of the command
+func (c *StdioMCPClient) restart(exited *stdioProcess) error {
+	_ = exited.wait()
+
+	process, err := startProcess(c.command, c.args)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	select {
+	case <-c.done:
+		// Closed while starting, Close already handled the old process
+		process.stdin.Close()
+		_ = process.wait()
+		return fmt.Errorf("client closed")
+	default:
+	}
+	// Requests written while the old process was going away will never
+	// be answered
+	c.failPending()
+	c.process = process
+	return nil
+}
+
+// reinitialize repeats the last initialize request against a restarted
+// server, so callers can carry on as if nothing happened
+func (c *StdioMCPClient) reinitialize() {
+	ctx, cancel := contextUntil(context.Background(), c.done)
+	defer cancel()
+
+	_, err := c.sendRequest(ctx, mcp.MethodInitialize, c.init.Load().params)
+	if err == nil && !c.options.skipInitializedNotification {
+		err = c.SendNotification(ctx, mcp.MethodNotificationInitialized, nil)
+	}
+	if err != nil && ctx.Err() == nil {
+		c.options.logger.Error("failed to initialize restarted server", "error", err)
 	}
 }
 
@@ -117,12 +327,23 @@ func (c *StdioMCPClient) sendRequest(
 	ctx context.Context,
 	method string,
 	params any,
-) (*json.RawMessage, error) {
-	if !c.initialized && method != "initialize" {
+) (result *json.RawMessage, err error) {
+	if c.init.Load() == nil && method != mcp.MethodInitialize {
 		return nil, fmt.Errorf("not initialized")
 	}
 
-	id := c.requestID.Add(1)
+	ctx, id, untrack := c.outstanding.start(ctx, &c.requestID)
+	defer untrack()
+	// A call cancelled before it was sent is not sent at all
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	params, err = requestParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	ctx, params, endSpan := c.options.startSpan(ctx, method, id, params)
+	defer func() { endSpan(err) }()
 
 	request := &struct {
 		ID      int64  `json:"id"`
@@ -140,27 +361,73 @@ func (c *StdioMCPClient) sendRequest(
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal msg: %w", err)
 	}
-	reqBytes = append(reqBytes, '\n')
 
-	responseCh := make(chan *json.RawMessage)
+	// Buffered so a response to an abandoned request never blocks the reader
+	responseCh := make(chan *response, 1)
 	c.mu.Lock()
+	if c.broken != nil {
+		c.mu.Unlock()
+		return nil, c.broken
+	}
 	c.response[request.ID] = responseCh
 	c.mu.Unlock()
 
-	if _, err := c.stdin.Write(reqBytes); err != nil {
+	if err := c.writeMessage(reqBytes); err != nil {
+		c.mu.Lock()
+		delete(c.response, id)
+		c.mu.Unlock()
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	select {
 	case <-ctx.Done():
+		c.mu.Lock()
 		delete(c.response, id)
-		return nil, ctx.Err()
+		c.mu.Unlock()
+		return nil, abandonRequest(ctx, c, id)
 	case resp := <-responseCh:
 		if resp == nil {
-			return nil, fmt.Errorf("request failed")
+			return nil, errRequestFailed
+		}
+		if resp.err != nil {
+			return nil, resp.err
 		}
-		return resp, nil
+		return &resp.result, nil
+	}
+}
+
+// writeMessage writes one framed message to the server. Writes are
+// serialized so concurrent callers never interleave partial frames.
+func (c *StdioMCPClient) writeMessage(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return writeFrame(c.process.stdin, data, c.options.framing == FramingContentLength)
+}
+
+func (c *StdioMCPClient) SendNotification(
+	ctx context.Context,
+	method string,
+	params any,
+) error {
+	notification := struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+	}{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
 	}
+
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	if err := c.writeMessage(notificationBytes); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
 }
 
 func (c *StdioMCPClient) Initialize(
@@ -169,17 +436,13 @@ func (c *StdioMCPClient) Initialize(
 	clientInfo mcp.Implementation,
 	protocolVersion string,
 ) (*mcp.InitializeResult, error) {
-	params := struct {
-		Capabilities    mcp.ClientCapabilities `json:"capabilities"`
-		ClientInfo      mcp.Implementation     `json:"clientInfo"`
-		ProtocolVersion string                 `json:"protocolVersion"`
-	}{
+	params := &mcp.InitializeRequestParams{
 		Capabilities:    capabilities,
 		ClientInfo:      clientInfo,
 		ProtocolVersion: protocolVersion,
 	}
 
-	resp, err := c.sendRequest(ctx, "initialize", params)
+	resp, err := c.sendRequest(ctx, mcp.MethodInitialize, params)
 	if err != nil {
 		return nil, err
 	}
@@ -189,26 +452,29 @@ func (c *StdioMCPClient) Initialize(
 		return nil, fmt.Errorf("failed to parse result: %w", err)
 	}
 
-	c.initialized = true
+	c.init.Store(&stdioInit{params: params, result: &result})
+
+	if !c.options.skipInitializedNotification {
+		if err := c.SendNotification(ctx, mcp.MethodNotificationInitialized, nil); err != nil {
+			return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+		}
+	}
+
 	return &result, nil
 }
 
 func (c *StdioMCPClient) Ping(ctx context.Context) error {
-	_, err := c.sendRequest(ctx, "ping", nil)
+	_, err := c.sendRequest(ctx, mcp.MethodPing, nil)
 	return err
 }
 
 func (c *StdioMCPClient) ListResources(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListResourcesResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	params := mcp.ListResourcesRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "resources/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesList, params)
 	if err != nil {
 		return nil, err
 	}
@@ -221,23 +487,66 @@ func (c *StdioMCPClient) ListResources(
 	return &result, nil
 }
 
+func (c *StdioMCPClient) ListResourceTemplates(
+	ctx context.Context,
+	cursor mcp.Cursor,
+) (*mcp.ListResourceTemplatesResult, error) {
+	params := mcp.ListResourceTemplatesRequestParams{Cursor: string(cursor)}
+
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesTemplatesList, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ListResourceTemplatesResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
 func (c *StdioMCPClient) ReadResource(
 	ctx context.Context,
 	uri string,
 ) (*mcp.ReadResourceResult, error) {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
+	params := mcp.ReadResourceRequestParams{Uri: uri}
+
+	response, err := c.sendRequest(ctx, mcp.MethodResourcesRead, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ReadResourceResult
+	if err := mcp.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	response, err := c.sendRequest(ctx, "resources/read", params)
+	return &result, nil
+}
+
+// ReadResourceWithProgress reads a resource, calling onProgress as the
+// server reports bytes read. Cancelling ctx stops the read on the server
+// and discards whatever was read so far.
+func (c *StdioMCPClient) ReadResourceWithProgress(
+	ctx context.Context,
+	uri string,
+	onProgress ReadProgressFunc,
+) (*mcp.ReadResourceResult, error) {
+	token, release := c.notifications.trackProgress(onProgress)
+	defer release()
+
+	meta := mcp.Meta{}
+	meta.SetProgressToken(mcp.IntProgressToken(token))
+	params := mcp.ReadResourceRequestParams{Uri: uri}
+
+	response, err := c.sendRequest(WithRequestMeta(ctx, meta), mcp.MethodResourcesRead, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.ReadResourceResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -245,38 +554,61 @@ func (c *StdioMCPClient) ReadResource(
 }
 
 func (c *StdioMCPClient) Subscribe(ctx context.Context, uri string) error {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
-	}
+	params := mcp.SubscribeRequestParams{Uri: uri}
 
-	_, err := c.sendRequest(ctx, "resources/subscribe", params)
+	_, err := c.sendRequest(ctx, mcp.MethodResourcesSubscribe, params)
 	return err
 }
 
 func (c *StdioMCPClient) Unsubscribe(ctx context.Context, uri string) error {
-	params := struct {
-		URI string `json:"uri"`
-	}{
-		URI: uri,
-	}
+	params := mcp.UnsubscribeRequestParams{Uri: uri}
 
-	_, err := c.sendRequest(ctx, "resources/unsubscribe", params)
+	_, err := c.sendRequest(ctx, mcp.MethodResourcesUnsubscribe, params)
+	if err == nil {
+		c.notifications.unsubscribeAll(uri)
+	}
 	return err
 }
 
+// SubscribeWithHandler subscribes to uri and calls handler every time the
+// server reports it updated, until Unsubscribe
+func (c *StdioMCPClient) SubscribeWithHandler(
+	ctx context.Context,
+	uri string,
+	handler ResourceUpdatedFunc,
+) error {
+	remove := c.notifications.subscribe(uri, &resourceSubscription{fn: handler})
+	if err := c.Subscribe(ctx, uri); err != nil {
+		remove()
+		return err
+	}
+	return nil
+}
+
+// SubscribeChannel subscribes to uri and delivers its URI on the returned
+// channel every time the server reports it updated. Updates that arrive
+// while one is still pending are coalesced. The channel is closed by
+// Unsubscribe.
+func (c *StdioMCPClient) SubscribeChannel(
+	ctx context.Context,
+	uri string,
+) (<-chan string, error) {
+	ch := make(chan string, 1)
+	remove := c.notifications.subscribe(uri, &resourceSubscription{ch: ch})
+	if err := c.Subscribe(ctx, uri); err != nil {
+		remove()
+		return nil, err
+	}
+	return ch, nil
+}
+
 func (c *StdioMCPClient) ListPrompts(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListPromptsResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	params := mcp.ListPromptsRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "prompts/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodPromptsList, params)
 	if err != nil {
 		return nil, err
 	}
@@ -294,21 +626,18 @@ func (c *StdioMCPClient) GetPrompt(
 	name string,
 	arguments map[string]string,
 ) (*mcp.GetPromptResult, error) {
-	params := struct {
-		Name      string            `json:"name"`
-		Arguments map[string]string `json:"arguments,omitempty"`
-	}{
+	params := mcp.GetPromptRequestParams{
 		Name:      name,
 		Arguments: arguments,
 	}
 
-	response, err := c.sendRequest(ctx, "prompts/get", params)
+	response, err := c.sendRequest(ctx, mcp.MethodPromptsGet, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.GetPromptResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -317,20 +646,16 @@ func (c *StdioMCPClient) GetPrompt(
 
 func (c *StdioMCPClient) ListTools(
 	ctx context.Context,
-	cursor *string,
-) (*mcp.ListToolsResult, error) {
-	params := struct {
-		Cursor *string `json:"cursor,omitempty"`
-	}{
-		Cursor: cursor,
-	}
+	cursor mcp.Cursor,
+) (*mcp.ListToolDefinitionsResult, error) {
+	params := mcp.ListToolsRequestParams{Cursor: string(cursor)}
 
-	response, err := c.sendRequest(ctx, "tools/list", params)
+	response, err := c.sendRequest(ctx, mcp.MethodToolsList, params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result mcp.ListToolsResult
+	var result mcp.ListToolDefinitionsResult
 	if err := json.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
@@ -343,38 +668,81 @@ func (c *StdioMCPClient) CallTool(
 	name string,
 	arguments map[string]interface{},
 ) (*mcp.CallToolResult, error) {
+	params := mcp.CallToolRequestParams{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	response, err := c.sendRequest(ctx, mcp.MethodToolsCall, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CallToolResult
+	if err := mcp.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// CallToolRaw invokes a tool with arguments that are already JSON encoded,
+// passing them through untouched so large numbers keep their precision
+func (c *StdioMCPClient) CallToolRaw(
+	ctx context.Context,
+	name string,
+	arguments json.RawMessage,
+) (*mcp.CallToolResult, error) {
+	if len(arguments) > 0 && !json.Valid(arguments) {
+		return nil, fmt.Errorf("invalid tool arguments: not valid JSON")
+	}
+
 	params := struct {
-		Name      string                 `json:"name"`
-		Arguments map[string]interface{} `json:"arguments,omitempty"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments,omitempty"`
 	}{
 		Name:      name,
 		Arguments: arguments,
 	}
 
-	response, err := c.sendRequest(ctx, "tools/call", params)
+	response, err := c.sendRequest(ctx, mcp.MethodToolsCall, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var result mcp.CallToolResult
-	if err := json.Unmarshal(*response, &result); err != nil {
+	if err := mcp.Unmarshal(*response, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	return &result, nil
 }
 
+// CallToolAsync starts a tool call in the background. The returned call
+// carries the request ID, so the call can be stopped with CancelRequest.
+func (c *StdioMCPClient) CallToolAsync(
+	ctx context.Context,
+	name string,
+	arguments map[string]interface{},
+) *PendingCall {
+	return callToolAsync(ctx, &c.requestID, c.outstanding, func(ctx context.Context) (*mcp.CallToolResult, error) {
+		return c.CallTool(ctx, name, arguments)
+	})
+}
+
+// CancelRequest stops waiting for the outstanding request id and sends
+// notifications/cancelled with reason to the server
+func (c *StdioMCPClient) CancelRequest(ctx context.Context, id int64, reason string) error {
+	return cancelRequest(ctx, c, c.outstanding, id, reason)
+}
+
 func (c *StdioMCPClient) SetLevel(
 	ctx context.Context,
 	level mcp.LoggingLevel,
 ) error {
-	params := struct {
-		Level mcp.LoggingLevel `json:"level"`
-	}{
-		Level: level,
-	}
+	params := mcp.SetLevelRequestParams{Level: level}
 
-	_, err := c.sendRequest(ctx, "logging/setLevel", params)
+	_, err := c.sendRequest(ctx, mcp.MethodLoggingSetLevel, params)
 	return err
 }
 
@@ -383,15 +751,12 @@ func (c *StdioMCPClient) Complete(
 	ref interface{},
 	argument mcp.CompleteRequestParamsArgument,
 ) (*mcp.CompleteResult, error) {
-	params := struct {
-		Ref      interface{}                       `json:"ref"`
-		Argument mcp.CompleteRequestParamsArgument `json:"argument"`
-	}{
+	params := mcp.CompleteRequestParams{
 		Ref:      ref,
 		Argument: argument,
 	}
 
-	response, err := c.sendRequest(ctx, "completion/complete", params)
+	response, err := c.sendRequest(ctx, mcp.MethodCompletionComplete, params)
 	if err != nil {
 		return nil, err
 	}
@@ -403,3 +768,31 @@ func (c *StdioMCPClient) Complete(
 
 	return &result, nil
 }
+
+// CompletePrompt requests completions for an argument of the named prompt
+func (c *StdioMCPClient) CompletePrompt(
+	ctx context.Context,
+	promptName string,
+	argument mcp.CompleteRequestParamsArgument,
+) (*mcp.CompleteResult, error) {
+	return c.Complete(ctx, mcp.NewPromptReference(promptName), argument)
+}
+
+// CompleteResourceTemplate requests completions for a variable of a
+// resource URI template
+func (c *StdioMCPClient) CompleteResourceTemplate(
+	ctx context.Context,
+	uriTemplate string,
+	argument mcp.CompleteRequestParamsArgument,
+) (*mcp.CompleteResult, error) {
+	return c.Complete(ctx, mcp.NewResourceReference(uriTemplate), argument)
+}
+
+// Snapshot exports the server's info, capabilities and full catalogs
+func (c *StdioMCPClient) Snapshot(ctx context.Context) (*ServerSnapshot, error) {
+	var result *mcp.InitializeResult
+	if init := c.init.Load(); init != nil {
+		result = init.result
+	}
+	return takeSnapshot(ctx, c, result)
+}
@@ -2,10 +2,14 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -85,7 +89,7 @@ func TestStdioMCPClient(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		result, err := client.ListResources(ctx, nil)
+		result, err := client.ListResources(ctx, "")
 		if err != nil {
 			t.Errorf("ListResources failed: %v", err)
 		}
@@ -133,7 +137,7 @@ func TestStdioMCPClient(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		result, err := client.ListPrompts(ctx, nil)
+		result, err := client.ListPrompts(ctx, "")
 		if err != nil {
 			t.Errorf("ListPrompts failed: %v", err)
 		}
@@ -161,7 +165,7 @@ func TestStdioMCPClient(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		result, err := client.ListTools(ctx, nil)
+		result, err := client.ListTools(ctx, "")
 		if err != nil {
 			t.Errorf("ListTools failed: %v", err)
 		}
@@ -181,12 +185,67 @@ func TestStdioMCPClient(t *testing.T) {
 
 		result, err := client.CallTool(ctx, "test-tool", args)
 		if err != nil {
-			t.Errorf("CallTool failed: %v", err)
+			t.Fatalf("CallTool failed: %v", err)
+		}
+
+		if result == nil {
+			t.Fatal("Expected non-nil result")
+		}
+
+		if content, ok := result.Content[0].(mcp.TextContent); !ok ||
+			content.Text != "tool result" {
+			t.Errorf("Expected decoded text content, got %#v", result.Content[0])
+		}
+	})
+
+	t.Run("AnswerServerPing", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		result, err := client.CallTool(ctx, "ping_client", nil)
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+
+		answer := result.Content[0].(mcp.TextContent).Text
+		if answer != `{"jsonrpc":"2.0","id":"server-1","result":{}}` {
+			t.Errorf("Unexpected ping answer: %s", answer)
+		}
+	})
+
+	t.Run("DuplicateResponse", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// The second copy of the response must not stall the read loop
+		for range 3 {
+			if _, err := client.CallTool(ctx, "duplicate", nil); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+		}
+		if err := client.Ping(ctx); err != nil {
+			t.Errorf("Ping after duplicate responses failed: %v", err)
+		}
+	})
+
+	t.Run("CallToolRaw", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		args := json.RawMessage(`{"id": 12345678901234567890}`)
+		result, err := client.CallToolRaw(ctx, "test-tool", args)
+		if err != nil {
+			t.Errorf("CallToolRaw failed: %v", err)
 		}
 
 		if result == nil {
 			t.Error("Expected non-nil result")
 		}
+
+		_, err = client.CallToolRaw(ctx, "test-tool", json.RawMessage(`{"id":`))
+		if err == nil {
+			t.Error("Expected error for invalid JSON arguments")
+		}
 	})
 
 	t.Run("SetLevel", func(t *testing.T) {
@@ -223,6 +282,87 @@ func TestStdioMCPClient(t *testing.T) {
 		}
 	})
 
+	t.Run("CompletePrompt", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		arg := mcp.CompleteRequestParamsArgument{Name: "test-arg", Value: "te"}
+		result, err := client.CompletePrompt(ctx, "test-prompt", arg)
+		if err != nil {
+			t.Fatalf("CompletePrompt failed: %v", err)
+		}
+		if got := result.Completion.Values[1]; got != "ref/prompt test-prompt" {
+			t.Errorf("Expected prompt reference, got %q", got)
+		}
+
+		result, err = client.CompleteResourceTemplate(ctx, "file:///{path}", arg)
+		if err != nil {
+			t.Fatalf("CompleteResourceTemplate failed: %v", err)
+		}
+		if got := result.Completion.Values[1]; got != "ref/resource file:///{path}" {
+			t.Errorf("Expected resource reference, got %q", got)
+		}
+	})
+
+	t.Run("ListResourceTemplates", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		result, err := client.ListResourceTemplates(ctx, "")
+		if err != nil {
+			t.Errorf("ListResourceTemplates failed: %v", err)
+		}
+
+		if result == nil || len(result.ResourceTemplates) != 1 {
+			t.Error("Expected one resource template")
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		snapshot, err := client.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+
+		if snapshot.ServerInfo.Name != "mock-server" {
+			t.Errorf("Expected server info, got %+v", snapshot.ServerInfo)
+		}
+		if len(snapshot.Tools) != 1 || len(snapshot.Prompts) != 1 ||
+			len(snapshot.Resources) != 1 || len(snapshot.ResourceTemplates) != 1 {
+			t.Errorf("Expected one entry per catalog, got %+v", snapshot)
+		}
+
+		if _, err := json.Marshal(snapshot); err != nil {
+			t.Errorf("Failed to marshal snapshot: %v", err)
+		}
+	})
+
+	t.Run("Concurrent Requests", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.ListTools(ctx, ""); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Errorf("Concurrent request failed: %v", err)
+		}
+	})
+
 	t.Run("Initialization Required", func(t *testing.T) {
 		// Create a new uninitialized client
 		uninitClient, err := NewStdioMCPClient(mockServerPath)
@@ -235,7 +375,7 @@ func TestStdioMCPClient(t *testing.T) {
 		defer cancel()
 
 		// Try to call a method before initialization
-		_, err = uninitClient.ListResources(ctx, nil)
+		_, err = uninitClient.ListResources(ctx, "")
 		if err == nil {
 			t.Error("Expected error when calling method before initialization")
 		}
@@ -245,7 +385,7 @@ func TestStdioMCPClient(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		_, err := client.ListResources(ctx, nil)
+		_, err := client.ListResources(ctx, "")
 		if err == nil {
 			t.Error("Expected error when context is cancelled")
 		}
@@ -257,12 +397,84 @@ func TestStdioMCPClient(t *testing.T) {
 
 		// This assumes the mock server will return an error for an unknown method
 		_, err := client.sendRequest(ctx, "invalid_method", nil)
-		if err == nil {
-			t.Error("Expected error for invalid method")
+		var rpcErr *mcp.JSONRPCErrorError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.MethodNotFound {
+			t.Errorf("Expected a method not found error, got %v", err)
 		}
 	})
 }
 
+func TestStdioMCPClientWait(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_wait")
+	if err := compileTestServer(mockServerPath); err != nil {
+		t.Fatalf("Failed to compile mock server: %v", err)
+	}
+	defer os.Remove(mockServerPath)
+
+	client, err := NewStdioMCPClient(mockServerPath)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	select {
+	case <-client.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reader did not stop after Close")
+	}
+	client.Wait()
+}
+
+func TestStdioMCPClientServerExit(t *testing.T) {
+	mockServerPath := filepath.Join("testdata", "mockstdio_exit")
+	if err := compileTestServer(mockServerPath); err != nil {
+		t.Fatalf("Failed to compile mock server: %v", err)
+	}
+	defer os.Remove(mockServerPath)
+
+	client, err := NewStdioMCPClientWithOptions(
+		mockServerPath,
+		nil,
+		WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// Without a backoff policy the pending call and every later one fail,
+	// even without a deadline
+	done := make(chan error, 2)
+	go func() {
+		_, err := client.CallTool(ctx, "crash", nil)
+		done <- err
+		_, err = client.CallTool(ctx, "test-tool", nil)
+		done <- err
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if !errors.Is(err, errRequestFailed) {
+				t.Errorf("Expected the request to fail, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Request blocked after the server exited")
+		}
+	}
+}
+
 func TestNewStdioMCPClient_Errors(t *testing.T) {
 	t.Run("Invalid Command", func(t *testing.T) {
 		_, err := NewStdioMCPClient("nonexistent_command")
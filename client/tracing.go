@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// Tracer starts a span around every request a client sends. It covers the
+// small part of the OpenTelemetry API the client needs, so an adapter over
+// go.opentelemetry.io/otel takes a few lines and the client itself does not
+// depend on it.
+type Tracer interface {
+	// Start begins a span for a request. Span attributes should include the
+	// method and request ID.
+	Start(ctx context.Context, method string, requestID int64) (context.Context, Span)
+	// Inject writes the trace context of ctx into carrier, for example a
+	// W3C traceparent entry. It is sent to the server in the request _meta.
+	Inject(ctx context.Context, carrier map[string]string)
+}
+
+// Span is a single traced request
+type Span interface {
+	// End finishes the span, marking it failed when err is not nil
+	End(err error)
+}
+
+// WithTracer traces every request with t and propagates its trace context
+// to the server in _meta
+func WithTracer(t Tracer) ClientOption {
+	return func(o *clientOptions) {
+		o.tracer = t
+	}
+}
+
+// startSpan starts a span for the request when tracing is enabled and
+// returns the params with the trace context added to _meta
+func (o clientOptions) startSpan(
+	ctx context.Context,
+	method string,
+	requestID int64,
+	params any,
+) (context.Context, any, func(error)) {
+	if o.tracer == nil {
+		return ctx, params, func(error) {}
+	}
+
+	ctx, span := o.tracer.Start(ctx, method, requestID)
+	carrier := make(map[string]string)
+	o.tracer.Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		fields := make(mcp.Meta, len(carrier))
+		for k, v := range carrier {
+			fields[k] = v
+		}
+		if withTrace, err := withMeta(params, fields); err == nil {
+			params = withTrace
+		}
+	}
+	return ctx, params, span.End
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/huangyul/go-mcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	method    string
+	requestID int64
+	err       error
+}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []recordedSpan
+}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	span   recordedSpan
+}
+
+func (t *fakeTracer) Start(
+	ctx context.Context,
+	method string,
+	requestID int64,
+) (context.Context, Span) {
+	return ctx, &fakeSpan{
+		tracer: t,
+		span:   recordedSpan{method: method, requestID: requestID},
+	}
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, carrier map[string]string) {
+	carrier["traceparent"] = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+}
+
+func (s *fakeSpan) End(err error) {
+	s.span.err = err
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s.span)
+	s.tracer.mu.Unlock()
+}
+
+func TestWithMeta(t *testing.T) {
+	fields := mcp.Meta{"traceparent": "00-abc-def-01"}
+
+	data, err := withMeta(nil, fields)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"_meta":{"traceparent":"00-abc-def-01"}}`, string(data))
+
+	params := map[string]any{
+		"name":  "add",
+		"_meta": map[string]any{"progressToken": 1},
+	}
+	data, err = withMeta(params, fields)
+	require.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{"name":"add","_meta":{"progressToken":1,"traceparent":"00-abc-def-01"}}`,
+		string(data),
+	)
+}
+
+// paramsRecorder keeps the raw params of the first request for each method
+type paramsRecorder struct {
+	server.MCPServer
+	mu     sync.Mutex
+	params map[string]json.RawMessage
+}
+
+func (r *paramsRecorder) Request(
+	ctx context.Context,
+	request server.JSONRPCRequest,
+) server.JSONRPCResponse {
+	r.mu.Lock()
+	if _, ok := r.params[request.Method]; !ok {
+		r.params[request.Method] = request.Params
+	}
+	r.mu.Unlock()
+	return r.MCPServer.Request(ctx, request)
+}
+
+func TestSSEMCPClientTracing(t *testing.T) {
+	mcpServer := server.NewDefaultServer("test-server", "1.0.0")
+	mcpServer.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			if name == "fail" {
+				return nil, fmt.Errorf("%w: missing arguments", server.ErrInvalidParams)
+			}
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+	recorder := &paramsRecorder{
+		MCPServer: mcpServer,
+		params:    make(map[string]json.RawMessage),
+	}
+	_, testServer := server.NewTestServer(recorder)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tracer := &fakeTracer{}
+	client, err := NewSSEMCPClient(testServer.URL+"/sse", WithTracer(tracer))
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	require.NoError(t, waitForEndpoint(client, 2*time.Second))
+
+	_, err = client.Initialize(
+		ctx,
+		mcp.ClientCapabilities{},
+		mcp.Implementation{Name: "test-client", Version: "1.0.0"},
+		"2024-11-05",
+	)
+	require.NoError(t, err)
+	_, err = client.CallTool(ctx, "add", map[string]interface{}{"a": 1})
+	require.NoError(t, err)
+	_, err = client.CallTool(ctx, "fail", nil)
+	require.Error(t, err)
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	require.Len(t, tracer.spans, 3)
+	assert.Equal(t, "initialize", tracer.spans[0].method)
+	assert.Equal(t, int64(1), tracer.spans[0].requestID)
+	assert.NoError(t, tracer.spans[0].err)
+	assert.Equal(t, "tools/call", tracer.spans[1].method)
+	assert.Equal(t, "tools/call", tracer.spans[2].method)
+	assert.Error(t, tracer.spans[2].err)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	var params struct {
+		Name string            `json:"name"`
+		Meta map[string]string `json:"_meta"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.params["tools/call"], &params))
+	assert.Equal(t, "add", params.Name)
+	assert.Contains(t, params.Meta["traceparent"], "0af7651916cd43dd8448eb211c80319c")
+}
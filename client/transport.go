@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrTransportClosed is returned when sending on a closed transport
+var ErrTransportClosed = errors.New("transport closed")
+
+// Transport moves raw JSON-RPC messages between a client and a server.
+// Implementations can be checked with the suite in the transporttest
+// package.
+type Transport interface {
+	// Start connects the transport. Messages can be sent and received once
+	// it returns.
+	Start(ctx context.Context) error
+	// Send writes one message. It is safe for concurrent use, and messages
+	// from one goroutine arrive in the order they were sent.
+	Send(ctx context.Context, message json.RawMessage) error
+	// Receive returns the channel incoming messages are delivered on, in
+	// the order they arrived. It is closed when the transport stops.
+	Receive() <-chan json.RawMessage
+	// Close disconnects the transport. Calling it again does nothing.
+	Close() error
+}
+
+// PipeTransport speaks newline-delimited JSON over a reader and a writer,
+// such as the pipes of a child process. It accepts Content-Length framed
+// messages as well. Besides Send and Receive it offers Call, which pairs a
+// request with its response.
+type PipeTransport struct {
+	reader   io.ReadCloser
+	writer   io.WriteCloser
+	messages chan json.RawMessage
+	done     chan struct{}
+	// readDone closes when reading stops
+	readDone chan struct{}
+	writeMu  sync.Mutex
+	start    sync.Once
+	close    sync.Once
+	callsMu  sync.Mutex
+	// calls holds the responses awaited by Call, by request ID
+	calls map[string]chan json.RawMessage
+}
+
+// NewPipeTransport returns a transport that reads messages from r and
+// writes them to w. Both are closed by Close.
+func NewPipeTransport(r io.ReadCloser, w io.WriteCloser) *PipeTransport {
+	return &PipeTransport{
+		reader:   r,
+		writer:   w,
+		messages: make(chan json.RawMessage),
+		done:     make(chan struct{}),
+		readDone: make(chan struct{}),
+		calls:    make(map[string]chan json.RawMessage),
+	}
+}
+
+func (t *PipeTransport) Start(ctx context.Context) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+	t.start.Do(func() {
+		go t.read()
+	})
+	return nil
+}
+
+func (t *PipeTransport) read() {
+	defer close(t.messages)
+	defer close(t.readDone)
+
+	reader := bufio.NewReader(t.reader)
+	for {
+		frame, _, err := readFrame(reader, 0)
+		if err != nil {
+			return
+		}
+		if t.deliverCall(frame) {
+			continue
+		}
+		select {
+		case t.messages <- frame:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *PipeTransport) Send(ctx context.Context, message json.RawMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	// Newline framing needs the message on a single line
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, message); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if err := writeFrame(t.writer, compact.Bytes(), false); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// Call sends request and waits for the response with the same ID. The
+// response goes to the caller rather than to Receive, so concurrent calls
+// cannot take each other's responses; notifications and requests from the
+// peer still arrive on Receive. The transport must be started.
+func (t *PipeTransport) Call(ctx context.Context, request json.RawMessage) (json.RawMessage, error) {
+	var envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(request, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	if !isRequestID(envelope.ID) {
+		return nil, fmt.Errorf("request has no id")
+	}
+	key := callKey(envelope.ID)
+	select {
+	case <-t.readDone:
+		return nil, ErrTransportClosed
+	default:
+	}
+
+	response := make(chan json.RawMessage, 1)
+	t.callsMu.Lock()
+	if _, ok := t.calls[key]; ok {
+		t.callsMu.Unlock()
+		return nil, fmt.Errorf("request %s is already in flight", key)
+	}
+	t.calls[key] = response
+	t.callsMu.Unlock()
+	defer func() {
+		t.callsMu.Lock()
+		delete(t.calls, key)
+		t.callsMu.Unlock()
+	}()
+
+	if err := t.Send(ctx, request); err != nil {
+		return nil, err
+	}
+	select {
+	case message := <-response:
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.done:
+		return nil, ErrTransportClosed
+	case <-t.readDone:
+		return nil, ErrTransportClosed
+	}
+}
+
+// deliverCall hands a response to the Call waiting for it and reports
+// whether there was one
+func (t *PipeTransport) deliverCall(message json.RawMessage) bool {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil ||
+		envelope.Method != "" || !isRequestID(envelope.ID) {
+		return false
+	}
+
+	t.callsMu.Lock()
+	response, ok := t.calls[callKey(envelope.ID)]
+	t.callsMu.Unlock()
+	if !ok {
+		return false
+	}
+	// The channel holds the one response a call waits for, a duplicate
+	// is dropped
+	select {
+	case response <- message:
+	default:
+	}
+	return true
+}
+
+// callKey is the form of a request ID calls are looked up by
+func callKey(id json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, id); err != nil {
+		return string(id)
+	}
+	return compact.String()
+}
+
+func (t *PipeTransport) Receive() <-chan json.RawMessage {
+	return t.messages
+}
+
+func (t *PipeTransport) Close() error {
+	var err error
+	t.close.Do(func() {
+		close(t.done)
+		err = errors.Join(t.writer.Close(), t.reader.Close())
+		// A transport that was never started still owes its receivers a
+		// closed channel
+		t.start.Do(func() {
+			close(t.messages)
+		})
+	})
+	return err
+}
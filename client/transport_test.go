@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeTransportCall(t *testing.T) {
+	toPeer, fromClient := io.Pipe()
+	toClient, fromPeer := io.Pipe()
+
+	// The peer collects three requests, then answers them in reverse order
+	// after a notification
+	go func() {
+		defer fromPeer.Close()
+		defer toPeer.Close()
+		reader := bufio.NewReader(toPeer)
+		var ids []json.RawMessage
+		for len(ids) < 3 {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var request struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(line, &request) == nil {
+				ids = append(ids, request.ID)
+			}
+		}
+		fmt.Fprintln(fromPeer, `{"jsonrpc":"2.0","method":"notifications/test"}`)
+		for i := len(ids) - 1; i >= 0; i-- {
+			fmt.Fprintf(fromPeer, "{\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":{\"id\":%s}}\n", ids[i], ids[i])
+		}
+	}()
+
+	transport := NewPipeTransport(toClient, fromClient)
+	defer transport.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, transport.Start(ctx))
+
+	var wg sync.WaitGroup
+	for id := 1; id <= 3; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := transport.Call(ctx, json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id)))
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"id":%d}}`, id, id), string(response))
+			}
+		}()
+	}
+
+	select {
+	case message := <-transport.Receive():
+		assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/test"}`, string(message))
+	case <-ctx.Done():
+		t.Fatal("notification not received")
+	}
+	wg.Wait()
+
+	_, err := transport.Call(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/test"}`))
+	assert.Error(t, err)
+
+	// Once the peer is gone calls fail rather than wait
+	for range transport.Receive() {
+	}
+	_, err = transport.Call(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":4,"method":"ping"}`))
+	assert.ErrorIs(t, err, ErrTransportClosed)
+}
@@ -43,7 +43,7 @@ func main() {
 
 	// List Tools
 	fmt.Println("Listing available tools...")
-	tools, err := c.ListTools(ctx, nil)
+	tools, err := c.ListTools(ctx, "")
 	if err != nil {
 		log.Fatalf("Failed to list tools: %v", err)
 	}
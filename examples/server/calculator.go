@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/huangyul/go-mcp/mcp"
+	mcpserver "github.com/huangyul/go-mcp/server"
 )
 
 // CalculationError represents an error during calculation
@@ -16,124 +17,66 @@ func (e CalculationError) Error() string {
 	return e.Message
 }
 
-func HandleListTools(
-	ctx context.Context,
-	cursor *string,
-) (*mcp.ListToolsResult, error) {
-	return &mcp.ListToolsResult{
-		Tools: []mcp.Tool{
-			{
-				Name:        "add",
-				Description: "Add two numbers",
-				InputSchema: mcp.ToolInputSchema{
-					Type: "object",
-					Properties: mcp.ToolInputSchemaProperties{
-						"a": map[string]interface{}{
-							"type":        "number",
-							"description": "First number",
-						},
-						"b": map[string]interface{}{
-							"type":        "number",
-							"description": "Second number",
-						},
-					},
-				},
-			},
-			{
-				Name:        "subtract",
-				Description: "Subtract two numbers",
-				InputSchema: mcp.ToolInputSchema{
-					Type: "object",
-					Properties: mcp.ToolInputSchemaProperties{
-						"a": map[string]interface{}{
-							"type":        "number",
-							"description": "First number",
-						},
-						"b": map[string]interface{}{
-							"type":        "number",
-							"description": "Second number",
-						},
-					},
-				},
-			},
-			{
-				Name:        "multiply",
-				Description: "Multiply two numbers",
-				InputSchema: mcp.ToolInputSchema{
-					Type: "object",
-					Properties: mcp.ToolInputSchemaProperties{
-						"a": map[string]interface{}{
-							"type":        "number",
-							"description": "First number",
-						},
-						"b": map[string]interface{}{
-							"type":        "number",
-							"description": "Second number",
-						},
-					},
-				},
-			},
-			{
-				Name:        "divide",
-				Description: "Divide two numbers",
-				InputSchema: mcp.ToolInputSchema{
-					Type: "object",
-					Properties: mcp.ToolInputSchemaProperties{
-						"a": map[string]interface{}{
-							"type":        "number",
-							"description": "First number (dividend)",
-						},
-						"b": map[string]interface{}{
-							"type":        "number",
-							"description": "Second number (divisor)",
-						},
-					},
-				},
-			},
-		},
-	}, nil
+// AddTools registers the calculator tools on s
+func AddTools(s mcpserver.MCPServer) {
+	s.AddTool(
+		calculatorTool("add", "Add two numbers", "First number", "Second number"),
+		binaryOperation(func(a, b float64) (float64, error) { return a + b, nil }),
+	)
+	s.AddTool(
+		calculatorTool("subtract", "Subtract two numbers", "First number", "Second number"),
+		binaryOperation(func(a, b float64) (float64, error) { return a - b, nil }),
+	)
+	s.AddTool(
+		calculatorTool("multiply", "Multiply two numbers", "First number", "Second number"),
+		binaryOperation(func(a, b float64) (float64, error) { return a * b, nil }),
+	)
+	s.AddTool(
+		calculatorTool("divide", "Divide two numbers", "First number (dividend)", "Second number (divisor)"),
+		binaryOperation(func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, &CalculationError{Message: "division by zero"}
+			}
+			return a / b, nil
+		}),
+	)
 }
 
-func HandleToolCall(
-	ctx context.Context,
-	name string,
-	args map[string]interface{},
-) (*mcp.CallToolResult, error) {
-	// Extract arguments
-	a, ok := args["a"].(float64)
-	if !ok {
-		return nil, &CalculationError{Message: "parameter 'a' must be a number"}
-	}
-	b, ok := args["b"].(float64)
-	if !ok {
-		return nil, &CalculationError{Message: "parameter 'b' must be a number"}
-	}
+func calculatorTool(name, description, aDescription, bDescription string) mcp.ToolDefinition {
+	return mcp.NewTool(name,
+		mcp.WithDescription(description),
+		mcp.WithNumber("a", mcp.Required(), mcp.Description(aDescription)),
+		mcp.WithNumber("b", mcp.Required(), mcp.Description(bDescription)),
+	)
+}
 
-	var result float64
+// binaryOperation turns op into a tool handler taking numbers a and b
+func binaryOperation(op func(a, b float64) (float64, error)) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		args := request.Params.Arguments
+		a, ok := args["a"].(float64)
+		if !ok {
+			return nil, &CalculationError{Message: "parameter 'a' must be a number"}
+		}
+		b, ok := args["b"].(float64)
+		if !ok {
+			return nil, &CalculationError{Message: "parameter 'b' must be a number"}
+		}
 
-	switch name {
-	case "add":
-		result = a + b
-	case "subtract":
-		result = a - b
-	case "multiply":
-		result = a * b
-	case "divide":
-		if b == 0 {
-			return nil, &CalculationError{Message: "division by zero"}
+		result, err := op(a, b)
+		if err != nil {
+			return nil, err
 		}
-		result = a / b
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
-	}
 
-	// Create response
-	return &mcp.CallToolResult{
-		Content: []interface{}{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("%.2f", result),
+		// Create response
+		return &mcp.CallToolResult{
+			Content: []interface{}{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("%.2f", result),
+				},
 			},
-		},
-	}, nil
+		}, nil
+	}
 }
@@ -11,9 +11,8 @@ func main() {
 	// Create MCP server
 	mcpServer := server.NewDefaultServer("calculator", "1.0.0")
 
-	// Register handlers
-	mcpServer.HandleCallTool(example.HandleToolCall)
-	mcpServer.HandleListTools(example.HandleListTools)
+	// Register tools
+	example.AddTools(mcpServer)
 
 	// Create and start SSE server
 	sseServer := server.NewSSEServer(mcpServer, "http://localhost:3001")
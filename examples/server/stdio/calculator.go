@@ -12,10 +12,11 @@ import (
 func main() {
 	mcpServer := server.NewDefaultServer("calculator", "1.0")
 
-	mcpServer.HandleCallTool(example.HandleToolCall)
-	mcpServer.HandleListTools(example.HandleListTools)
+	example.AddTools(mcpServer)
 
-	fmt.Fprintf(os.Stdout, "server is running\name: %s\nversion: %s\n\n", "calculator", "1.0")
+	// Standard output carries the messages, anything else goes to standard
+	// error
+	fmt.Fprintf(os.Stderr, "server is running\nname: %s\nversion: %s\n\n", "calculator", "1.0")
 
 	if err := server.ServeStdio(mcpServer); err != nil {
 		log.Fatal(err)
@@ -2,14 +2,16 @@ module github.com/huangyul/go-mcp
 
 go 1.24.4
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/google/uuid v1.6.0
+	github.com/stretchr/testify v1.11.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/djherbis/times v1.6.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.9 // indirect
 	github.com/gobwas/glob v0.2.3 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/mark3labs/mcp-filesystem-server v0.11.1 // indirect
 	github.com/mark3labs/mcp-go v0.32.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -0,0 +1,47 @@
+// Package framing holds the LSP style Content-Length framing shared by the
+// stdio client and server.
+package framing
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContentLengthHeader starts the header that carries the size of a message
+const ContentLengthHeader = "Content-Length:"
+
+// ReadHeaders consumes the header block starting with first and returns
+// the content length
+func ReadHeaders(r *bufio.Reader, first string) (int, error) {
+	length := -1
+	line := first
+	for line != "" {
+		if HasContentLength(line) {
+			value := strings.TrimSpace(line[len(ContentLengthHeader):])
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid content length: %q", value)
+			}
+			length = n
+		}
+
+		next, err := r.ReadString('\n')
+		if err != nil {
+			return 0, fmt.Errorf("failed to read message headers: %w", err)
+		}
+		line = strings.TrimRight(next, "\r\n")
+	}
+	if length < 0 {
+		return 0, fmt.Errorf("missing content length")
+	}
+	return length, nil
+}
+
+// HasContentLength reports whether line is a Content-Length header, in
+// any case
+func HasContentLength(line string) bool {
+	return len(line) >= len(ContentLengthHeader) &&
+		strings.EqualFold(line[:len(ContentLengthHeader)], ContentLengthHeader)
+}
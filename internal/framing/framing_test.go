@@ -0,0 +1,38 @@
+package framing
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeaders(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("Content-Type: application/json\r\n\r\n{}"))
+	length, err := ReadHeaders(r, "content-length: 2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, length)
+
+	rest, err := r.ReadString('}')
+	require.NoError(t, err)
+	assert.Equal(t, "{}", rest)
+
+	for _, input := range []struct{ first, rest string }{
+		{first: "Content-Length: -1", rest: "\r\n"},
+		{first: "Content-Length: x", rest: "\r\n"},
+		{first: "Content-Type: application/json", rest: "\r\n"},
+		{first: "Content-Length: 2", rest: ""},
+	} {
+		_, err := ReadHeaders(bufio.NewReader(strings.NewReader(input.rest)), input.first)
+		assert.Error(t, err, input.first)
+	}
+}
+
+func TestHasContentLength(t *testing.T) {
+	assert.True(t, HasContentLength("Content-Length: 2"))
+	assert.True(t, HasContentLength("CONTENT-LENGTH:2"))
+	assert.False(t, HasContentLength("Content-Type: application/json"))
+	assert.False(t, HasContentLength(`{"id":1}`))
+}
@@ -0,0 +1,24 @@
+package mcp
+
+import "math"
+
+// Annotations tell a host who an object is meant for and how important it
+// is. Resources, resource templates and content blocks carry them, each in
+// a generated type of its own with the same fields; convert a pointer to
+// one of them to the other, as in (*ResourceAnnotations)(annotations).
+type Annotations = AnnotatedAnnotations
+
+// AudioContentAnnotations are the annotations of AudioContent, which the
+// generated types lack
+type AudioContentAnnotations = Annotations
+
+// NewAnnotations returns annotations for audience with priority, between
+// 0 and 1. A priority outside that range is clamped to it, and NaN taken
+// as 0, so the annotations always decode again.
+func NewAnnotations(audience []Role, priority float64) *Annotations {
+	if math.IsNaN(priority) {
+		priority = 0
+	}
+	priority = max(0, min(priority, 1))
+	return &Annotations{Audience: audience, Priority: &priority}
+}
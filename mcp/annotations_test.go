@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotations(t *testing.T) {
+	annotations := NewAnnotations([]Role{RoleUser, RoleAssistant}, 1)
+	text := NewTextContent("hi")
+	text.Annotations = (*TextContentAnnotations)(annotations)
+	image := NewImageContent([]byte("hi"), "image/png")
+	image.Annotations = (*ImageContentAnnotations)(annotations)
+	embedded := NewEmbeddedTextResource("file:///a", "text/plain", "body")
+	embedded.Annotations = (*EmbeddedResourceAnnotations)(annotations)
+
+	result := CallToolResult{Content: []interface{}{text, image, embedded}}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content": [
+		{"type": "text", "text": "hi", "annotations": {"audience": ["user", "assistant"], "priority": 1}},
+		{"type": "image", "data": "aGk=", "mimeType": "image/png", "annotations": {"audience": ["user", "assistant"], "priority": 1}},
+		{"type": "resource", "resource": {"uri": "file:///a", "mimeType": "text/plain", "text": "body"}, "annotations": {"audience": ["user", "assistant"], "priority": 1}}
+	]}`, string(data))
+
+	var decoded CallToolResult
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, result, decoded)
+
+	var invalid Annotations
+	assert.Error(t, json.Unmarshal([]byte(`{"priority": 2}`), &invalid))
+	assert.Error(t, json.Unmarshal([]byte(`{"priority": -1}`), &invalid))
+	assert.Error(t, json.Unmarshal([]byte(`{"audience": ["everyone"]}`), &invalid))
+}
+
+func TestNewAnnotationsClampsPriority(t *testing.T) {
+	for _, tc := range []struct {
+		priority float64
+		expected float64
+	}{
+		{priority: 0.5, expected: 0.5},
+		{priority: 2, expected: 1},
+		{priority: -1, expected: 0},
+		{priority: math.NaN(), expected: 0},
+	} {
+		resource := NewResource("file:///a", "a", WithAnnotations([]Role{RoleUser}, tc.priority))
+		data, err := json.Marshal(resource)
+		require.NoError(t, err)
+
+		var decoded Resource
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.NotNil(t, decoded.Annotations)
+		assert.Equal(t, tc.expected, *decoded.Annotations.Priority)
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Audio provided to or from an LLM.
+type AudioContent struct {
+	// Annotations corresponds to the JSON schema field "annotations".
+	Annotations *AudioContentAnnotations `json:"annotations,omitempty" yaml:"annotations,omitempty" mapstructure:"annotations,omitempty"`
+
+	// The base64-encoded audio data.
+	Data string `json:"data" yaml:"data" mapstructure:"data"`
+
+	// The MIME type of the audio. Different providers may support different
+	// audio types.
+	MimeType string `json:"mimeType" yaml:"mimeType" mapstructure:"mimeType"`
+
+	// Type corresponds to the JSON schema field "type".
+	Type string `json:"type" yaml:"type" mapstructure:"type"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *AudioContent) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["data"]; raw != nil && !ok {
+		return fmt.Errorf("field data in AudioContent: required")
+	}
+	if _, ok := raw["mimeType"]; raw != nil && !ok {
+		return fmt.Errorf("field mimeType in AudioContent: required")
+	}
+	if _, ok := raw["type"]; raw != nil && !ok {
+		return fmt.Errorf("field type in AudioContent: required")
+	}
+	type Plain AudioContent
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = AudioContent(plain)
+	return nil
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Content types used as the "type" discriminator of content unions
+const (
+	TextContentType      = "text"
+	ImageContentType     = "image"
+	AudioContentType     = "audio"
+	EmbeddedResourceType = "resource"
+	ResourceLinkType     = "resource_link"
+)
+
+// DecodeContent turns the generic JSON form of a content union member, as
+// left in interface{} fields by encoding/json, into TextContent,
+// ImageContent, AudioContent, EmbeddedResource or ResourceLink. Unknown
+// content types and values that are already concrete are returned
+// unchanged.
+func DecodeContent(v any) (any, error) {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+
+	switch object["type"] {
+	case TextContentType:
+		var content TextContent
+		if err := convert(object, &content); err != nil {
+			return nil, err
+		}
+		return content, nil
+
+	case ImageContentType:
+		var content ImageContent
+		if err := convert(object, &content); err != nil {
+			return nil, err
+		}
+		return content, nil
+
+	case AudioContentType:
+		var content AudioContent
+		if err := convert(object, &content); err != nil {
+			return nil, err
+		}
+		return content, nil
+
+	case EmbeddedResourceType:
+		var content EmbeddedResource
+		if err := convert(object, &content); err != nil {
+			return nil, err
+		}
+		if err := decodeEmbeddedResource(&content); err != nil {
+			return nil, err
+		}
+		return content, nil
+
+	case ResourceLinkType:
+		var content ResourceLink
+		if err := convert(object, &content); err != nil {
+			return nil, err
+		}
+		return content, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// NewTextContent returns text content
+func NewTextContent(text string) TextContent {
+	return TextContent{Type: TextContentType, Text: text}
+}
+
+// NewImageContent returns image content, base64 encoding data
+func NewImageContent(data []byte, mimeType string) ImageContent {
+	return ImageContent{
+		Type:     ImageContentType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// NewAudioContent returns audio content, base64 encoding data
+func NewAudioContent(data []byte, mimeType string) AudioContent {
+	return AudioContent{
+		Type:     AudioContentType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// NewEmbeddedResource returns content embedding the contents of a
+// resource, a TextResourceContents or BlobResourceContents
+func NewEmbeddedResource(contents any) EmbeddedResource {
+	return EmbeddedResource{Type: EmbeddedResourceType, Resource: contents}
+}
+
+// NewEmbeddedTextResource returns content embedding a text resource
+func NewEmbeddedTextResource(uri, mimeType, text string) EmbeddedResource {
+	return NewEmbeddedResource(NewTextResourceContents(uri, mimeType, text))
+}
+
+// NewEmbeddedBlobResource returns content embedding a binary resource
+func NewEmbeddedBlobResource(uri, mimeType string, data []byte) EmbeddedResource {
+	return NewEmbeddedResource(NewBlobResourceContents(uri, mimeType, data))
+}
+
+// DecodeResourceContents turns the generic JSON form of resource contents
+// into TextResourceContents or BlobResourceContents, depending on which of
+// text or blob is set
+func DecodeResourceContents(v any) (any, error) {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+
+	if _, isBlob := object["blob"]; isBlob {
+		var contents BlobResourceContents
+		if err := convert(object, &contents); err != nil {
+			return nil, err
+		}
+		return contents, nil
+	}
+	if _, isText := object["text"]; isText {
+		var contents TextResourceContents
+		if err := convert(object, &contents); err != nil {
+			return nil, err
+		}
+		return contents, nil
+	}
+	return v, nil
+}
+
+// DecodeContents replaces the generic content union members of a result
+// in place. It understands CallToolResult, GetPromptResult,
+// ReadResourceResult, CreateMessageResult, PromptMessage, SamplingMessage
+// and EmbeddedResource; other values are left alone. Unmarshal calls it
+// after decoding.
+func DecodeContents(v any) error {
+	var err error
+	switch v := v.(type) {
+	case *CallToolResult:
+		for i := range v.Content {
+			if v.Content[i], err = DecodeContent(v.Content[i]); err != nil {
+				return err
+			}
+		}
+	case *GetPromptResult:
+		for i := range v.Messages {
+			if err := DecodeContents(&v.Messages[i]); err != nil {
+				return err
+			}
+		}
+	case *ReadResourceResult:
+		for i := range v.Contents {
+			if v.Contents[i], err = DecodeResourceContents(v.Contents[i]); err != nil {
+				return err
+			}
+		}
+	case *CreateMessageResult:
+		v.Content, err = DecodeContent(v.Content)
+	case *PromptMessage:
+		v.Content, err = DecodeContent(v.Content)
+	case *SamplingMessage:
+		v.Content, err = DecodeContent(v.Content)
+	case *EmbeddedResource:
+		err = decodeEmbeddedResource(v)
+	}
+	return err
+}
+
+// convert decodes the generic JSON object into target, running its
+// generated validation
+func convert(object map[string]interface{}, target any) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode content: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeContents(t *testing.T) {
+	var toolResult CallToolResult
+	require.NoError(t, json.Unmarshal([]byte(`{"content": [
+		{"type": "text", "text": "hello"},
+		{"type": "image", "data": "aGk=", "mimeType": "image/png"},
+		{"type": "resource", "resource": {"uri": "file:///a", "blob": "aGk="}},
+		{"type": "audio", "data": "aGk=", "mimeType": "audio/wav"},
+		{"type": "video", "data": "aGk="}
+	]}`), &toolResult))
+	require.NoError(t, DecodeContents(&toolResult))
+
+	assert.Equal(t, TextContent{Type: "text", Text: "hello"}, toolResult.Content[0])
+	assert.Equal(t, ImageContent{Type: "image", Data: "aGk=", MimeType: "image/png"}, toolResult.Content[1])
+	embedded, ok := toolResult.Content[2].(EmbeddedResource)
+	require.True(t, ok)
+	assert.Equal(t, BlobResourceContents{Uri: "file:///a", Blob: "aGk="}, embedded.Resource)
+	assert.Equal(t, AudioContent{Type: "audio", Data: "aGk=", MimeType: "audio/wav"}, toolResult.Content[3])
+	// Unknown content types are kept as they were decoded
+	assert.IsType(t, map[string]interface{}{}, toolResult.Content[4])
+
+	var promptResult GetPromptResult
+	require.NoError(t, json.Unmarshal([]byte(`{"messages": [
+		{"role": "user", "content": {"type": "text", "text": "hi"}}
+	]}`), &promptResult))
+	require.NoError(t, DecodeContents(&promptResult))
+	assert.Equal(t, TextContent{Type: "text", Text: "hi"}, promptResult.Messages[0].Content)
+
+	var readResult ReadResourceResult
+	require.NoError(t, json.Unmarshal([]byte(`{"contents": [
+		{"uri": "file:///a", "text": "body", "mimeType": "text/plain"}
+	]}`), &readResult))
+	require.NoError(t, DecodeContents(&readResult))
+	assert.Equal(t, TextResourceContents{Uri: "file:///a", Text: "body", MimeType: "text/plain"}, readResult.Contents[0])
+
+	// Required fields are still enforced
+	toolResult = CallToolResult{Content: []interface{}{
+		map[string]interface{}{"type": "image", "data": "aGk="},
+	}}
+	assert.Error(t, DecodeContents(&toolResult))
+}
+
+func TestNewContent(t *testing.T) {
+	audio := NewAudioContent([]byte("hi"), "audio/wav")
+	data, err := json.Marshal(audio)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "audio", "data": "aGk=", "mimeType": "audio/wav"}`, string(data))
+
+	var message PromptMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"role": "user", "content": `+string(data)+`}`), &message))
+	require.NoError(t, DecodeContents(&message))
+	assert.Equal(t, audio, message.Content)
+
+	assert.Equal(t, ImageContent{Type: "image", Data: "aGk=", MimeType: "image/png"}, NewImageContent([]byte("hi"), "image/png"))
+	assert.Equal(t, TextContent{Type: "text", Text: "hi"}, NewTextContent("hi"))
+
+	var content AudioContent
+	assert.Error(t, json.Unmarshal([]byte(`{"type": "audio", "data": "aGk="}`), &content))
+}
+
+func TestEmbeddedResource(t *testing.T) {
+	result := CallToolResult{Content: []interface{}{
+		NewEmbeddedTextResource("file:///a.txt", "text/plain", "body"),
+		NewEmbeddedBlobResource("file:///a.bin", "", []byte("hi")),
+	}}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content": [
+		{"type": "resource", "resource": {"uri": "file:///a.txt", "mimeType": "text/plain", "text": "body"}},
+		{"type": "resource", "resource": {"uri": "file:///a.bin", "blob": "aGk="}}
+	]}`, string(data))
+
+	var decoded CallToolResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, DecodeContents(&decoded))
+	assert.Equal(t, result.Content, decoded.Content)
+
+	// Embedded contents must be text or blob
+	decoded = CallToolResult{Content: []interface{}{
+		map[string]interface{}{"type": "resource", "resource": map[string]interface{}{"uri": "file:///a"}},
+	}}
+	assert.Error(t, DecodeContents(&decoded))
+}
+
+func TestResourceLink(t *testing.T) {
+	link := NewResourceLink(Resource{Uri: "file:///report.txt", Name: "report", MimeType: "text/plain"})
+	data, err := json.Marshal(link)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "resource_link", "uri": "file:///report.txt", "name": "report", "mimeType": "text/plain"}`, string(data))
+
+	var result CallToolResult
+	require.NoError(t, json.Unmarshal([]byte(`{"content": [`+string(data)+`]}`), &result))
+	require.NoError(t, DecodeContents(&result))
+	assert.Equal(t, link, result.Content[0])
+
+	result = CallToolResult{Content: []interface{}{
+		map[string]interface{}{"type": "resource_link", "uri": "file:///a"},
+	}}
+	assert.Error(t, DecodeContents(&result))
+}
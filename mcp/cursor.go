@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// maxCursorLength bounds the cursors DecodeCursor accepts
+const maxCursorLength = 1024
+
+// ErrInvalidCursor is returned for cursors DecodeCursor did not make
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorState is the pagination state a cursor made by EncodeCursor
+// carries: where the next page starts and, for filtered listings, a hash of
+// the filter, so that a cursor is not continued with another one
+type CursorState struct {
+	Offset     int    `json:"mcpOffset"`
+	FilterHash string `json:"mcpFilter,omitempty"`
+}
+
+// EncodeCursor returns an opaque cursor for state
+func EncodeCursor(state CursorState) Cursor {
+	data, _ := json.Marshal(state)
+	return Cursor(base64.RawURLEncoding.EncodeToString(data))
+}
+
+// DecodeCursor returns the state of a cursor made by EncodeCursor. Other
+// cursors, such as those of a list handler with its own scheme, return
+// ErrInvalidCursor.
+func DecodeCursor(cursor Cursor) (CursorState, error) {
+	if len(cursor) == 0 || len(cursor) > maxCursorLength {
+		return CursorState{}, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return CursorState{}, ErrInvalidCursor
+	}
+	var state *struct {
+		Offset     *int   `json:"mcpOffset"`
+		FilterHash string `json:"mcpFilter"`
+	}
+	if json.Unmarshal(data, &state) != nil || state == nil || state.Offset == nil || *state.Offset < 0 {
+		return CursorState{}, ErrInvalidCursor
+	}
+	return CursorState{Offset: *state.Offset, FilterHash: state.FilterHash}, nil
+}
+
+// FilterHash returns a short hash of the parts of a filter, for
+// CursorState.FilterHash
+func FilterHash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	state := CursorState{Offset: 20, FilterHash: FilterHash("name", "calc")}
+	cursor := EncodeCursor(state)
+	decoded, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, state, decoded)
+
+	decoded, err = DecodeCursor(EncodeCursor(CursorState{}))
+	require.NoError(t, err)
+	assert.Equal(t, CursorState{}, decoded)
+
+	assert.Equal(t, FilterHash("a", "b"), FilterHash("a", "b"))
+	assert.NotEqual(t, FilterHash("ab"), FilterHash("a", "b"))
+
+	for _, cursor := range []Cursor{
+		"",
+		"not a cursor",
+		EncodeCursor(CursorState{Offset: -1}),
+		Cursor("bnVsbA"),          // null
+		Cursor("eyJvdGhlciI6MX0"), // {"other":1}
+	} {
+		_, err := DecodeCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
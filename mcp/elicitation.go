@@ -0,0 +1,60 @@
+package mcp
+
+// The schema the types are generated from predates elicitation, so its
+// types are declared here.
+
+// ElicitRequest is a request from the server to ask the user for input
+// through the client. The input is described by a flat object schema
+// whose properties are strings, numbers, integers, booleans or enums.
+type ElicitRequest struct {
+	// Method corresponds to the JSON schema field "method".
+	Method string `json:"method"`
+
+	// Params corresponds to the JSON schema field "params".
+	Params ElicitRequestParams `json:"params"`
+}
+
+type ElicitRequestParams struct {
+	// Meta is the _meta of the request
+	Meta Meta `json:"_meta,omitempty"`
+
+	// The message shown to the user
+	Message string `json:"message"`
+
+	// The schema of the content the user is asked for
+	RequestedSchema ElicitationSchema `json:"requestedSchema"`
+}
+
+// ElicitationSchema is the object schema of requested input. Each property
+// is a JSON Schema of a primitive type, such as {"type": "string"}.
+type ElicitationSchema struct {
+	Type       string                            `json:"type"`
+	Properties map[string]map[string]interface{} `json:"properties"`
+	Required   []string                          `json:"required,omitempty"`
+}
+
+// ElicitAction is how the user responded to an elicitation
+type ElicitAction string
+
+const (
+	// ElicitActionAccept means the user submitted the requested content
+	ElicitActionAccept ElicitAction = "accept"
+	// ElicitActionDecline means the user explicitly refused to answer
+	ElicitActionDecline ElicitAction = "decline"
+	// ElicitActionCancel means the user dismissed the request without
+	// choosing
+	ElicitActionCancel ElicitAction = "cancel"
+)
+
+// ElicitResult is the answer of the client to an ElicitRequest
+type ElicitResult struct {
+	// This result property is reserved by the protocol to allow clients and servers
+	// to attach additional metadata to their responses.
+	Meta map[string]interface{} `json:"_meta,omitempty"`
+
+	// Action corresponds to the JSON schema field "action".
+	Action ElicitAction `json:"action"`
+
+	// The submitted content, present when Action is accept
+	Content map[string]interface{} `json:"content,omitempty"`
+}
@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EmptyCollections controls how empty slices and maps in results are
+// encoded. Some non-Go clients reject null where the spec expects an array.
+type EmptyCollections int
+
+const (
+	// EmptyAsNull keeps the encoding/json default of null for nil slices
+	// and maps. Results are encoded as handlers return them.
+	EmptyAsNull EmptyCollections = iota
+	// EmptyAsEmpty encodes nil slices as [] and nil maps as {}
+	EmptyAsEmpty
+	// EmptyOmitted leaves null, [] and {} values of optional fields out of
+	// objects entirely. Collections the spec requires are encoded as [] and
+	// {} instead.
+	EmptyOmitted
+)
+
+// NormalizeEmpty prepares v for encoding under policy. v itself is never
+// modified. With EmptyAsEmpty the result is a copy of v, of the same type,
+// with nil collections replaced. With EmptyOmitted the result is the
+// generic JSON form of v.
+func NormalizeEmpty(v any, policy EmptyCollections) (any, error) {
+	switch policy {
+	case EmptyAsEmpty:
+		value := reflect.ValueOf(v)
+		if !value.IsValid() {
+			return v, nil
+		}
+		return fillEmpty(value).Interface(), nil
+
+	case EmptyOmitted:
+		value := reflect.ValueOf(v)
+		if value.IsValid() {
+			value = fillEmpty(value)
+			v = value.Interface()
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var generic any
+		if err := decoder.Decode(&generic); err != nil {
+			return nil, fmt.Errorf("failed to decode result: %w", err)
+		}
+		return omitOptional(value, generic), nil
+
+	default:
+		return v, nil
+	}
+}
+
+// fillEmpty returns a copy of v with nil slices and maps replaced by empty
+// ones. Pointers, slices and maps are copied rather than shared so the
+// caller's value is left as it was. Byte slices are left alone as they
+// encode as strings.
+func fillEmpty(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(fillEmpty(v.Elem()))
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(fillEmpty(v.Elem()))
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(fillEmpty(v.Field(i)))
+			}
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(fillEmpty(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Map:
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), fillEmpty(iter.Value()))
+		}
+		return copied
+
+	default:
+		return v
+	}
+}
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+// omitOptional drops null, [] and {} members from the objects in generic,
+// the JSON form of v, where they come from optional fields. Fields tagged
+// omitempty are optional, as in the generated types; the others are
+// required and kept. Values that marshal themselves are left as they are.
+func omitOptional(v reflect.Value, generic any) any {
+	if !v.IsValid() || v.Type().Implements(marshalerType) {
+		return generic
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return generic
+		}
+		return omitOptional(v.Elem(), generic)
+
+	case reflect.Struct:
+		if object, ok := generic.(map[string]any); ok {
+			omitFields(v, object)
+		}
+		return generic
+
+	case reflect.Slice, reflect.Array:
+		array, ok := generic.([]any)
+		if !ok || len(array) != v.Len() {
+			return generic
+		}
+		for i := range array {
+			array[i] = omitOptional(v.Index(i), array[i])
+		}
+		return array
+
+	case reflect.Map:
+		object, ok := generic.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return generic
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if elem, ok := object[key]; ok {
+				object[key] = omitOptional(iter.Value(), elem)
+			}
+		}
+		return object
+
+	default:
+		return generic
+	}
+}
+
+// omitFields applies omitOptional to the members of object that come from
+// the fields of the struct v, including those of embedded structs
+func omitFields(v reflect.Value, object map[string]any) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				omitFields(embedded, object)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		elem, ok := object[name]
+		if !ok {
+			continue
+		}
+		elem = omitOptional(v.Field(i), elem)
+		if isEmptyJSON(elem) && slices.Contains(strings.Split(options, ","), "omitempty") {
+			delete(object, name)
+			continue
+		}
+		object[name] = elem
+	}
+}
+
+func isEmptyJSON(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
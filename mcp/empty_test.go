@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalNormalized(t *testing.T, v any, policy EmptyCollections) string {
+	t.Helper()
+	normalized, err := NormalizeEmpty(v, policy)
+	require.NoError(t, err)
+	data, err := json.Marshal(normalized)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNormalizeEmpty(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   EmptyCollections
+		expected string
+	}{
+		{name: "Empty", policy: EmptyAsEmpty, expected: `{"completion":{"values":[]}}`},
+		{name: "Omitted", policy: EmptyOmitted, expected: `{"completion":{"values":[]}}`},
+		{name: "Null", policy: EmptyAsNull, expected: `{"completion":{"values":null}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.JSONEq(t, tc.expected, marshalNormalized(t, &CompleteResult{}, tc.policy))
+		})
+	}
+
+	assert.JSONEq(t, `{"tools":[]}`, marshalNormalized(t, &ListToolDefinitionsResult{}, EmptyAsEmpty))
+	assert.JSONEq(t, `{"tools":[]}`, marshalNormalized(t, &ListToolDefinitionsResult{}, EmptyOmitted))
+	assert.JSONEq(t, `{"tools":null}`, marshalNormalized(t, &ListToolDefinitionsResult{}, EmptyAsNull))
+}
+
+func TestNormalizeEmptyInterfaces(t *testing.T) {
+	// Values held in interfaces and passed by value are filled too
+	result := CallToolResult{
+		Content: []interface{}{
+			EmbeddedResource{Type: "resource"},
+		},
+	}
+	assert.JSONEq(
+		t,
+		`{"content":[{"type":"resource","resource":null}]}`,
+		marshalNormalized(t, result, EmptyAsEmpty),
+	)
+	assert.JSONEq(
+		t,
+		`{"content":[]}`,
+		marshalNormalized(t, CallToolResult{}, EmptyAsEmpty),
+	)
+}
+
+func TestNormalizeEmptyOmitted(t *testing.T) {
+	// Only optional members are left out, required ones are kept even when
+	// they are empty
+	assert.JSONEq(t, `{"content":[]}`, marshalNormalized(t, CallToolResult{}, EmptyOmitted))
+	result := &CallToolResult{
+		Content: []interface{}{
+			EmbeddedResource{Type: "resource", Annotations: &EmbeddedResourceAnnotations{}},
+		},
+	}
+	assert.JSONEq(
+		t,
+		`{"content":[{"type":"resource","resource":null}]}`,
+		marshalNormalized(t, result, EmptyOmitted),
+	)
+}
+
+func TestNormalizeEmptyCopies(t *testing.T) {
+	// Results shared between requests are left as the handler built them
+	result := &CallToolResult{
+		Content: []interface{}{&EmbeddedResource{Type: "resource"}},
+	}
+	normalized, err := NormalizeEmpty(result, EmptyAsEmpty)
+	require.NoError(t, err)
+
+	copied, ok := normalized.(*CallToolResult)
+	require.True(t, ok)
+	assert.NotSame(t, result, copied)
+	assert.NotNil(t, copied.Meta)
+	assert.Nil(t, result.Meta)
+	assert.NotSame(t, result.Content[0], copied.Content[0])
+}
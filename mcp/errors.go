@@ -0,0 +1,38 @@
+package mcp
+
+import "fmt"
+
+// ErrorCode is the code of a JSON-RPC error, the Code of JSONRPCErrorError
+type ErrorCode = int
+
+// Error codes of JSON-RPC
+const (
+	ParseError     ErrorCode = -32700
+	InvalidRequest ErrorCode = -32600
+	MethodNotFound ErrorCode = -32601
+	InvalidParams  ErrorCode = -32602
+	InternalError  ErrorCode = -32603
+)
+
+// Error codes of MCP, in the range JSON-RPC leaves to servers
+const (
+	// ServerUnavailable answers requests the server cannot take now, as it
+	// is busy, rate limiting or shutting down
+	ServerUnavailable ErrorCode = -32000
+	// Unauthorized answers requests whose credentials were rejected
+	Unauthorized ErrorCode = -32001
+	// ResourceNotFound answers reads of resources that do not exist
+	ResourceNotFound ErrorCode = -32002
+)
+
+// Error makes the error object of a JSON-RPC error response an error.
+// Errors match each other in errors.Is by code.
+func (j *JSONRPCErrorError) Error() string {
+	return fmt.Sprintf("%s (%d)", j.Message, j.Code)
+}
+
+// Is reports whether target is a *JSONRPCErrorError with the same code
+func (j *JSONRPCErrorError) Is(target error) bool {
+	t, ok := target.(*JSONRPCErrorError)
+	return ok && t.Code == j.Code
+}
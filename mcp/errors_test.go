@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRPCErrorError(t *testing.T) {
+	var err error = &JSONRPCErrorError{Code: MethodNotFound, Message: "method not found: foo"}
+	assert.EqualError(t, err, "method not found: foo (-32601)")
+
+	wrapped := fmt.Errorf("call failed: %w", err)
+	assert.True(t, errors.Is(wrapped, &JSONRPCErrorError{Code: MethodNotFound}))
+	assert.False(t, errors.Is(wrapped, &JSONRPCErrorError{Code: InvalidParams}))
+	var rpcErr *JSONRPCErrorError
+	require.True(t, errors.As(wrapped, &rpcErr))
+	assert.Equal(t, MethodNotFound, rpcErr.Code)
+
+	var response JSONRPCError
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"jsonrpc": "2.0", "id": 1,
+		"error": {"code": -32002, "message": "resource not found", "data": {"uri": "file:///a"}}
+	}`), &response))
+	assert.Equal(t, ResourceNotFound, response.Error.Code)
+	assert.Equal(t, map[string]interface{}{"uri": "file:///a"}, response.Error.Data)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"message": "no code"}`), &JSONRPCErrorError{}))
+	assert.Error(t, json.Unmarshal([]byte(`{"jsonrpc": "2.0", "id": 1}`), &response))
+}
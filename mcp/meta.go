@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProgressTokenMetaKey is the _meta field a request asks for progress
+// notifications with
+const ProgressTokenMetaKey = "progressToken"
+
+// Meta is the _meta object of a request, result or notification. The
+// protocol reserves it for metadata such as progress tokens, trace context
+// and extension data, keyed by name.
+type Meta map[string]interface{}
+
+// ProgressToken returns the progress token and whether there is a valid
+// one
+func (m Meta) ProgressToken() (AnyProgressToken, bool) {
+	switch value := m[ProgressTokenMetaKey].(type) {
+	case nil:
+		return AnyProgressToken{}, false
+	case AnyProgressToken:
+		return value, true
+	default:
+		// Decoded _meta holds a string or a float64
+		data, err := json.Marshal(value)
+		if err != nil {
+			return AnyProgressToken{}, false
+		}
+		token, err := progressTokenOf(data)
+		return token, err == nil
+	}
+}
+
+// SetProgressToken asks for progress notifications tagged with token
+func (m Meta) SetProgressToken(token AnyProgressToken) {
+	m[ProgressTokenMetaKey] = token
+}
+
+// MetaOf returns the _meta object of the encoded params of a request or
+// notification, or of a result. It returns nil when there is none.
+func MetaOf(data json.RawMessage) (Meta, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var object struct {
+		Meta Meta `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to decode _meta: %w", err)
+	}
+	return object.Meta, nil
+}
@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeta(t *testing.T) {
+	meta, err := MetaOf(json.RawMessage(`{"uri": "file:///a", "_meta": {"progressToken": "t1", "traceparent": "00-abc"}}`))
+	require.NoError(t, err)
+	token, ok := meta.ProgressToken()
+	require.True(t, ok)
+	assert.Equal(t, StringProgressToken("t1"), token)
+	assert.Equal(t, "00-abc", meta["traceparent"])
+
+	meta, err = MetaOf(json.RawMessage(`{"uri": "file:///a"}`))
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	_, ok = meta.ProgressToken()
+	assert.False(t, ok)
+
+	meta, err = MetaOf(nil)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	_, err = MetaOf(json.RawMessage(`{"_meta": 1}`))
+	assert.Error(t, err)
+
+	meta = Meta{}
+	meta.SetProgressToken(IntProgressToken(7))
+	data, err := json.Marshal(ElicitRequestParams{Meta: meta, Message: "name?"})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_meta":{"progressToken":7}`)
+}
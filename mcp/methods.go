@@ -0,0 +1,38 @@
+package mcp
+
+// Methods of the requests a client sends to a server
+const (
+	MethodInitialize             = "initialize"
+	MethodPing                   = "ping"
+	MethodResourcesList          = "resources/list"
+	MethodResourcesTemplatesList = "resources/templates/list"
+	MethodResourcesRead          = "resources/read"
+	MethodResourcesSubscribe     = "resources/subscribe"
+	MethodResourcesUnsubscribe   = "resources/unsubscribe"
+	MethodPromptsList            = "prompts/list"
+	MethodPromptsGet             = "prompts/get"
+	MethodToolsList              = "tools/list"
+	MethodToolsCall              = "tools/call"
+	MethodLoggingSetLevel        = "logging/setLevel"
+	MethodCompletionComplete     = "completion/complete"
+)
+
+// Methods of the requests a server sends to a client
+const (
+	MethodRootsList             = "roots/list"
+	MethodSamplingCreateMessage = "sampling/createMessage"
+	MethodElicitationCreate     = "elicitation/create"
+)
+
+// Methods of notifications, sent by either side
+const (
+	MethodNotificationInitialized          = "notifications/initialized"
+	MethodNotificationCancelled            = "notifications/cancelled"
+	MethodNotificationProgress             = "notifications/progress"
+	MethodNotificationMessage              = "notifications/message"
+	MethodNotificationResourceUpdated      = "notifications/resources/updated"
+	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
+	MethodNotificationToolsListChanged     = "notifications/tools/list_changed"
+	MethodNotificationPromptsListChanged   = "notifications/prompts/list_changed"
+	MethodNotificationRootsListChanged     = "notifications/roots/list_changed"
+)
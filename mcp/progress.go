@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// AnyProgressToken associates progress notifications with the request
+// that asked for them. The protocol allows a string or an integer, unlike
+// the generated ProgressToken, which is an integer; the zero value is the
+// integer 0. Tokens are comparable and can key maps.
+type AnyProgressToken struct {
+	str   string
+	num   int64
+	isStr bool
+}
+
+// StringProgressToken returns a string progress token
+func StringProgressToken(s string) AnyProgressToken {
+	return AnyProgressToken{str: s, isStr: true}
+}
+
+// IntProgressToken returns an integer progress token
+func IntProgressToken(n int64) AnyProgressToken {
+	return AnyProgressToken{num: n}
+}
+
+// IsString reports whether the token is a string
+func (t AnyProgressToken) IsString() bool {
+	return t.isStr
+}
+
+// Value returns the token as a string or an int64
+func (t AnyProgressToken) Value() interface{} {
+	if t.isStr {
+		return t.str
+	}
+	return t.num
+}
+
+// String returns the token as text
+func (t AnyProgressToken) String() string {
+	if t.isStr {
+		return t.str
+	}
+	return strconv.FormatInt(t.num, 10)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t AnyProgressToken) MarshalJSON() ([]byte, error) {
+	if t.isStr {
+		return json.Marshal(t.str)
+	}
+	return []byte(strconv.FormatInt(t.num, 10)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *AnyProgressToken) UnmarshalJSON(b []byte) error {
+	token, err := progressTokenOf(b)
+	if err != nil {
+		return err
+	}
+	*t = token
+	return nil
+}
+
+// progressTokenOf decodes a token, which must be a string or an integer
+func progressTokenOf(b []byte) (AnyProgressToken, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return AnyProgressToken{}, err
+		}
+		return StringProgressToken(s), nil
+	}
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return AnyProgressToken{}, fmt.Errorf("progress token %s: must be a string or an integer", b)
+	}
+	return IntProgressToken(n), nil
+}
+
+// ProgressParams are the params of a progress notification. They replace
+// the generated ProgressNotificationParams, which lack message and take
+// integer tokens only.
+type ProgressParams struct {
+	// An optional message describing the current progress.
+	Message string `json:"message,omitempty" yaml:"message,omitempty" mapstructure:"message,omitempty"`
+
+	// The progress thus far. This should increase every time progress is made, even
+	// if the total is unknown.
+	Progress float64 `json:"progress" yaml:"progress" mapstructure:"progress"`
+
+	// The progress token which was given in the initial request, used to associate
+	// this notification with the request that is proceeding.
+	ProgressToken AnyProgressToken `json:"progressToken" yaml:"progressToken" mapstructure:"progressToken"`
+
+	// Total number of items to process (or total progress required), if known.
+	Total *float64 `json:"total,omitempty" yaml:"total,omitempty" mapstructure:"total,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ProgressParams) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["progress"]; raw != nil && !ok {
+		return fmt.Errorf("field progress in ProgressParams: required")
+	}
+	if _, ok := raw["progressToken"]; raw != nil && !ok {
+		return fmt.Errorf("field progressToken in ProgressParams: required")
+	}
+	type Plain ProgressParams
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = ProgressParams(plain)
+	return nil
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnyProgressToken(t *testing.T) {
+	for _, token := range []AnyProgressToken{StringProgressToken("t1"), StringProgressToken("7"), IntProgressToken(7)} {
+		data, err := json.Marshal(token)
+		require.NoError(t, err)
+		var decoded AnyProgressToken
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, token, decoded)
+	}
+	assert.NotEqual(t, StringProgressToken("7"), IntProgressToken(7))
+	assert.Equal(t, "7", IntProgressToken(7).String())
+	assert.Equal(t, int64(7), IntProgressToken(7).Value())
+	assert.True(t, StringProgressToken("t1").IsString())
+
+	var token AnyProgressToken
+	assert.Error(t, json.Unmarshal([]byte(`1.5`), &token))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &token))
+
+	// Decoded _meta holds numbers as float64
+	meta, err := MetaOf(json.RawMessage(`{"_meta": {"progressToken": 42}}`))
+	require.NoError(t, err)
+	token, ok := meta.ProgressToken()
+	require.True(t, ok)
+	assert.Equal(t, IntProgressToken(42), token)
+}
+
+func TestProgressParams(t *testing.T) {
+	total := 10.0
+	params := ProgressParams{
+		ProgressToken: StringProgressToken("t1"),
+		Progress:      5,
+		Total:         &total,
+		Message:       "halfway",
+	}
+	data, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"progressToken": "t1", "progress": 5, "total": 10, "message": "halfway"}`, string(data))
+
+	var decoded ProgressParams
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, params, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"progress": 1}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"progressToken": 1}`), &decoded))
+}
@@ -0,0 +1,50 @@
+package mcp
+
+// PromptOption configures a prompt made by NewPrompt
+type PromptOption func(*Prompt)
+
+// NewPrompt returns a prompt named name with the arguments given in opts
+func NewPrompt(name string, opts ...PromptOption) Prompt {
+	prompt := Prompt{Name: name}
+	for _, opt := range opts {
+		opt(&prompt)
+	}
+	return prompt
+}
+
+// WithPromptDescription describes what the prompt provides
+func WithPromptDescription(description string) PromptOption {
+	return func(p *Prompt) {
+		p.Description = description
+	}
+}
+
+// WithArgument adds an argument to the prompt. Of the property options,
+// Required and Description apply to arguments; the others are ignored.
+func WithArgument(name string, opts ...PropertyOption) PromptOption {
+	return func(p *Prompt) {
+		property := map[string]interface{}{}
+		for _, opt := range opts {
+			opt(property)
+		}
+		argument := PromptArgument{Name: name}
+		argument.Description, _ = property["description"].(string)
+		_, argument.Required = property[requiredKeyword]
+		p.Arguments = append(p.Arguments, argument)
+	}
+}
+
+// NewPromptMessage returns a message of role with content, such as a
+// TextContent or an EmbeddedResource
+func NewPromptMessage(role Role, content interface{}) PromptMessage {
+	return PromptMessage{Role: role, Content: content}
+}
+
+// NewGetPromptResult returns the result of a prompts/get with description
+// and messages
+func NewGetPromptResult(description string, messages ...PromptMessage) *GetPromptResult {
+	if messages == nil {
+		messages = []PromptMessage{}
+	}
+	return &GetPromptResult{Description: description, Messages: messages}
+}
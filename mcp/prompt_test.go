@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrompt(t *testing.T) {
+	prompt := NewPrompt("summarize",
+		WithPromptDescription("Summarize a topic"),
+		WithArgument("topic", Required(), Description("What to summarize")),
+		WithArgument("style"),
+	)
+	assert.Equal(t, Prompt{
+		Name:        "summarize",
+		Description: "Summarize a topic",
+		Arguments: []PromptArgument{
+			{Name: "topic", Description: "What to summarize", Required: true},
+			{Name: "style"},
+		},
+	}, prompt)
+}
+
+func TestNewGetPromptResult(t *testing.T) {
+	result := NewGetPromptResult("A summary",
+		NewPromptMessage(RoleUser, NewTextContent("Summarize Go")),
+		NewPromptMessage(RoleAssistant, NewEmbeddedTextResource("file:///go.md", "text/markdown", "# Go")),
+	)
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "A summary",
+		"messages": [
+			{"role": "user", "content": {"type": "text", "text": "Summarize Go"}},
+			{"role": "assistant", "content": {"type": "resource", "resource": {"uri": "file:///go.md", "mimeType": "text/markdown", "text": "# Go"}}}
+		]
+	}`, string(data))
+
+	var decoded GetPromptResult
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, *result, decoded)
+
+	data, err = json.Marshal(NewGetPromptResult(""))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"messages": []}`, string(data))
+}
@@ -0,0 +1,18 @@
+package mcp
+
+// Reference types accepted by completion/complete
+const (
+	PromptReferenceType   = "ref/prompt"
+	ResourceReferenceType = "ref/resource"
+)
+
+// NewPromptReference refers to a prompt by name in a completion request
+func NewPromptReference(name string) PromptReference {
+	return PromptReference{Type: PromptReferenceType, Name: name}
+}
+
+// NewResourceReference refers to a resource or URI template in a
+// completion request
+func NewResourceReference(uri string) ResourceReference {
+	return ResourceReference{Type: ResourceReferenceType, Uri: uri}
+}
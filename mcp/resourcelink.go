@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A reference to a resource of the server, included in a tool call result
+// in place of its contents. The client reads the resource when it needs it.
+type ResourceLink struct {
+	// Annotations corresponds to the JSON schema field "annotations".
+	Annotations *ResourceAnnotations `json:"annotations,omitempty" yaml:"annotations,omitempty" mapstructure:"annotations,omitempty"`
+
+	// A description of what this resource represents.
+	Description string `json:"description,omitempty" yaml:"description,omitempty" mapstructure:"description,omitempty"`
+
+	// The MIME type of this resource, if known.
+	MimeType string `json:"mimeType,omitempty" yaml:"mimeType,omitempty" mapstructure:"mimeType,omitempty"`
+
+	// A human-readable name for this resource.
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+
+	// Type corresponds to the JSON schema field "type".
+	Type string `json:"type" yaml:"type" mapstructure:"type"`
+
+	// The URI of this resource.
+	Uri string `json:"uri" yaml:"uri" mapstructure:"uri"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ResourceLink) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["name"]; raw != nil && !ok {
+		return fmt.Errorf("field name in ResourceLink: required")
+	}
+	if _, ok := raw["type"]; raw != nil && !ok {
+		return fmt.Errorf("field type in ResourceLink: required")
+	}
+	if _, ok := raw["uri"]; raw != nil && !ok {
+		return fmt.Errorf("field uri in ResourceLink: required")
+	}
+	type Plain ResourceLink
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = ResourceLink(plain)
+	return nil
+}
+
+// NewResourceLink returns content linking to resource
+func NewResourceLink(resource Resource) ResourceLink {
+	return ResourceLink{
+		Type:        ResourceLinkType,
+		Uri:         resource.Uri,
+		Name:        resource.Name,
+		Description: resource.Description,
+		MimeType:    resource.MimeType,
+		Annotations: resource.Annotations,
+	}
+}
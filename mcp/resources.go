@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewTextResourceContents returns the contents of a text resource
+func NewTextResourceContents(uri, mimeType, text string) TextResourceContents {
+	return TextResourceContents{Uri: uri, MimeType: mimeType, Text: text}
+}
+
+// NewBlobResourceContents returns the contents of a binary resource,
+// base64 encoding data
+func NewBlobResourceContents(uri, mimeType string, data []byte) BlobResourceContents {
+	return BlobResourceContents{
+		Uri:      uri,
+		MimeType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// ReadBlobResourceContents returns the contents of a binary resource read
+// from r. The data is encoded as it is read, so only the encoded form is
+// held in memory.
+func ReadBlobResourceContents(uri, mimeType string, r io.Reader) (BlobResourceContents, error) {
+	var blob strings.Builder
+	encoder := base64.NewEncoder(base64.StdEncoding, &blob)
+	if _, err := io.Copy(encoder, r); err != nil {
+		return BlobResourceContents{}, fmt.Errorf("failed to read blob: %w", err)
+	}
+	encoder.Close()
+	return BlobResourceContents{Uri: uri, MimeType: mimeType, Blob: blob.String()}, nil
+}
+
+// Data returns the decoded bytes of the blob
+func (c BlobResourceContents) Data() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(c.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob: %w", err)
+	}
+	return data, nil
+}
+
+// ResourceOption configures a resource made by NewResource
+type ResourceOption func(*Resource)
+
+// NewResource returns the resource at uri named name
+func NewResource(uri, name string, opts ...ResourceOption) Resource {
+	resource := Resource{Uri: uri, Name: name}
+	for _, opt := range opts {
+		opt(&resource)
+	}
+	return resource
+}
+
+// WithResourceDescription describes what the resource represents
+func WithResourceDescription(description string) ResourceOption {
+	return func(r *Resource) {
+		r.Description = description
+	}
+}
+
+// WithMIMEType sets the MIME type of the resource
+func WithMIMEType(mimeType string) ResourceOption {
+	return func(r *Resource) {
+		r.MimeType = mimeType
+	}
+}
+
+// WithAnnotations tells hosts who the resource is meant for and how
+// important it is, with priority clamped between 0 and 1
+func WithAnnotations(audience []Role, priority float64) ResourceOption {
+	return func(r *Resource) {
+		r.Annotations = (*ResourceAnnotations)(NewAnnotations(audience, priority))
+	}
+}
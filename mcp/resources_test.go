@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobResourceContents(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	contents := NewBlobResourceContents("file:///logo.png", "image/png", data)
+	assert.Equal(t, "iVBORwD/", contents.Blob)
+	decoded, err := contents.Data()
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	read, err := ReadBlobResourceContents("file:///logo.png", "image/png", iotest.OneByteReader(bytes.NewReader(data)))
+	require.NoError(t, err)
+	assert.Equal(t, contents, read)
+
+	_, err = ReadBlobResourceContents("file:///logo.png", "", iotest.ErrReader(errors.New("disk failure")))
+	assert.ErrorContains(t, err, "disk failure")
+
+	_, err = BlobResourceContents{Blob: "not base64!"}.Data()
+	assert.Error(t, err)
+}
+
+func TestReadResourceResult_MixedContents(t *testing.T) {
+	result := ReadResourceResult{Contents: []interface{}{
+		NewTextResourceContents("file:///readme.md", "text/markdown", "# Readme"),
+		NewBlobResourceContents("file:///logo.png", "image/png", []byte("png")),
+	}}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var decoded ReadResourceResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, DecodeContents(&decoded))
+	assert.Equal(t, result.Contents, decoded.Contents)
+}
+
+func TestNewResource(t *testing.T) {
+	resource := NewResource("file:///report.txt", "report",
+		WithResourceDescription("Weekly report"),
+		WithMIMEType("text/plain"),
+		WithAnnotations([]Role{RoleUser}, 0.5),
+	)
+	data, err := json.Marshal(resource)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"uri": "file:///report.txt",
+		"name": "report",
+		"description": "Weekly report",
+		"mimeType": "text/plain",
+		"annotations": {"audience": ["user"], "priority": 0.5}
+	}`, string(data))
+
+	var decoded Resource
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, resource, decoded)
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolSchema is a JSON Schema object defining the expected parameters for
+// a tool. Unlike the generated ToolInputSchema, it keeps every keyword:
+// Type, Properties and Required are the keywords tools commonly declare,
+// and any other, such as $defs, additionalProperties or anyOf, is kept in
+// Extra, so that schemas from other SDKs survive a round trip.
+type ToolSchema struct {
+	// Properties corresponds to the JSON schema field "properties".
+	Properties ToolInputSchemaProperties `json:"properties,omitempty" yaml:"properties,omitempty" mapstructure:"properties,omitempty"`
+
+	// Required corresponds to the JSON schema field "required".
+	Required []string `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required,omitempty"`
+
+	// Type corresponds to the JSON schema field "type".
+	Type string `json:"type" yaml:"type" mapstructure:"type"`
+
+	// Extra holds the keywords of the schema besides the ones above
+	Extra map[string]interface{} `json:"-" yaml:",inline" mapstructure:",remain"`
+}
+
+// Map returns the schema as a JSON object, keywords in Extra included
+func (j ToolSchema) Map() map[string]interface{} {
+	schema := make(map[string]interface{}, len(j.Extra)+3)
+	for keyword, value := range j.Extra {
+		schema[keyword] = value
+	}
+	schema["type"] = j.Type
+	if j.Properties != nil {
+		schema["properties"] = map[string]map[string]interface{}(j.Properties)
+	}
+	if j.Required != nil {
+		schema["required"] = j.Required
+	}
+	return schema
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j ToolSchema) MarshalJSON() ([]byte, error) {
+	if len(j.Extra) == 0 {
+		type Plain ToolSchema
+		return json.Marshal(Plain(j))
+	}
+	schema := j.Map()
+	if len(j.Properties) == 0 {
+		delete(schema, "properties")
+	}
+	if len(j.Required) == 0 {
+		delete(schema, "required")
+	}
+	return json.Marshal(schema)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ToolSchema) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["type"]; raw != nil && !ok {
+		return fmt.Errorf("field type in ToolSchema: required")
+	}
+	type Plain ToolSchema
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	for keyword, data := range raw {
+		switch keyword {
+		case "type", "properties", "required":
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		if plain.Extra == nil {
+			plain.Extra = make(map[string]interface{})
+		}
+		plain.Extra[keyword] = value
+	}
+	*j = ToolSchema(plain)
+	return nil
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSchema(t *testing.T) {
+	original := `{
+		"type": "object",
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"properties": {
+			"unit": {"type": "string", "enum": ["c", "f"]},
+			"points": {"type": "array", "items": {"$ref": "#/$defs/point"}}
+		},
+		"required": ["unit"],
+		"additionalProperties": false,
+		"$defs": {"point": {"type": "object", "properties": {"x": {"type": "number"}}}}
+	}`
+
+	var tool ToolDefinition
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "plot", "inputSchema": `+original+`}`), &tool))
+	assert.Equal(t, "object", tool.InputSchema.Type)
+	assert.Equal(t, []string{"unit"}, tool.InputSchema.Required)
+	assert.Equal(t, false, tool.InputSchema.Extra["additionalProperties"])
+	assert.Contains(t, tool.InputSchema.Extra, "$defs")
+
+	data, err := json.Marshal(tool.InputSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, original, string(data))
+
+	// Schemas without other keywords encode as before
+	data, err = json.Marshal(ToolSchema{Type: "object"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object"}`, string(data))
+
+	var schema ToolSchema
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "object"}`), &schema))
+	assert.Equal(t, ToolSchema{Type: "object"}, schema)
+	assert.Error(t, json.Unmarshal([]byte(`{"properties": {}}`), &schema))
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import "slices"
+
+// ToolOption configures a tool made by NewTool
+type ToolOption func(*ToolDefinition)
+
+// PropertyOption configures a property of the input schema of a tool
+type PropertyOption func(property map[string]interface{})
+
+// requiredKeyword marks a property as required until it is added to the
+// schema, where it moves to the required list
+const requiredKeyword = "\x00required"
+
+// NewTool returns a tool named name whose input schema is an object with
+// the properties given in opts
+func NewTool(name string, opts ...ToolOption) ToolDefinition {
+	tool := ToolDefinition{
+		Name: name,
+		InputSchema: ToolSchema{
+			Type:       "object",
+			Properties: ToolInputSchemaProperties{},
+		},
+	}
+	for _, opt := range opts {
+		opt(&tool)
+	}
+	return tool
+}
+
+// WithDescription describes what the tool does
+func WithDescription(description string) ToolOption {
+	return func(t *ToolDefinition) {
+		t.Description = description
+	}
+}
+
+// WithString adds a string property
+func WithString(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "string", opts)
+}
+
+// WithNumber adds a number property
+func WithNumber(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "number", opts)
+}
+
+// WithInteger adds an integer property
+func WithInteger(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "integer", opts)
+}
+
+// WithBoolean adds a boolean property
+func WithBoolean(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "boolean", opts)
+}
+
+// WithArray adds an array property, see Items
+func WithArray(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "array", opts)
+}
+
+// WithObject adds an object property, see Properties
+func WithObject(name string, opts ...PropertyOption) ToolOption {
+	return withProperty(name, "object", opts)
+}
+
+func withProperty(name, kind string, opts []PropertyOption) ToolOption {
+	return func(t *ToolDefinition) {
+		property := map[string]interface{}{"type": kind}
+		for _, opt := range opts {
+			opt(property)
+		}
+		if _, ok := property[requiredKeyword]; ok {
+			delete(property, requiredKeyword)
+			if !slices.Contains(t.InputSchema.Required, name) {
+				t.InputSchema.Required = append(t.InputSchema.Required, name)
+			}
+		} else {
+			// A property redefined without Required is optional again
+			t.InputSchema.Required = slices.DeleteFunc(t.InputSchema.Required, func(required string) bool {
+				return required == name
+			})
+			if len(t.InputSchema.Required) == 0 {
+				t.InputSchema.Required = nil
+			}
+		}
+		if t.InputSchema.Properties == nil {
+			t.InputSchema.Properties = ToolInputSchemaProperties{}
+		}
+		t.InputSchema.Properties[name] = property
+	}
+}
+
+// Required makes a property required
+func Required() PropertyOption {
+	return func(property map[string]interface{}) {
+		property[requiredKeyword] = true
+	}
+}
+
+// Description describes a property
+func Description(description string) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["description"] = description
+	}
+}
+
+// Enum limits a property to values
+func Enum(values ...interface{}) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["enum"] = values
+	}
+}
+
+// DefaultValue sets the value a property takes when it is left out
+func DefaultValue(value interface{}) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["default"] = value
+	}
+}
+
+// Minimum sets the least value of a number or integer property
+func Minimum(limit float64) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["minimum"] = limit
+	}
+}
+
+// Maximum sets the greatest value of a number or integer property
+func Maximum(limit float64) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["maximum"] = limit
+	}
+}
+
+// MinLength sets the least length of a string property
+func MinLength(limit int) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["minLength"] = limit
+	}
+}
+
+// MaxLength sets the greatest length of a string property
+func MaxLength(limit int) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["maxLength"] = limit
+	}
+}
+
+// Items sets the schema of the items of an array property
+func Items(schema map[string]interface{}) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["items"] = schema
+	}
+}
+
+// Properties sets the properties of an object property
+func Properties(properties map[string]interface{}) PropertyOption {
+	return func(property map[string]interface{}) {
+		property["properties"] = properties
+	}
+}
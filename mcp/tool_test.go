@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTool(t *testing.T) {
+	tool := NewTool("convert",
+		WithDescription("Convert a temperature"),
+		WithNumber("value", Required(), Description("Temperature"), Minimum(-273.15)),
+		WithString("unit", Required(), Enum("c", "f"), DefaultValue("c")),
+		WithInteger("precision", Minimum(0), Maximum(10)),
+		WithBoolean("verbose"),
+		WithArray("tags", Items(map[string]interface{}{"type": "string"})),
+		WithString("note", MinLength(1), MaxLength(80)),
+		WithObject("options", Properties(map[string]interface{}{"round": map[string]interface{}{"type": "boolean"}})),
+	)
+
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "convert",
+		"description": "Convert a temperature",
+		"inputSchema": {
+			"type": "object",
+			"properties": {
+				"value": {"type": "number", "description": "Temperature", "minimum": -273.15},
+				"unit": {"type": "string", "enum": ["c", "f"], "default": "c"},
+				"precision": {"type": "integer", "minimum": 0, "maximum": 10},
+				"verbose": {"type": "boolean"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"note": {"type": "string", "minLength": 1, "maxLength": 80},
+				"options": {"type": "object", "properties": {"round": {"type": "boolean"}}}
+			},
+			"required": ["value", "unit"]
+		}
+	}`, string(data))
+
+	// A property added twice is replaced and required once
+	tool = NewTool("echo", WithString("text", Required()), WithString("text", Required(), Description("Text")))
+	assert.Equal(t, []string{"text"}, tool.InputSchema.Required)
+	assert.Equal(t, "Text", tool.InputSchema.Properties["text"]["description"])
+
+	// Redefining it without Required makes it optional
+	tool = NewTool("echo",
+		WithString("text", Required()),
+		WithString("lang", Required()),
+		WithString("text", Description("Text")),
+	)
+	assert.Equal(t, []string{"lang"}, tool.InputSchema.Required)
+	tool = NewTool("echo", WithString("text", Required()), WithString("text"))
+	assert.Nil(t, tool.InputSchema.Required)
+
+	assert.Equal(t, ToolDefinition{Name: "ping", InputSchema: ToolSchema{Type: "object", Properties: ToolInputSchemaProperties{}}}, NewTool("ping"))
+}
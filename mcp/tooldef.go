@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolDefinition is a tool as servers list it. It replaces the generated
+// Tool, whose input schema drops every keyword but type and properties.
+type ToolDefinition struct {
+	// A human-readable description of the tool.
+	Description string `json:"description,omitempty" yaml:"description,omitempty" mapstructure:"description,omitempty"`
+
+	// A JSON Schema object defining the expected parameters for the tool.
+	InputSchema ToolSchema `json:"inputSchema" yaml:"inputSchema" mapstructure:"inputSchema"`
+
+	// The name of the tool.
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ToolDefinition) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["inputSchema"]; raw != nil && !ok {
+		return fmt.Errorf("field inputSchema in ToolDefinition: required")
+	}
+	if _, ok := raw["name"]; raw != nil && !ok {
+		return fmt.Errorf("field name in ToolDefinition: required")
+	}
+	type Plain ToolDefinition
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = ToolDefinition(plain)
+	return nil
+}
+
+// The server's response to a tools/list request from the client. It
+// replaces the generated ListToolsResult, listing ToolDefinition.
+type ListToolDefinitionsResult struct {
+	// This result property is reserved by the protocol to allow clients and servers
+	// to attach additional metadata to their responses.
+	Meta ListToolsResultMeta `json:"_meta,omitempty" yaml:"_meta,omitempty" mapstructure:"_meta,omitempty"`
+
+	// An opaque token representing the pagination position after the last returned
+	// result.
+	// If present, there may be more results available.
+	NextCursor Cursor `json:"nextCursor,omitempty" yaml:"nextCursor,omitempty" mapstructure:"nextCursor,omitempty"`
+
+	// Tools corresponds to the JSON schema field "tools".
+	Tools []ToolDefinition `json:"tools" yaml:"tools" mapstructure:"tools"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ListToolDefinitionsResult) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["tools"]; raw != nil && !ok {
+		return fmt.Errorf("field tools in ListToolDefinitionsResult: required")
+	}
+	type Plain ListToolDefinitionsResult
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = ListToolDefinitionsResult(plain)
+	return nil
+}
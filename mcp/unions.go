@@ -0,0 +1,33 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Unmarshal decodes data into v like json.Unmarshal, then turns the content
+// unions of v into concrete types, such as TextContent, instead of the maps
+// encoding/json leaves in interface{} fields. See DecodeContents for the
+// types it understands.
+func Unmarshal(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	return DecodeContents(v)
+}
+
+// decodeEmbeddedResource turns the resource of embedded content into
+// TextResourceContents or BlobResourceContents, one of which it must be
+func decodeEmbeddedResource(content *EmbeddedResource) error {
+	resource, err := DecodeResourceContents(content.Resource)
+	if err != nil {
+		return err
+	}
+	switch resource.(type) {
+	case TextResourceContents, BlobResourceContents:
+	default:
+		return fmt.Errorf("field resource in EmbeddedResource: must have text or blob")
+	}
+	content.Resource = resource
+	return nil
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip marshals v and unmarshals it into a new value of its type
+func roundTrip[T any](t *testing.T, v T) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var decoded T
+	require.NoError(t, Unmarshal(data, &decoded))
+	return decoded
+}
+
+func TestUnionsRoundTrip(t *testing.T) {
+	contents := []interface{}{
+		NewTextContent("hello"),
+		NewImageContent([]byte("png"), "image/png"),
+		NewAudioContent([]byte("wav"), "audio/wav"),
+		NewEmbeddedTextResource("file:///a.txt", "text/plain", "body"),
+		NewEmbeddedBlobResource("file:///a.bin", "application/octet-stream", []byte("hi")),
+		NewResourceLink(Resource{Uri: "file:///b.txt", Name: "b"}),
+	}
+
+	toolResult := CallToolResult{Content: contents}
+	assert.Equal(t, toolResult, roundTrip(t, toolResult))
+
+	var messages []PromptMessage
+	for _, content := range contents {
+		messages = append(messages, PromptMessage{Role: RoleUser, Content: content})
+	}
+	promptResult := GetPromptResult{Messages: messages}
+	assert.Equal(t, promptResult, roundTrip(t, promptResult))
+
+	readResult := ReadResourceResult{Contents: []interface{}{
+		NewTextResourceContents("file:///a.txt", "text/plain", "body"),
+		NewBlobResourceContents("file:///a.bin", "", []byte("hi")),
+	}}
+	assert.Equal(t, readResult, roundTrip(t, readResult))
+
+	createResult := CreateMessageResult{Role: RoleAssistant, Model: "m", Content: NewTextContent("hi")}
+	assert.Equal(t, createResult, roundTrip(t, createResult))
+
+	sampling := SamplingMessage{Role: RoleUser, Content: NewImageContent([]byte("png"), "image/png")}
+	assert.Equal(t, sampling, roundTrip(t, sampling))
+}
+
+func TestUnionsRequiredFields(t *testing.T) {
+	var toolResult CallToolResult
+	assert.Error(t, Unmarshal([]byte(`{}`), &toolResult))
+	// Content members are validated too
+	assert.Error(t, Unmarshal([]byte(`{"content": [{"type": "image", "data": "aGk="}]}`), &toolResult))
+
+	var embedded EmbeddedResource
+	assert.Error(t, Unmarshal([]byte(`{"type": "resource", "resource": {"uri": "file:///a"}}`), &embedded))
+
+	var message PromptMessage
+	assert.Error(t, Unmarshal([]byte(`{"role": "user"}`), &message))
+}
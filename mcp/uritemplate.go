@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URITemplate is a parsed RFC 6570 URI template, as used by resource
+// templates. It supports simple {var} expressions, which match a single
+// path segment, and reserved {+var} expressions, which match anything
+// including slashes. Several variables may share an expression, as in
+// {x,y}, matching values separated by commas.
+type URITemplate struct {
+	raw       string
+	pattern   *regexp.Regexp
+	variables []string
+}
+
+// templateExpression matches the expressions of a URI template
+var templateExpression = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// templateVariable matches a valid variable name
+var templateVariable = regexp.MustCompile(`^[A-Za-z0-9_.%]+$`)
+
+// ParseURITemplate parses a URI template
+func ParseURITemplate(template string) (*URITemplate, error) {
+	t := &URITemplate{raw: template}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range templateExpression.FindAllStringSubmatchIndex(template, -1) {
+		literal := template[last:loc[0]]
+		if strings.ContainsAny(literal, "{}") {
+			return nil, fmt.Errorf("invalid uri template %q: unbalanced braces", template)
+		}
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		last = loc[1]
+
+		expression := template[loc[2]:loc[3]]
+		value := `([^/?#,]*)`
+		if strings.HasPrefix(expression, "+") {
+			expression = expression[1:]
+			value = `(.*?)`
+		}
+		for i, name := range strings.Split(expression, ",") {
+			if !templateVariable.MatchString(name) {
+				return nil, fmt.Errorf("invalid uri template %q: bad variable %q", template, name)
+			}
+			if i > 0 {
+				pattern.WriteString(",")
+			}
+			pattern.WriteString(value)
+			t.variables = append(t.variables, name)
+		}
+	}
+	if strings.ContainsAny(template[last:], "{}") {
+		return nil, fmt.Errorf("invalid uri template %q: unbalanced braces", template)
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	var err error
+	if t.pattern, err = regexp.Compile(pattern.String()); err != nil {
+		return nil, fmt.Errorf("invalid uri template %q: %w", template, err)
+	}
+	return t, nil
+}
+
+// MustParseURITemplate is like ParseURITemplate but panics on error
+func MustParseURITemplate(template string) *URITemplate {
+	t, err := ParseURITemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template as it was parsed
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the template's variables in order
+func (t *URITemplate) Variables() []string {
+	return append([]string(nil), t.variables...)
+}
+
+// Match reports whether uri is an expansion of the template and returns
+// the decoded value of each variable
+func (t *URITemplate) Match(uri string) (map[string]string, bool) {
+	match := t.pattern.FindStringSubmatch(uri)
+	if match == nil {
+		return nil, false
+	}
+	values := make(map[string]string, len(t.variables))
+	for i, name := range t.variables {
+		value, err := url.PathUnescape(match[i+1])
+		if err != nil {
+			return nil, false
+		}
+		values[name] = value
+	}
+	return values, true
+}
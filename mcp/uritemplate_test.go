@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURITemplate_Match(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		want     map[string]string
+	}{
+		{"users://{id}/profile", "users://42/profile", map[string]string{"id": "42"}},
+		{"users://{id}/profile", "users://a%20b/profile", map[string]string{"id": "a b"}},
+		{"users://{id}/profile", "users://4/2/profile", nil},
+		{"users://{id}/profile", "users://42/settings", nil},
+		{"file:///{+path}", "file:///src/main.go", map[string]string{"path": "src/main.go"}},
+		{"geo://{lat,lon}", "geo://52.1,4.3", map[string]string{"lat": "52.1", "lon": "4.3"}},
+		{"repo://{owner}/{name}.git", "repo://go/mcp.git", map[string]string{"owner": "go", "name": "mcp"}},
+		{"static://readme", "static://readme", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template+" "+tt.uri, func(t *testing.T) {
+			template, err := ParseURITemplate(tt.template)
+			require.NoError(t, err)
+			got, ok := template.Match(tt.uri)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseURITemplate_Invalid(t *testing.T) {
+	for _, template := range []string{"users://{id", "users://id}", "users://{}", "users://{a b}"} {
+		_, err := ParseURITemplate(template)
+		assert.Error(t, err, template)
+	}
+	assert.Equal(t, []string{"lat", "lon"}, MustParseURITemplate("geo://{lat,lon}").Variables())
+}
@@ -0,0 +1,25 @@
+package mcp
+
+// Protocol versions the package knows about
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+
+	// LatestProtocolVersion is the newest protocol version supported
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+// SupportedProtocolVersions lists the supported protocol versions, newest
+// first
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// ProtocolVersionAtLeast reports whether version is min or newer. Versions
+// are dates, so they order as strings do.
+func ProtocolVersionAtLeast(version, min string) bool {
+	return version >= min
+}
@@ -0,0 +1,14 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocolVersionAtLeast(t *testing.T) {
+	assert.True(t, ProtocolVersionAtLeast(ProtocolVersion20250618, ProtocolVersion20250326))
+	assert.True(t, ProtocolVersionAtLeast(ProtocolVersion20241105, ProtocolVersion20241105))
+	assert.False(t, ProtocolVersionAtLeast(ProtocolVersion20241105, ProtocolVersion20250618))
+	assert.Equal(t, LatestProtocolVersion, SupportedProtocolVersions[0])
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// AuditOutcome is how an audited request ended
+type AuditOutcome string
+
+const (
+	// AuditOutcomeSuccess is a request that was answered with a result
+	AuditOutcomeSuccess AuditOutcome = "success"
+	// AuditOutcomeToolError is a tool call whose result reports a failure
+	AuditOutcomeToolError AuditOutcome = "tool_error"
+	// AuditOutcomeError is a request that was answered with an error
+	AuditOutcomeError AuditOutcome = "error"
+)
+
+// AuditRecord describes one tool call, resource read or prompt get
+type AuditRecord struct {
+	Time          time.Time `json:"time"`
+	CorrelationID string    `json:"correlationId"`
+	SessionID     string    `json:"sessionId,omitempty"`
+	// Identity is the identity the transport authenticated, if any
+	Identity any    `json:"identity,omitempty"`
+	Client   string `json:"client,omitempty"`
+	Method   string `json:"method"`
+	// Target is the tool or prompt name, or the resource URI
+	Target string `json:"target"`
+	// ArgumentsHash is the hex SHA-256 of the arguments as JSON, so calls
+	// can be matched without the log holding what was passed
+	ArgumentsHash string        `json:"argumentsHash,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	Outcome       AuditOutcome  `json:"outcome"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// AuditSink receives a record for every tool call, resource read and
+// prompt get once it is answered. Records are written from the goroutine
+// of the request; errors are logged to the logger set with WithLogger.
+type AuditSink interface {
+	Audit(ctx context.Context, record AuditRecord) error
+}
+
+// WithAuditSink sends audit records to sink
+func WithAuditSink(sink AuditSink) ServerOption {
+	return func(s *DefaultServer) {
+		s.auditSink = sink
+	}
+}
+
+// JSONLAuditSink writes audit records as JSON lines
+type JSONLAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLAuditSink writes audit records to w
+func NewJSONLAuditSink(w io.Writer) *JSONLAuditSink {
+	return &JSONLAuditSink{w: w}
+}
+
+// OpenAuditFile appends audit records to the file at path, creating it if
+// needed. Close the sink to close the file.
+func OpenAuditFile(path string) (*JSONLAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return NewJSONLAuditSink(f), nil
+}
+
+func (s *JSONLAuditSink) Audit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer
+func (s *JSONLAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// audit records a request of an audited method to the audit sink
+func (s *DefaultServer) audit(
+	ctx context.Context,
+	request JSONRPCRequest,
+	started time.Time,
+	resp any,
+	err error,
+) {
+	if s.auditSink == nil {
+		return
+	}
+	switch request.Method {
+	case mcp.MethodToolsCall, mcp.MethodResourcesRead, mcp.MethodPromptsGet:
+	default:
+		return
+	}
+
+	var p struct {
+		Name      string         `json:"name"`
+		URI       string         `json:"uri"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	_ = json.Unmarshal(request.Params, &p)
+	record := AuditRecord{
+		Time:          started,
+		CorrelationID: CorrelationIDFromContext(ctx),
+		SessionID:     sessionIDFromContext(ctx),
+		Identity:      IdentityFromContext(ctx),
+		Method:        request.Method,
+		Target:        p.Name,
+		Duration:      time.Since(started),
+		Outcome:       AuditOutcomeSuccess,
+	}
+	if request.Method == mcp.MethodResourcesRead {
+		record.Target = p.URI
+	}
+	if info, ok := ClientInfoFromContext(ctx); ok {
+		record.Client = info.Implementation.Name
+	}
+	if p.Arguments != nil {
+		// Map keys are marshaled in order, so equal arguments hash alike
+		data, _ := json.Marshal(p.Arguments)
+		sum := sha256.Sum256(data)
+		record.ArgumentsHash = hex.EncodeToString(sum[:])
+	}
+	if result, ok := resp.(*mcp.CallToolResult); ok && result.IsError {
+		record.Outcome = AuditOutcomeToolError
+	}
+	if err != nil {
+		record.Outcome = AuditOutcomeError
+		record.Error = err.Error()
+	}
+
+	if err := s.auditSink.Audit(ctx, record); err != nil {
+		s.log(ctx, slog.LevelError, "audit failed", request.Method, slog.String("error", err.Error()))
+	}
+}
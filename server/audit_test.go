@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuditSink(t *testing.T) {
+	var logs bytes.Buffer
+	s := NewDefaultServer("test", "1.0.0", WithAuditSink(NewJSONLAuditSink(&logs))).(*DefaultServer)
+	s.AddTool(mcp.ToolDefinition{Name: "echo", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Arguments["fail"] == true {
+				return nil, errors.New("boom")
+			}
+			return &mcp.CallToolResult{}, nil
+		})
+	s.HandleReadResource(func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+		return nil, errors.New("not found")
+	})
+	s.registerSessionWith("a", (&recorder{}).send, sessionOptions{identity: "alice"})
+	ctx := withSessionID(context.Background(), "a")
+
+	callTool(t, s, ctx, `{"name":"echo","arguments":{"b":1,"a":2}}`)
+	callTool(t, s, ctx, `{"name":"echo","arguments":{"a":2,"b":1}}`)
+	callTool(t, s, ctx, `{"name":"echo","arguments":{"fail":true}}`)
+	s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0", ID: 2, Method: "resources/read",
+		Params: json.RawMessage(`{"uri":"file:///secret"}`),
+	})
+	// Other methods are not audited
+	s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "tools/list"})
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(&logs)
+	for scanner.Scan() {
+		var record AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 4)
+
+	assert.Equal(t, "tools/call", records[0].Method)
+	assert.Equal(t, "echo", records[0].Target)
+	assert.Equal(t, "a", records[0].SessionID)
+	assert.Equal(t, "alice", records[0].Identity)
+	assert.NotEmpty(t, records[0].CorrelationID)
+	assert.Equal(t, AuditOutcomeSuccess, records[0].Outcome)
+	assert.Len(t, records[0].ArgumentsHash, 64)
+	assert.Equal(t, records[0].ArgumentsHash, records[1].ArgumentsHash)
+
+	assert.Equal(t, AuditOutcomeToolError, records[2].Outcome)
+	assert.NotEqual(t, records[0].ArgumentsHash, records[2].ArgumentsHash)
+
+	assert.Equal(t, "resources/read", records[3].Method)
+	assert.Equal(t, "file:///secret", records[3].Target)
+	assert.Equal(t, AuditOutcomeError, records[3].Outcome)
+	assert.Equal(t, "not found", records[3].Error)
+	assert.Empty(t, records[3].ArgumentsHash)
+}
+
+func TestOpenAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for range 2 {
+		sink, err := OpenAuditFile(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Audit(context.Background(), AuditRecord{Method: "tools/call"}))
+		require.NoError(t, sink.Close())
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// Authenticator checks the credentials of a request to an HTTP transport.
+// It is called when a client connects and for every message it posts, and
+// returns the identity of the caller, which handlers get with
+// IdentityFromContext. A request it returns an error for is answered with
+// 401 and a JSON-RPC error carrying the error message.
+type Authenticator interface {
+	Authenticate(r *http.Request) (any, error)
+}
+
+// IdentityComparer is implemented by authenticators whose identities are
+// not comparable with ==, or that count several identities as the same
+// caller. A message posted into a session, or a stream resuming one, must
+// come from the identity that opened it. Identities are compared with ==,
+// or reflect.DeepEqual when not comparable, otherwise.
+type IdentityComparer interface {
+	SameIdentity(a, b any) bool
+}
+
+// errForeignSession answers a request for a session opened by another
+// identity
+var errForeignSession = errors.New("session belongs to another identity")
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(r *http.Request) (any, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (any, error) {
+	return f(r)
+}
+
+// WithAuthenticator requires the clients of the server to authenticate
+func WithAuthenticator(authenticator Authenticator) SSEOption {
+	return func(s *SSEServer) {
+		s.authenticator = authenticator
+	}
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity the authenticator of the
+// transport returned for the request being handled, or otherwise for the
+// session of the request when it connected. It returns nil when the
+// transport does not authenticate.
+func IdentityFromContext(ctx context.Context) any {
+	if identity := ctx.Value(identityKey{}); identity != nil {
+		return identity
+	}
+	if session := clientSessionFromContext(ctx); session != nil {
+		return session.identity
+	}
+	return nil
+}
+
+// Identity returns the identity the client authenticated with when it
+// connected, or nil when the transport does not authenticate
+func (s *Session) Identity() any {
+	return s.client.identity
+}
+
+// authenticate runs the authenticator of the server on r. It answers the
+// request with 401 and returns false when r is not authenticated.
+func (s *SSEServer) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if s.authenticator == nil {
+		return r, true
+	}
+	identity, err := s.authenticator.Authenticate(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: mcp.Unauthorized, Message: err.Error()},
+		})
+		return r, false
+	}
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)), true
+}
+
+// authorizeSession checks that r comes from the identity that opened
+// session. It answers the request with 403 and returns false otherwise.
+func (s *SSEServer) authorizeSession(w http.ResponseWriter, r *http.Request, session *sseSession) bool {
+	if s.authenticator == nil || s.sameIdentity(session.identity, IdentityFromContext(r.Context())) {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error:   &JSONRPCError{Code: mcp.Unauthorized, Message: errForeignSession.Error()},
+	})
+	return false
+}
+
+func (s *SSEServer) sameIdentity(a, b any) bool {
+	if comparer, ok := s.authenticator.(IdentityComparer); ok {
+		return comparer.SameIdentity(a, b)
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
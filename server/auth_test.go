@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// user is an identity carrying the token it was authenticated with
+type user struct {
+	name  string
+	token string
+}
+
+// tokenAuthenticator maps bearer tokens to users, counting every token of
+// a user as the same caller
+type tokenAuthenticator map[string]string
+
+func (a tokenAuthenticator) Authenticate(r *http.Request) (any, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	name, ok := a[token]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return user{name: name, token: token}, nil
+}
+
+func (a tokenAuthenticator) SameIdentity(x, y any) bool {
+	return x.(user).name == y.(user).name
+}
+
+func TestWithAuthenticator(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0", WithHooks(Hooks{
+		OnSessionStart: func(session *Session) {
+			assert.Equal(t, user{name: "alice", token: "secret-a"}, session.Identity())
+		},
+	}))
+	var identity, sessionIdentity any
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "whoami", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			identity = IdentityFromContext(ctx)
+			sessionIdentity = SessionFromContext(ctx).Identity()
+			return &mcp.CallToolResult{}, nil
+		})
+	tokens := tokenAuthenticator{"secret-a": "alice", "secret-a2": "alice", "secret-b": "bob"}
+	_, testServer := NewTestServer(mcpServer, WithAuthenticator(tokens))
+	t.Cleanup(testServer.Close)
+	do := func(method, url, token, body string) *http.Response {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	unauthorized := func(resp *http.Response) {
+		t.Helper()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		var body JSONRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.NotNil(t, body.Error)
+		assert.Equal(t, "invalid token", body.Error.Message)
+	}
+
+	unauthorized(do(http.MethodGet, testServer.URL+"/sse", "", ""))
+
+	stream := do(http.MethodGet, testServer.URL+"/sse", "secret-a", "")
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	reader := bufio.NewReader(stream.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	sessionID := strings.TrimSpace(strings.Split(dataLine, "sessionId=")[1])
+	messageURL := fmt.Sprintf("%s/message?sessionId=%s", testServer.URL, sessionID)
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`
+
+	unauthorized(do(http.MethodPost, messageURL, "wrong", call))
+	assert.Nil(t, identity)
+
+	// Handlers see the identity of the message and of the connection
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, messageURL, "secret-a2", call).StatusCode)
+	messages := make(chan string, 10)
+	go readSSEMessages(reader, messages)
+	nextMessage(t, messages)
+	assert.Equal(t, user{name: "alice", token: "secret-a2"}, identity)
+	assert.Equal(t, user{name: "alice", token: "secret-a"}, sessionIdentity)
+}
+
+func TestWithAuthenticatorForeignSession(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	var calls []any
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "whoami", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls = append(calls, IdentityFromContext(ctx))
+			return &mcp.CallToolResult{}, nil
+		})
+	tokens := map[string]string{"secret-a": "alice", "secret-b": "bob"}
+	_, testServer := NewTestServer(mcpServer, WithAuthenticator(AuthenticatorFunc(
+		func(r *http.Request) (any, error) {
+			name, ok := tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+			if !ok {
+				return nil, errors.New("invalid token")
+			}
+			return name, nil
+		},
+	)))
+	t.Cleanup(testServer.Close)
+
+	do := func(method, url, token string) *http.Response {
+		req, err := http.NewRequest(method, url, strings.NewReader(
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`,
+		))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	open := func(token string) string {
+		stream := do(http.MethodGet, testServer.URL+"/sse", token)
+		require.Equal(t, http.StatusOK, stream.StatusCode)
+		reader := bufio.NewReader(stream.Body)
+		_, _ = reader.ReadString('\n')
+		dataLine, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return strings.TrimSpace(strings.Split(dataLine, "sessionId=")[1])
+	}
+	aliceSession, bobSession := open("secret-a"), open("secret-b")
+
+	// Each user is turned away from the session of the other
+	for _, cross := range []struct{ token, session string }{
+		{token: "secret-b", session: aliceSession},
+		{token: "secret-a", session: bobSession},
+	} {
+		resp := do(http.MethodPost, testServer.URL+"/message?sessionId="+cross.session, cross.token)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var body JSONRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.NotNil(t, body.Error)
+		assert.Equal(t, mcp.Unauthorized, body.Error.Code)
+	}
+	assert.Empty(t, calls)
+
+	resp := do(http.MethodPost, testServer.URL+"/message?sessionId="+aliceSession, "secret-a")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
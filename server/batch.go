@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// splitBatch returns the elements of message when it is a JSON-RPC batch.
+// isBatch is false for a single message.
+func splitBatch(message []byte) (batch []json.RawMessage, isBatch bool, err error) {
+	trimmed := bytes.TrimLeft(message, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false, nil
+	}
+	if err := json.Unmarshal(trimmed, &batch); err != nil {
+		return nil, true, err
+	}
+	return batch, true, nil
+}
+
+// handleBatch dispatches the messages of a batch concurrently with handle
+// and returns what to send back: the responses to the requests of the
+// batch in its order, an invalid request error for an empty batch, or nil
+// when the batch held only notifications and responses. Elements that are
+// not JSON-RPC messages are answered with an invalid request error.
+func handleBatch(
+	batch []json.RawMessage,
+	handle func(request JSONRPCRequest, message json.RawMessage) *JSONRPCResponse,
+) any {
+	if len(batch) == 0 {
+		return invalidRequest(nil)
+	}
+
+	responses := make([]*JSONRPCResponse, len(batch))
+	var wg sync.WaitGroup
+	for i, message := range batch {
+		var request JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			responses[i] = invalidRequest(nil)
+			continue
+		}
+		if request.Method == "" && request.ID == nil {
+			responses[i] = invalidRequest(nil)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := handle(request, message)
+			// Notifications get no response within a batch
+			if request.Method != "" && request.ID != nil {
+				responses[i] = response
+			}
+		}()
+	}
+	wg.Wait()
+
+	var result []JSONRPCResponse
+	for _, response := range responses {
+		if response != nil {
+			result = append(result, *response)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func invalidRequest(id any) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &JSONRPCError{
+			Code:    mcp.InvalidRequest,
+			Message: "Invalid Request",
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServer_Batch(t *testing.T) {
+	_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+	t.Cleanup(testServer.Close)
+	sessionID, messages := connectSSE(t, testServer.URL)
+
+	post := func(body string) (int, string) {
+		t.Helper()
+		resp, err := http.Post(
+			fmt.Sprintf("%s/message?sessionId=%s", testServer.URL, sessionID),
+			"application/json",
+			strings.NewReader(body),
+		)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, strings.TrimSpace(string(data))
+	}
+
+	status, body := post(`[
+		{"jsonrpc": "2.0", "id": 1, "method": "ping"},
+		{"jsonrpc": "2.0", "method": "notifications/initialized"},
+		5,
+		{"jsonrpc": "2.0", "id": 2, "method": "ping"}
+	]`)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Empty(t, body)
+	select {
+	case data := <-messages:
+		assert.JSONEq(t, `[
+			{"jsonrpc": "2.0", "id": 1, "result": {}},
+			{"jsonrpc": "2.0", "id": null, "error": {"code": -32600, "message": "Invalid Request"}},
+			{"jsonrpc": "2.0", "id": 2, "result": {}}
+		]`, data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the batch response event")
+	}
+
+	status, body = post(`[{"jsonrpc": "2.0", "method": "notifications/initialized"}]`)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Empty(t, body)
+
+	status, body = post(`[]`)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Empty(t, body)
+	select {
+	case data := <-messages:
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "id": null, "error": {"code": -32600, "message": "Invalid Request"}}`, data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the batch error event")
+	}
+
+	status, _ = post(`[{"jsonrpc": "2.0", "id": 1,`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	select {
+	case data := <-messages:
+		t.Fatalf("unexpected message %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
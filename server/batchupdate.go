@@ -0,0 +1,80 @@
+package server
+
+import "github.com/huangyul/go-mcp/mcp"
+
+// Batch stages changes to the tools, resources, resource templates and
+// prompts of a server, see DefaultServer.BatchUpdate. Its methods mirror
+// the ones of DefaultServer.
+type Batch struct {
+	s   *DefaultServer
+	reg *Registry
+	err error
+}
+
+// BatchUpdate applies the changes fn stages to the tools, resources,
+// resource templates and prompts of the server at once. Requests see
+// either none or all of them, and clients are told each list changed a
+// single time.
+// Nothing is committed if fn panics or a template fails to parse, whose
+// error is returned. Requests wait for fn to return, and fn must not call
+// the server, which would deadlock.
+func (s *DefaultServer) BatchUpdate(fn func(batch *Batch)) error {
+	return s.registry.batch(func(stage *Registry) error {
+		batch := &Batch{s: s, reg: stage}
+		fn(batch)
+		return batch.err
+	})
+}
+
+// AddTool stages adding or replacing a tool and its handler
+func (b *Batch) AddTool(tool mcp.ToolDefinition, handler ToolHandlerFunc) {
+	b.s.checkToolName(tool.Name)
+	addTool(b.reg, tool, handler)
+}
+
+// RemoveTool stages removing a tool. It reports whether the tool exists.
+func (b *Batch) RemoveTool(name string) bool {
+	return b.reg.remove(registryKey{RegistryKindTool, name})
+}
+
+// AddResource stages adding or replacing a resource and its handler
+func (b *Batch) AddResource(resource mcp.Resource, handler ResourceHandlerFunc) {
+	addResource(b.reg, resource, handler)
+}
+
+// RemoveResource stages removing a resource. It reports whether the
+// resource exists.
+func (b *Batch) RemoveResource(uri string) bool {
+	return b.reg.remove(registryKey{RegistryKindResource, uri})
+}
+
+// AddResourceTemplate stages adding or replacing a resource template and
+// its handler. An error fails the whole batch.
+func (b *Batch) AddResourceTemplate(template mcp.ResourceTemplate, handler ResourceTemplateHandlerFunc) error {
+	entry, err := newResourceTemplate(template, handler)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return err
+	}
+	addResourceTemplate(b.reg, entry)
+	return nil
+}
+
+// RemoveResourceTemplate stages removing a resource template. It reports
+// whether the template exists.
+func (b *Batch) RemoveResourceTemplate(uriTemplate string) bool {
+	return b.reg.remove(registryKey{RegistryKindResourceTemplate, uriTemplate})
+}
+
+// AddPrompt stages adding or replacing a prompt and its handler
+func (b *Batch) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc) {
+	addPrompt(b.reg, prompt, handler)
+}
+
+// RemovePrompt stages removing a prompt. It reports whether the prompt
+// exists.
+func (b *Batch) RemovePrompt(name string) bool {
+	return b.reg.remove(registryKey{RegistryKindPrompt, name})
+}
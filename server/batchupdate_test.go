@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_BatchUpdate(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	text := func(text string) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(text)}}, nil
+		}
+	}
+	read := func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []interface{}{mcp.NewTextResourceContents(request.Params.Uri, "", "body")}}, nil
+	}
+	s.AddTool(mcp.NewTool("old"), text("old"))
+	s.AddResource(mcp.NewResource("file:///old", "old"), read)
+
+	var client recorder
+	s.registerSession("client", client.send)
+	ctx := withSessionID(context.Background(), "client")
+	init := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, init.Error)
+
+	err := s.BatchUpdate(func(batch *Batch) {
+		batch.AddTool(mcp.NewTool("a"), text("a"))
+		batch.AddTool(mcp.NewTool("b"), text("b"))
+		assert.True(t, batch.RemoveTool("old"))
+		batch.AddResource(mcp.NewResource("file:///new", "new"), read)
+		assert.True(t, batch.RemoveResource("file:///old"))
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, toolNames(t, s, ctx))
+	response := callTool(t, s, ctx, `{"name":"b"}`)
+	require.Nil(t, response.Error)
+	assert.Equal(t, "b", response.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+	_, _, ok := s.registeredResource("file:///new")
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []string{
+		mcp.MethodNotificationToolsListChanged,
+		mcp.MethodNotificationResourcesListChanged,
+	}, client.methods())
+
+	// A template that fails to parse fails the whole batch
+	err = s.BatchUpdate(func(batch *Batch) {
+		batch.AddTool(mcp.NewTool("c"), text("c"))
+		assert.Error(t, batch.AddResourceTemplate(mcp.ResourceTemplate{Name: "bad", UriTemplate: "users://{id"}, nil))
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a", "b"}, toolNames(t, s, ctx))
+	assert.Len(t, client.methods(), 2)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// errRequestCancelled is the cancellation cause of requests the client
+// cancelled with notifications/cancelled
+var errRequestCancelled = errors.New("request cancelled by client")
+
+// trackRequest makes the request cancellable by the client until done is
+// called
+func (s *DefaultServer) trackRequest(
+	ctx context.Context,
+	id any,
+) (context.Context, func()) {
+	key, ok := inflightKey(ctx, id)
+	if !ok {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	s.inflight.Store(key, cancel)
+	return ctx, func() {
+		s.inflight.Delete(key)
+		cancel(nil)
+	}
+}
+
+// cancelRequest handles notifications/cancelled. Unknown or finished
+// requests are ignored, as the spec allows the race.
+func (s *DefaultServer) cancelRequest(ctx context.Context, params json.RawMessage) {
+	var p struct {
+		RequestID any `json:"requestId"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+
+	key, ok := inflightKey(ctx, p.RequestID)
+	if !ok {
+		return
+	}
+	if cancel, ok := s.inflight.Load(key); ok {
+		cancel.(context.CancelCauseFunc)(errRequestCancelled)
+	}
+}
+
+// inflightKey scopes a request ID to the session it arrived on
+func inflightKey(ctx context.Context, id any) (string, bool) {
+	if id == nil {
+		return "", false
+	}
+	data, err := json.Marshal(id)
+	if err != nil {
+		return "", false
+	}
+	return sessionIDFromContext(ctx) + "/" + string(data), true
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ErrSamplingBudgetExceeded is returned once a PromptChain has spent its
+// token budget
+var ErrSamplingBudgetExceeded = errors.New("sampling token budget exceeded")
+
+// ErrSamplingLoop is returned when sampling calls nest deeper than
+// maxSamplingDepth or a chain makes more calls than it allows
+var ErrSamplingLoop = errors.New("sampling loop detected")
+
+// maxSamplingDepth bounds how deeply sampling calls may nest, for example
+// a sampling callback that renders another chained prompt
+const maxSamplingDepth = 3
+
+// SamplingFunc asks the client to sample a message from its model
+type SamplingFunc func(
+	ctx context.Context,
+	params mcp.CreateMessageRequestParams,
+) (*mcp.CreateMessageResult, error)
+
+type samplingDepthKey struct{}
+
+// PromptChain lets a prompt handler sample intermediate results, such as a
+// summary of a referenced resource, before it returns its messages. Every
+// call is charged against a token budget and the number of calls and their
+// nesting are bounded, so a chain cannot loop forever. A chain is meant for
+// a single prompt request.
+type PromptChain struct {
+	sample   SamplingFunc
+	maxCalls int
+
+	mu        sync.Mutex
+	remaining int
+	calls     int
+}
+
+// NewPromptChain returns a chain that samples through sample, spending at
+// most tokenBudget tokens over at most maxCalls calls
+func NewPromptChain(sample SamplingFunc, tokenBudget, maxCalls int) *PromptChain {
+	return &PromptChain{
+		sample:    sample,
+		maxCalls:  maxCalls,
+		remaining: tokenBudget,
+	}
+}
+
+// Sample sends params to the client. MaxTokens is capped to what is left of
+// the budget, and a zero MaxTokens asks for all of it.
+func (c *PromptChain) Sample(
+	ctx context.Context,
+	params mcp.CreateMessageRequestParams,
+) (*mcp.CreateMessageResult, error) {
+	depth, _ := ctx.Value(samplingDepthKey{}).(int)
+	if depth >= maxSamplingDepth {
+		return nil, fmt.Errorf("%w: nested %d deep", ErrSamplingLoop, depth)
+	}
+
+	c.mu.Lock()
+	if c.calls >= c.maxCalls {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: more than %d calls", ErrSamplingLoop, c.maxCalls)
+	}
+	if c.remaining <= 0 {
+		c.mu.Unlock()
+		return nil, ErrSamplingBudgetExceeded
+	}
+	if params.MaxTokens <= 0 || params.MaxTokens > c.remaining {
+		params.MaxTokens = c.remaining
+	}
+	// Charge the full request up front so concurrent calls cannot overspend
+	c.remaining -= params.MaxTokens
+	c.calls++
+	c.mu.Unlock()
+
+	ctx = context.WithValue(ctx, samplingDepthKey{}, depth+1)
+	result, err := c.sample(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample: %w", err)
+	}
+	return result, nil
+}
+
+// SampleText samples a reply to a single user message and returns its
+// text, for the common case of summarizing or rewriting some input
+func (c *PromptChain) SampleText(
+	ctx context.Context,
+	systemPrompt string,
+	text string,
+	maxTokens int,
+) (string, error) {
+	result, err := c.Sample(ctx, mcp.CreateMessageRequestParams{
+		SystemPrompt: systemPrompt,
+		MaxTokens:    maxTokens,
+		Messages: []mcp.SamplingMessage{
+			{
+				Role:    mcp.RoleUser,
+				Content: mcp.TextContent{Type: "text", Text: text},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	switch content := result.Content.(type) {
+	case mcp.TextContent:
+		return content.Text, nil
+	case *mcp.TextContent:
+		return content.Text, nil
+	case map[string]interface{}:
+		if text, ok := content["text"].(string); ok && content["type"] == "text" {
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("sampled content is not text")
+}
+
+// Remaining returns the number of tokens left in the budget
+func (c *PromptChain) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remaining
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptChain(t *testing.T) {
+	var requested []int
+	var chain *PromptChain
+	chain = NewPromptChain(func(
+		ctx context.Context,
+		params mcp.CreateMessageRequestParams,
+	) (*mcp.CreateMessageResult, error) {
+		requested = append(requested, params.MaxTokens)
+		if params.SystemPrompt == "recurse" {
+			// A sampling callback that chains again must hit the depth limit
+			return chain.Sample(ctx, params)
+		}
+		return &mcp.CreateMessageResult{
+			Role:    mcp.RoleAssistant,
+			Model:   "test-model",
+			Content: map[string]interface{}{"type": "text", "text": "summary"},
+		}, nil
+	}, 100, 10)
+
+	text, err := chain.SampleText(context.Background(), "Summarize", "a long resource", 30)
+	require.NoError(t, err)
+	assert.Equal(t, "summary", text)
+	assert.Equal(t, 70, chain.Remaining())
+
+	// Requests above the remaining budget are capped
+	_, err = chain.SampleText(context.Background(), "", "more", 500)
+	require.NoError(t, err)
+	assert.Equal(t, []int{30, 70}, requested)
+
+	_, err = chain.SampleText(context.Background(), "", "again", 10)
+	assert.ErrorIs(t, err, ErrSamplingBudgetExceeded)
+
+	chain = NewPromptChain(chain.sample, 1000, 10)
+	_, err = chain.SampleText(context.Background(), "recurse", "loop", 1)
+	assert.ErrorIs(t, err, ErrSamplingLoop)
+
+	chain = NewPromptChain(chain.sample, 1000, 1)
+	_, err = chain.SampleText(context.Background(), "", "once", 1)
+	require.NoError(t, err)
+	_, err = chain.SampleText(context.Background(), "", "twice", 1)
+	assert.ErrorIs(t, err, ErrSamplingLoop)
+}
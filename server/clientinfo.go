@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ClientInfo describes the client of the request being handled, as it
+// introduced itself in initialize
+type ClientInfo struct {
+	// SessionID is the ID the transport gave the session
+	SessionID string
+	// ProtocolVersion is the version agreed on in initialize
+	ProtocolVersion string
+	// Implementation is the name and version of the client
+	Implementation mcp.Implementation
+	// Capabilities are the capabilities the client declared
+	Capabilities mcp.ClientCapabilities
+}
+
+// ClientInfoFromContext returns the client of the request being handled.
+// It reports false outside of a request that arrived through a transport.
+// Before the client initialized only SessionID is set.
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	session := clientSessionFromContext(ctx)
+	if session == nil {
+		return ClientInfo{}, false
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return ClientInfo{
+		SessionID:       session.id,
+		ProtocolVersion: session.protocolVersion,
+		Implementation:  session.clientInfo,
+		Capabilities:    session.clientCapabilities,
+	}, true
+}
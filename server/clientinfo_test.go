@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientInfoFromContext(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	var info ClientInfo
+	var found bool
+	s.AddTool(mcp.ToolDefinition{Name: "whoami", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			info, found = ClientInfoFromContext(ctx)
+			return &mcp.CallToolResult{}, nil
+		})
+
+	s.registerSession("a", (&recorder{}).send)
+	ctx := withSessionID(context.Background(), "a")
+	callTool(t, s, ctx, `{"name":"whoami"}`)
+	require.True(t, found)
+	assert.Equal(t, ClientInfo{SessionID: "a"}, info)
+
+	response := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {"roots": {"listChanged": true}},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, response.Error)
+	callTool(t, s, ctx, `{"name":"whoami"}`)
+	require.True(t, found)
+	assert.Equal(t, "a", info.SessionID)
+	assert.Equal(t, mcp.ProtocolVersion20241105, info.ProtocolVersion)
+	assert.Equal(t, mcp.Implementation{Name: "test-client", Version: "1.0.0"}, info.Implementation)
+	require.NotNil(t, info.Capabilities.Roots)
+	assert.True(t, info.Capabilities.Roots.ListChanged)
+
+	callTool(t, s, context.Background(), `{"name":"whoami"}`)
+	assert.False(t, found)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// maxCompletionValues is the most values a completion result may hold
+const maxCompletionValues = 100
+
+// CompletionProvider returns the candidate values of an argument, given
+// the value typed so far. Candidates may be returned unfiltered; they are
+// ranked against value and those that do not match it are dropped.
+type CompletionProvider func(ctx context.Context, value string) ([]string, error)
+
+// CompleteValues is a CompletionProvider for a fixed set of values
+func CompleteValues(values ...string) CompletionProvider {
+	return func(ctx context.Context, value string) ([]string, error) {
+		return values, nil
+	}
+}
+
+// completionKey identifies an argument of a prompt or a variable of a
+// resource template
+type completionKey struct {
+	refType string
+	ref     string
+	name    string
+}
+
+// completions holds the providers registered on a DefaultServer
+type completions struct {
+	mu        sync.RWMutex
+	providers map[completionKey]CompletionProvider
+}
+
+// AddPromptCompletion completes argument of the prompt named prompt with
+// provider. completion/complete requests it does not cover go to the
+// function set with HandleComplete.
+func (s *DefaultServer) AddPromptCompletion(prompt, argument string, provider CompletionProvider) {
+	s.completions.add(completionKey{mcp.PromptReferenceType, prompt, argument}, provider)
+}
+
+// AddResourceCompletion completes variable of the resource template
+// uriTemplate with provider
+func (s *DefaultServer) AddResourceCompletion(uriTemplate, variable string, provider CompletionProvider) {
+	s.completions.add(completionKey{mcp.ResourceReferenceType, uriTemplate, variable}, provider)
+}
+
+func (c *completions) add(key completionKey, provider CompletionProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.providers == nil {
+		c.providers = make(map[completionKey]CompletionProvider)
+	}
+	c.providers[key] = provider
+}
+
+// complete answers a completion/complete request with the provider
+// registered for its reference and argument. ok is false when there is
+// none.
+func (c *completions) complete(
+	ctx context.Context,
+	ref json.RawMessage,
+	argument mcp.CompleteRequestParamsArgument,
+) (result *mcp.CompleteResult, ok bool, err error) {
+	var r struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+		URI  string `json:"uri"`
+	}
+	if json.Unmarshal(ref, &r) != nil {
+		return nil, false, nil
+	}
+	key := completionKey{refType: r.Type, ref: r.Name, name: argument.Name}
+	if r.Type == mcp.ResourceReferenceType {
+		key.ref = r.URI
+	}
+
+	c.mu.RLock()
+	provider, ok := c.providers[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	candidates, err := provider(ctx, argument.Value)
+	if err != nil {
+		return nil, true, err
+	}
+	values := rankCompletions(candidates, argument.Value)
+	completion := mcp.CompleteResultCompletion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return &mcp.CompleteResult{Completion: completion}, true, nil
+}
+
+// rankCompletions orders candidates by how well they match value: those
+// starting with it, then those starting with it ignoring case, then those
+// containing it ignoring case. Candidates keep their order within a rank
+// and those matching in none of these ways are dropped.
+func rankCompletions(candidates []string, value string) []string {
+	lower := strings.ToLower(value)
+	rank := func(candidate string) int {
+		switch {
+		case strings.HasPrefix(candidate, value):
+			return 0
+		case strings.HasPrefix(strings.ToLower(candidate), lower):
+			return 1
+		case strings.Contains(strings.ToLower(candidate), lower):
+			return 2
+		}
+		return -1
+	}
+
+	values := []string{}
+	for _, candidate := range candidates {
+		if rank(candidate) >= 0 {
+			values = append(values, candidate)
+		}
+	}
+	slices.SortStableFunc(values, func(a, b string) int {
+		return rank(a) - rank(b)
+	})
+	return values
+}
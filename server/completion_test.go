@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func complete(t *testing.T, s MCPServer, params string) *mcp.CompleteResult {
+	t.Helper()
+	response := s.Request(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "completion/complete",
+		Params:  json.RawMessage(params),
+	})
+	require.Nil(t, response.Error)
+	return response.Result.(*mcp.CompleteResult)
+}
+
+func TestDefaultServer_Completion(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	s.AddPromptCompletion("review", "language",
+		CompleteValues("TypeScript", "Python", "typst", "Go", "JavaScript"))
+	s.AddResourceCompletion("users://{id}/profile", "id",
+		func(ctx context.Context, value string) ([]string, error) {
+			var ids []string
+			for i := range 150 {
+				ids = append(ids, fmt.Sprintf("%s%d", value, i))
+			}
+			return ids, nil
+		})
+	s.HandleComplete(func(ctx context.Context, ref interface{}, argument mcp.CompleteRequestParamsArgument) (*mcp.CompleteResult, error) {
+		return &mcp.CompleteResult{Completion: mcp.CompleteResultCompletion{Values: []string{"fallback"}}}, nil
+	})
+
+	result := complete(t, s, `{
+		"ref": {"type": "ref/prompt", "name": "review"},
+		"argument": {"name": "language", "value": "ty"}
+	}`)
+	assert.Equal(t, []string{"typst", "TypeScript"}, result.Completion.Values)
+	assert.False(t, result.Completion.HasMore)
+
+	result = complete(t, s, `{
+		"ref": {"type": "ref/prompt", "name": "review"},
+		"argument": {"name": "language", "value": "script"}
+	}`)
+	assert.Equal(t, []string{"TypeScript", "JavaScript"}, result.Completion.Values)
+
+	result = complete(t, s, `{
+		"ref": {"type": "ref/resource", "uri": "users://{id}/profile"},
+		"argument": {"name": "id", "value": "u"}
+	}`)
+	assert.Len(t, result.Completion.Values, 100)
+	assert.Equal(t, 150, result.Completion.Total)
+	assert.True(t, result.Completion.HasMore)
+
+	result = complete(t, s, `{
+		"ref": {"type": "ref/prompt", "name": "other"},
+		"argument": {"name": "language", "value": "ty"}
+	}`)
+	assert.Equal(t, []string{"fallback"}, result.Completion.Values)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionConfig configures the compression of responses of an
+// SSEServer, negotiated with the Accept-Encoding header of each request
+type CompressionConfig struct {
+	// Level is a compress/flate level. Zero means flate.DefaultCompression.
+	Level int
+	// Streams compresses SSE streams as well as message responses. Each
+	// event is flushed through the compressor, but proxies that buffer
+	// compressed responses can hold events back.
+	Streams bool
+}
+
+// WithCompression compresses responses with gzip or deflate for clients
+// that accept it. Only responses with a body are compressed.
+func WithCompression(config CompressionConfig) SSEOption {
+	return func(s *SSEServer) {
+		if config.Level == 0 {
+			config.Level = flate.DefaultCompression
+		}
+		s.compression = &config
+	}
+}
+
+// compress wraps w to compress the response to r when compression is on
+// and r accepts it. The returned function must be called once the
+// response is complete.
+func (s *SSEServer) compress(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if s.compression == nil {
+		return w, func() {}
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" {
+		return w, func() {}
+	}
+	cw := &compressWriter{ResponseWriter: w, encoding: encoding, level: s.compression.Level}
+	return cw, cw.close
+}
+
+// acceptedEncoding picks gzip or deflate from an Accept-Encoding header
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter compresses the body of successful responses. Flushes go
+// through the compressor so streamed events are not held back.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	level      int
+	compressor interface {
+		io.WriteCloser
+		Flush() error
+	}
+	wroteHeader bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK {
+		w.Header().Set("Content-Encoding", w.encoding)
+		w.Header().Del("Content-Length")
+		if w.encoding == "gzip" {
+			w.compressor, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+		} else {
+			w.compressor, _ = flate.NewWriter(w.ResponseWriter, w.level)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.compressor == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.compressor.Write(p)
+}
+
+// FlushError is used by http.ResponseController
+func (w *compressWriter) FlushError() error {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) close() {
+	if w.compressor != nil {
+		w.compressor.Close()
+	}
+}
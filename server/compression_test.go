@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	assert.Equal(t, "gzip", acceptedEncoding("gzip, deflate"))
+	assert.Equal(t, "deflate", acceptedEncoding("deflate, gzip;q=0"))
+	assert.Equal(t, "gzip", acceptedEncoding("br, GZIP;q=0.5"))
+	assert.Empty(t, acceptedEncoding("br"))
+	assert.Empty(t, acceptedEncoding(""))
+}
+
+func TestSSEServerCompression(t *testing.T) {
+	_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"),
+		WithCompression(CompressionConfig{Streams: true}),
+		WithResponseDelivery(ResponseInBody),
+	)
+	t.Cleanup(testServer.Close)
+	// Keep the transport from decompressing on its own
+	httpClient := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	request, err := http.NewRequest(http.MethodGet, testServer.URL+"/sse", nil)
+	require.NoError(t, err)
+	request.Header.Set("Accept-Encoding", "gzip")
+	resp, err := httpClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	decompressed, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	reader := bufio.NewReader(decompressed)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	endpoint := strings.TrimSpace(strings.TrimPrefix(dataLine, "data: "))
+
+	post := func(acceptEncoding string) *http.Response {
+		request, err := http.NewRequest(http.MethodPost, endpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		require.NoError(t, err)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := httpClient.Do(request)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp = post("gzip")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+	body, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, string(data))
+
+	resp = post("identity")
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	data, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, string(data))
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig controls the CORS headers of both endpoints of an SSEServer
+type CORSConfig struct {
+	// AllowedOrigins lists the origins that may call the server, or "*"
+	// for any origin
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers a preflight may ask for. It
+	// defaults to Content-Type, Authorization and Last-Event-ID.
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and credentials. The
+	// origin of the request is echoed in place of "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight; zero leaves it to
+	// the browser
+	MaxAge time.Duration
+}
+
+// defaultCORS lets any origin call the server without credentials
+var defaultCORS = CORSConfig{AllowedOrigins: []string{"*"}}
+
+// WithCORS sets the CORS headers of the server, replacing the default of
+// allowing any origin. Headers already set by middleware are left alone.
+func WithCORS(config CORSConfig) SSEOption {
+	return func(s *SSEServer) {
+		s.cors = config
+	}
+}
+
+// allowedOrigin returns the value of Access-Control-Allow-Origin for a
+// request from origin, or "" when the origin is not allowed
+func (c CORSConfig) allowedOrigin(origin string) string {
+	if slices.Contains(c.AllowedOrigins, "*") {
+		if c.AllowCredentials && origin != "" {
+			return origin
+		}
+		return "*"
+	}
+	if origin != "" && slices.Contains(c.AllowedOrigins, origin) {
+		return origin
+	}
+	return ""
+}
+
+// applyCORS sets the CORS headers of a response to an endpoint allowing
+// method, and answers preflight requests. It reports whether the request
+// was a preflight and has been answered.
+func (s *SSEServer) applyCORS(w http.ResponseWriter, r *http.Request, method string) bool {
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	header := w.Header()
+	// Leave CORS to middleware that has already decided on it
+	if header.Get("Access-Control-Allow-Origin") != "" {
+		if preflight {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return preflight
+	}
+
+	origin := s.cors.allowedOrigin(r.Header.Get("Origin"))
+	if origin != "*" {
+		header.Add("Vary", "Origin")
+	}
+	if origin == "" {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return preflight
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if s.cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if len(s.cors.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(s.cors.ExposedHeaders, ", "))
+		}
+		return false
+	}
+
+	allowedHeaders := s.cors.AllowedHeaders
+	if allowedHeaders == nil {
+		allowedHeaders = []string{"Content-Type", "Authorization", "Last-Event-ID"}
+	}
+	header.Set("Access-Control-Allow-Methods", method+", "+http.MethodOptions)
+	header.Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+	if s.cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(s.cors.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
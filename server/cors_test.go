@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServerCORS(t *testing.T) {
+	_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithCORS(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedHeaders:   []string{"Content-Type", "X-Tenant"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	defer testServer.Close()
+
+	preflight := func(path, origin, method string) *http.Response {
+		request, err := http.NewRequest(http.MethodOptions, testServer.URL+path, nil)
+		require.NoError(t, err)
+		request.Header.Set("Origin", origin)
+		request.Header.Set("Access-Control-Request-Method", method)
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := preflight("/message", "https://app.example.com", http.MethodPost)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "POST, OPTIONS", resp.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-Tenant", resp.Header.Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", resp.Header.Get("Access-Control-Max-Age"))
+
+	resp = preflight("/sse", "https://app.example.com", http.MethodGet)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "GET, OPTIONS", resp.Header.Get("Access-Control-Allow-Methods"))
+
+	resp = preflight("/message", "https://evil.example.com", http.MethodPost)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// Actual requests carry the headers on both endpoints
+	request, err := http.NewRequest(http.MethodPost, testServer.URL+"/message?sessionId=missing",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	request.Header.Set("Origin", "https://app.example.com")
+	resp, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", resp.Header.Get("Vary"))
+
+	request, err = http.NewRequest(http.MethodGet, testServer.URL+"/sse", nil)
+	require.NoError(t, err)
+	request.Header.Set("Origin", "https://app.example.com")
+	resp, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestSSEServerDefaultCORS(t *testing.T) {
+	_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+	defer testServer.Close()
+
+	resp, err := http.Post(testServer.URL+"/message?sessionId=missing", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// correlationIDMetaKey is the _meta field that carries the correlation ID
+// in requests, responses and error data
+const correlationIDMetaKey = "correlationId"
+
+type correlationIDKey struct{}
+
+// CorrelationIDFromContext returns the correlation ID of the request being
+// handled, or an empty string outside of a request
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+func withCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// requestCorrelationID adopts the correlation ID sent in the request _meta
+// or generates a new one
+func requestCorrelationID(params json.RawMessage) string {
+	var request struct {
+		Meta struct {
+			CorrelationID string `json:"correlationId"`
+		} `json:"_meta"`
+	}
+	if len(params) > 0 && json.Unmarshal(params, &request) == nil &&
+		request.Meta.CorrelationID != "" {
+		return request.Meta.CorrelationID
+	}
+	return uuid.New().String()
+}
+
+type requestMetaKey struct{}
+
+// RequestMetaFromContext returns the _meta of the request or notification
+// being handled, nil when it has none
+func RequestMetaFromContext(ctx context.Context) mcp.Meta {
+	meta, _ := ctx.Value(requestMetaKey{}).(mcp.Meta)
+	return meta
+}
+
+func withRequestMeta(ctx context.Context, meta mcp.Meta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// withResultMeta returns a copy of a result with key set in its _meta.
+// The generated result types all keep _meta in a map typed Meta field;
+// other results are returned as they are. The result itself is left
+// alone, since handlers may share it between requests.
+func withResultMeta(result any, key string, value any) any {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return result
+	}
+	meta := v.Elem().FieldByName("Meta")
+	if !meta.IsValid() || !meta.CanSet() || meta.Kind() != reflect.Map ||
+		meta.Type().Key().Kind() != reflect.String {
+		return result
+	}
+
+	copied := reflect.New(v.Elem().Type())
+	copied.Elem().Set(v.Elem())
+	copiedMeta := reflect.MakeMapWithSize(meta.Type(), meta.Len()+1)
+	for iter := meta.MapRange(); iter.Next(); {
+		copiedMeta.SetMapIndex(iter.Key(), iter.Value())
+	}
+	copiedMeta.SetMapIndex(reflect.ValueOf(key).Convert(meta.Type().Key()), reflect.ValueOf(value))
+	copied.Elem().FieldByName("Meta").Set(copiedMeta)
+	return copied.Interface()
+}
+
+// notifyFunc sends a notification to the client that made the request
+// being handled
+type notifyFunc func(method string, params any) error
+
+type notifierKey struct{}
+
+// withNotifier is used by transports to give handlers a way back to the
+// client for the duration of a request
+func withNotifier(ctx context.Context, notify notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierKey{}, notify)
+}
+
+func notifierFromContext(ctx context.Context) notifyFunc {
+	notify, _ := ctx.Value(notifierKey{}).(notifyFunc)
+	return notify
+}
+
+type sessionIDKey struct{}
+
+// withSessionID is used by transports that multiplex several clients, so
+// request IDs can be told apart
+func withSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+func sessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+type clientSessionKey struct{}
+
+// withClientSession gives handlers a way to make requests to the client
+// of the request being handled
+func withClientSession(ctx context.Context, session *clientSession) context.Context {
+	return context.WithValue(ctx, clientSessionKey{}, session)
+}
+
+func clientSessionFromContext(ctx context.Context) *clientSession {
+	session, _ := ctx.Value(clientSessionKey{}).(*clientSession)
+	return session
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+)
+
+// ResponseDelivery decides how the response to a posted message reaches
+// the client
+type ResponseDelivery int
+
+const (
+	// ResponseOverSSE sends responses as events on the session stream and
+	// answers the post with 202 Accepted, as the SSE transport specifies
+	ResponseOverSSE ResponseDelivery = iota
+	// ResponseInBody answers the post with 200 OK and the response in the
+	// body, leaving the stream to notifications and requests of the server
+	ResponseInBody
+)
+
+// WithResponseDelivery sets how responses are delivered, ResponseOverSSE
+// by default
+func WithResponseDelivery(delivery ResponseDelivery) SSEOption {
+	return func(s *SSEServer) {
+		s.responseDelivery = delivery
+	}
+}
+
+// deliver sends the response to a message posted on w
+func (s *SSEServer) deliver(w http.ResponseWriter, session *sseSession, response *encodedMessage) error {
+	if s.responseDelivery == ResponseInBody {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return response.writeTo(w)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	return session.enqueue(response)
+}
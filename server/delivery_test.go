@@ -0,0 +1,51 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServerResponseDelivery(t *testing.T) {
+	post := func(t *testing.T, serverURL, sessionID string) (*http.Response, string) {
+		resp, err := http.Post(serverURL+"/message?sessionId="+sessionID, "application/json",
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	t.Run("OverSSE", func(t *testing.T) {
+		_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+		t.Cleanup(testServer.Close)
+		sessionID, messages := connectSSE(t, testServer.URL)
+
+		resp, body := post(t, testServer.URL, sessionID)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Empty(t, body)
+		assert.Equal(t, float64(1), nextMessage(t, messages)["id"])
+	})
+
+	t.Run("InBody", func(t *testing.T) {
+		_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithResponseDelivery(ResponseInBody))
+		t.Cleanup(testServer.Close)
+		sessionID, messages := connectSSE(t, testServer.URL)
+
+		resp, body := post(t, testServer.URL, sessionID)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, body)
+		select {
+		case data := <-messages:
+			t.Fatalf("response also sent on the stream: %s", data)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
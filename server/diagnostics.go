@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// DiagnosticsEnv turns the diagnostic tools off when set to "off", so a
+// deployment can drop them without a rebuild
+const DiagnosticsEnv = "MCP_DIAGNOSTICS"
+
+// diagnosticsPrefix marks the vendor tools registered by WithDiagnostics
+const diagnosticsPrefix = "mcp_"
+
+// maxDiagnosticSleep caps mcp_sleep so a stray call cannot hold a worker
+// for long
+const maxDiagnosticSleep = time.Minute
+
+// WithDiagnostics adds the mcp_echo, mcp_server_stats and mcp_sleep tools
+// next to the server's own, for smoke testing deployments and exercising
+// client timeouts and cancellation. Set MCP_DIAGNOSTICS=off to leave them
+// out.
+func WithDiagnostics() ServerOption {
+	return func(s *DefaultServer) {
+		if strings.EqualFold(os.Getenv(DiagnosticsEnv), "off") {
+			return
+		}
+		s.diagnostics = true
+	}
+}
+
+// checkToolName warns about a tool added with a name in the diagnostic
+// tools' namespace. Calls to one named like a diagnostic tool never reach
+// its handler.
+func (s *DefaultServer) checkToolName(name string) {
+	if !s.diagnostics || !strings.HasPrefix(name, diagnosticsPrefix) {
+		return
+	}
+	logger := s.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("tool name uses the prefix reserved for diagnostic tools", "tool", name)
+}
+
+func diagnosticTools() []mcp.ToolDefinition {
+	return []mcp.ToolDefinition{
+		{
+			Name:        "mcp_echo",
+			Description: "Returns its arguments unchanged",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: mcp.ToolInputSchemaProperties{},
+			},
+		},
+		{
+			Name:        "mcp_server_stats",
+			Description: "Reports server uptime and request counters",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: mcp.ToolInputSchemaProperties{},
+			},
+		},
+		{
+			Name:        "mcp_sleep",
+			Description: "Sleeps for duration_ms milliseconds, for testing timeouts",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: mcp.ToolInputSchemaProperties{
+					"duration_ms": map[string]interface{}{
+						"type":        "number",
+						"description": "How long to sleep, at most one minute",
+					},
+				},
+			},
+		},
+	}
+}
+
+// callDiagnosticTool runs a diagnostic tool. ok is false when name is not
+// one of them.
+func (s *DefaultServer) callDiagnosticTool(
+	ctx context.Context,
+	name string,
+	arguments map[string]interface{},
+) (result *mcp.CallToolResult, ok bool, err error) {
+	if !s.diagnostics || !strings.HasPrefix(name, diagnosticsPrefix) {
+		return nil, false, nil
+	}
+
+	switch name {
+	case "mcp_echo":
+		if arguments == nil {
+			arguments = map[string]interface{}{}
+		}
+		result, err = jsonToolResult(arguments)
+		return result, true, err
+
+	case "mcp_server_stats":
+		stats := map[string]interface{}{
+			"name":           s.name,
+			"version":        s.version,
+			"uptime_seconds": time.Since(s.started).Seconds(),
+			"requests":       s.requests.Load(),
+			"failures":       s.failures.Load(),
+		}
+		result, err = jsonToolResult(stats)
+		return result, true, err
+
+	case "mcp_sleep":
+		ms, isNumber := arguments["duration_ms"].(float64)
+		if !isNumber || ms < 0 {
+			return nil, true, fmt.Errorf("duration_ms must be a non-negative number")
+		}
+		duration := min(time.Duration(ms)*time.Millisecond, maxDiagnosticSleep)
+
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		case <-timer.C:
+		}
+		result, err = jsonToolResult(map[string]interface{}{
+			"slept_ms": duration.Milliseconds(),
+		})
+		return result, true, err
+	}
+	return nil, false, nil
+}
+
+func jsonToolResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []interface{}{
+			mcp.TextContent{Type: "text", Text: string(data)},
+		},
+	}, nil
+}
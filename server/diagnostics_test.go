@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callTool(t *testing.T, s MCPServer, ctx context.Context, params string) JSONRPCResponse {
+	t.Helper()
+	return s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(params),
+	})
+}
+
+// toolError returns the text of a tool call that failed with IsError set
+func toolError(t *testing.T, response JSONRPCResponse) string {
+	t.Helper()
+	require.Nil(t, response.Error)
+	result := response.Result.(*mcp.CallToolResult)
+	require.True(t, result.IsError)
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestDefaultServer_Diagnostics(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithDiagnostics())
+	ctx := context.Background()
+
+	list := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.Nil(t, list.Error)
+	var names []string
+	for _, tool := range list.Result.(*mcp.ListToolDefinitionsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"mcp_echo", "mcp_server_stats", "mcp_sleep"}, names)
+
+	echo := callTool(t, s, ctx, `{"name":"mcp_echo","arguments":{"hello":"world"}}`)
+	require.Nil(t, echo.Error)
+	content := echo.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent)
+	assert.JSONEq(t, `{"hello":"world"}`, content.Text)
+
+	stats := callTool(t, s, ctx, `{"name":"mcp_server_stats"}`)
+	require.Nil(t, stats.Error)
+	content = stats.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent)
+	var counters map[string]any
+	require.NoError(t, json.Unmarshal([]byte(content.Text), &counters))
+	assert.Equal(t, float64(3), counters["requests"])
+	assert.Equal(t, "test", counters["name"])
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	sleep := callTool(t, s, timeout, `{"name":"mcp_sleep","arguments":{"duration_ms":5000}}`)
+	require.NotNil(t, sleep.Error)
+	assert.Contains(t, sleep.Error.Message, context.DeadlineExceeded.Error())
+
+	sleep = callTool(t, s, ctx, `{"name":"mcp_sleep","arguments":{"duration_ms":1}}`)
+	require.Nil(t, sleep.Error)
+}
+
+func TestDefaultServer_DiagnosticsReservedPrefix(t *testing.T) {
+	var logs bytes.Buffer
+	s := NewDefaultServer(
+		"test", "1.0.0",
+		WithDiagnostics(),
+		WithSlogLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+
+	s.AddTool(mcp.ToolDefinition{Name: "search"}, handler)
+	assert.Empty(t, logs.String())
+
+	s.AddTool(mcp.ToolDefinition{Name: "mcp_echo"}, handler)
+	assert.Contains(t, logs.String(), "prefix reserved for diagnostic tools")
+	assert.Contains(t, logs.String(), "tool=mcp_echo")
+}
+
+func TestDefaultServer_DiagnosticsDisabled(t *testing.T) {
+	t.Setenv(DiagnosticsEnv, "off")
+	s := NewDefaultServer("test", "1.0.0", WithDiagnostics())
+
+	list := s.Request(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/list",
+	})
+	require.Nil(t, list.Error)
+	assert.Empty(t, list.Result.(*mcp.ListToolDefinitionsResult).Tools)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errDraining is returned for messages that arrive after a transport
+// started shutting down
+var errDraining = errors.New("server is shutting down")
+
+// drain tracks the messages a transport is handling, so that shutting
+// down can stop taking new ones and wait for those still running
+type drain struct {
+	mu       sync.Mutex
+	draining bool
+	running  sync.WaitGroup
+}
+
+// enter registers a message about to be handled. It reports false once
+// draining started, and the message must then be refused.
+func (d *drain) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.running.Add(1)
+	return true
+}
+
+// isDraining reports whether draining started
+func (d *drain) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// leave marks a message entered with enter as handled
+func (d *drain) leave() {
+	d.running.Done()
+}
+
+// wait stops new messages from entering and waits until those running are
+// handled or ctx ends
+func (d *drain) wait(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ErrElicitationNotSupported is returned when the client did not declare
+// the elicitation capability or initialized with a protocol version that
+// predates it
+var ErrElicitationNotSupported = errors.New("client does not support elicitation")
+
+// Elicit asks the user of the client of the request being handled for the
+// input described by the request's schema, and blocks until they accept,
+// decline or cancel, or ctx ends. Accepted content is checked against the
+// schema before it is returned.
+func Elicit(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	session := clientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoClientSession
+	}
+	if !session.supportsElicitation() ||
+		!session.supportsProtocolVersion(mcp.ProtocolVersion20250618) {
+		return nil, ErrElicitationNotSupported
+	}
+
+	params := request.Params
+	if params.RequestedSchema.Type == "" {
+		params.RequestedSchema.Type = "object"
+	}
+	data, err := session.request(ctx, mcp.MethodElicitationCreate, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ElicitResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal elicitation result: %w", err)
+	}
+	switch result.Action {
+	case mcp.ElicitActionAccept:
+		if err := validateElicited(params.RequestedSchema, result.Content); err != nil {
+			return nil, err
+		}
+	case mcp.ElicitActionDecline, mcp.ElicitActionCancel:
+	default:
+		return nil, fmt.Errorf("invalid elicitation action: %s", result.Action)
+	}
+	return &result, nil
+}
+
+// validateElicited checks accepted content against the requested schema
+func validateElicited(schema mcp.ElicitationSchema, content map[string]interface{}) error {
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+	var problems []string
+	validateValue("content", map[string]interface{}{
+		"type":       schema.Type,
+		"properties": schema.Properties,
+		"required":   schema.Required,
+	}, toJSONValue(content), &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid elicited content: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// supportsElicitation reports whether the client declared the elicitation
+// capability
+func (c *clientSession) supportsElicitation() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.elicitation
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElicit(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+
+	var answer string
+	var asked mcp.ElicitRequestParams
+	s.registerSession("a", func(message any) error {
+		request, ok := message.(serverRequest)
+		if !ok || request.Method != "elicitation/create" {
+			return nil
+		}
+		asked = request.Params.(mcp.ElicitRequestParams)
+		go s.handleClientResponse("a", json.RawMessage(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%d,"result":%s}`, request.ID, answer,
+		)))
+		return nil
+	})
+	ctx := withSessionID(context.Background(), "a")
+	initialize := func(capabilities, protocolVersion string) {
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params: json.RawMessage(`{
+				"capabilities": ` + capabilities + `,
+				"clientInfo": {"name": "test-client", "version": "1.0.0"},
+				"protocolVersion": "` + protocolVersion + `"
+			}`),
+		})
+		require.Nil(t, response.Error)
+	}
+
+	var result *mcp.ElicitResult
+	s.AddTool(mcp.ToolDefinition{Name: "book", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var err error
+			result, err = Elicit(ctx, mcp.ElicitRequest{
+				Method: "elicitation/create",
+				Params: mcp.ElicitRequestParams{
+					Message: "How many seats?",
+					RequestedSchema: mcp.ElicitationSchema{
+						Properties: map[string]map[string]interface{}{
+							"seats": {"type": "integer", "minimum": 1},
+						},
+						Required: []string{"seats"},
+					},
+				},
+			})
+			return &mcp.CallToolResult{}, err
+		})
+	// book returns the error the tool failed with
+	book := func() string {
+		result = nil
+		response := callTool(t, s, ctx, `{"name":"book"}`)
+		require.Nil(t, response.Error)
+		if !response.Result.(*mcp.CallToolResult).IsError {
+			return ""
+		}
+		return toolError(t, response)
+	}
+
+	initialize(`{}`, mcp.ProtocolVersion20250618)
+	assert.NotEmpty(t, book())
+	assert.Nil(t, result)
+
+	// The protocol version predates elicitation
+	initialize(`{"elicitation": {}}`, mcp.ProtocolVersion20241105)
+	assert.NotEmpty(t, book())
+
+	initialize(`{"elicitation": {}}`, mcp.ProtocolVersion20250618)
+	answer = `{"action":"accept","content":{"seats":2}}`
+	assert.Empty(t, book())
+	assert.Equal(t, "How many seats?", asked.Message)
+	assert.Equal(t, "object", asked.RequestedSchema.Type)
+	assert.Equal(t, mcp.ElicitActionAccept, result.Action)
+	assert.Equal(t, map[string]interface{}{"seats": float64(2)}, result.Content)
+
+	answer = `{"action":"decline"}`
+	assert.Empty(t, book())
+	assert.Equal(t, mcp.ElicitActionDecline, result.Action)
+
+	answer = `{"action":"cancel"}`
+	assert.Empty(t, book())
+	assert.Equal(t, mcp.ElicitActionCancel, result.Action)
+
+	answer = `{"action":"accept","content":{"seats":0}}`
+	assert.Equal(t, "invalid elicited content: content.seats: must be at least 1", book())
+
+	answer = `{"action":"maybe"}`
+	assert.NotEmpty(t, book())
+
+	_, noSession := Elicit(context.Background(), mcp.ElicitRequest{})
+	assert.ErrorIs(t, noSession, ErrNoClientSession)
+}
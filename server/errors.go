@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// Error is an error a handler returns to choose the JSON-RPC error code,
+// message and data of the response. Errors match each other in errors.Is
+// by code, so a handler may return its own message and still be matched
+// against ErrMethodNotFound or ErrInvalidParams.
+type Error struct {
+	Code    mcp.ErrorCode
+	Message string
+	Data    any
+}
+
+// NewError returns an error answered with code, message and data
+func NewError(code mcp.ErrorCode, message string, data any) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	// ErrMethodNotFound is answered with mcp.MethodNotFound
+	ErrMethodNotFound = NewError(mcp.MethodNotFound, "method not found", nil)
+	// ErrInvalidParams is answered with mcp.InvalidParams
+	ErrInvalidParams = NewError(mcp.InvalidParams, "invalid params", nil)
+)
+
+func methodNotFound(method string) error {
+	return NewError(mcp.MethodNotFound, fmt.Sprintf("method not found: %s", method), nil)
+}
+
+func invalidParams(format string, args ...any) error {
+	return NewError(mcp.InvalidParams, fmt.Sprintf(format, args...), nil)
+}
+
+// errorCode returns the JSON-RPC error code err is answered with and the
+// data it carries besides the correlation ID
+func errorCode(err error) (mcp.ErrorCode, map[string]any) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return mcp.InvalidParams, map[string]any{"problems": validationErr.Problems}
+	}
+	if errors.Is(err, ErrServerBusy) || errors.Is(err, ErrServerShutdown) {
+		return mcp.ServerUnavailable, nil
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return mcp.ServerUnavailable, map[string]any{"retryAfterMs": rateLimitErr.RetryAfter.Milliseconds()}
+	}
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		switch data := rpcErr.Data.(type) {
+		case nil:
+			return rpcErr.Code, nil
+		case map[string]any:
+			return rpcErr.Code, data
+		default:
+			return rpcErr.Code, map[string]any{"details": data}
+		}
+	}
+	return mcp.InternalError, nil
+}
+
+// isProtocolError reports whether a failed tool call is answered with a
+// JSON-RPC error rather than a result with IsError set. Tools report their
+// own failures in the result, so the model can see them; unknown tools,
+// invalid arguments, requests that ran out of time and the like are
+// errors of the request.
+func isProtocolError(err error) bool {
+	var validationErr *ValidationError
+	var rpcErr *Error
+	return errors.As(err, &validationErr) || errors.As(err, &rpcErr) ||
+		errors.Is(err, ErrServerBusy) || errors.Is(err, ErrServerShutdown) ||
+		errors.Is(err, errRequestCancelled) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrSamplingBudgetExceeded) || errors.Is(err, ErrSamplingLoop)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodes(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	s.HandleReadResource(func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+		switch uri {
+		case "file:///missing":
+			return nil, NewError(mcp.ResourceNotFound, "resource not found", map[string]any{"uri": uri})
+		case "file:///bad":
+			return nil, fmt.Errorf("%w: unsupported scheme", ErrInvalidParams)
+		}
+		return nil, errors.New("disk failure")
+	})
+	s.AddTool(mcp.ToolDefinition{Name: "fail", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("tool failed")
+		})
+	ctx := context.Background()
+	read := func(uri string) *JSONRPCError {
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "resources/read",
+			Params:  json.RawMessage(`{"uri":"` + uri + `"}`),
+		})
+		require.NotNil(t, response.Error)
+		return response.Error
+	}
+
+	missing := read("file:///missing")
+	assert.Equal(t, mcp.ResourceNotFound, missing.Code)
+	assert.Equal(t, "resource not found", missing.Message)
+	assert.Equal(t, "file:///missing", missing.Data.(map[string]any)["uri"])
+	assert.NotEmpty(t, missing.Data.(map[string]any)["correlationId"])
+
+	bad := read("file:///bad")
+	assert.Equal(t, mcp.InvalidParams, bad.Code)
+	assert.Equal(t, "invalid params: unsupported scheme", bad.Message)
+
+	assert.Equal(t, mcp.InternalError, read("file:///other").Code)
+	assert.Equal(t, mcp.InvalidParams, read("").Code)
+
+	unknown := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "unknown"})
+	require.NotNil(t, unknown.Error)
+	assert.Equal(t, mcp.MethodNotFound, unknown.Error.Code)
+
+	// Tools report their own failures in the result
+	assert.Equal(t, "tool failed", toolError(t, callTool(t, s, ctx, `{"name":"fail"}`)))
+	invalid := callTool(t, s, ctx, `{"arguments":{}}`)
+	require.NotNil(t, invalid.Error)
+	assert.Equal(t, mcp.InvalidParams, invalid.Error.Code)
+}
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("reading: %w", NewError(-32602, "bad uri", nil))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	assert.NotErrorIs(t, err, ErrMethodNotFound)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/huangyul/go-mcp/internal/framing"
+)
+
+// StdioFraming selects how a stdio server delimits the messages it writes
+type StdioFraming int
+
+const (
+	// FramingAuto writes newline-delimited messages until the client sends
+	// a Content-Length framed message, then switches to match it
+	FramingAuto StdioFraming = iota
+	// FramingNewline always writes newline-delimited JSON
+	FramingNewline
+	// FramingContentLength always writes LSP style Content-Length framing,
+	// for hosts that reuse LSP plumbing and may not speak first
+	FramingContentLength
+)
+
+// WithStdioFraming sets the framing the server writes in, FramingAuto by
+// default. Reading always accepts both framings.
+func WithStdioFraming(framing StdioFraming) StdioOption {
+	return func(s *StdioServer) {
+		s.framing = framing
+		s.contentLength.Store(framing == FramingContentLength)
+	}
+}
+
+// readMessage reads one message in either framing and reports whether it
+// was Content-Length framed. A message larger than max bytes is skipped
+// and errMessageTooLarge returned.
+func readMessage(reader *bufio.Reader, max int) (string, bool, error) {
+	line, err := readLine(reader, max)
+	if err != nil {
+		return line, false, err
+	}
+	first := strings.TrimRight(line, "\r\n")
+	if !framing.HasContentLength(first) {
+		return line, false, nil
+	}
+
+	length, err := framing.ReadHeaders(reader, first)
+	if err != nil {
+		return "", true, err
+	}
+	if max > 0 && length > max {
+		if _, err := reader.Discard(length); err != nil {
+			return "", true, fmt.Errorf("failed to read message body: %w", err)
+		}
+		return "", true, errMessageTooLarge
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return "", true, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return string(body), true, nil
+}
+
+// writeContentLength writes message with a Content-Length header. Resource
+// streams are read whole first, as the length comes before the body.
+func writeContentLength(w io.Writer, message *encodedMessage) error {
+	var body bytes.Buffer
+	if err := message.writeTo(&body); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", body.Len()); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frame(message string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(message), message)
+}
+
+func TestStdioServerFraming(t *testing.T) {
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	response := `{"jsonrpc":"2.0","id":1,"result":{}}`
+
+	listen := func(t *testing.T, input string, opts ...StdioOption) string {
+		t.Helper()
+		var out strings.Builder
+		server := NewStdioServer(NewDefaultServer("test-server", "1.0.0"), opts...)
+		require.NoError(t, server.Listen(context.Background(), strings.NewReader(input), &out))
+		return out.String()
+	}
+
+	t.Run("auto answers in the framing of the client", func(t *testing.T) {
+		assert.Equal(t, response+"\n", listen(t, ping+"\n"))
+		assert.Equal(t, frame(response), listen(t, frame(ping)))
+	})
+
+	t.Run("framed bodies may span lines", func(t *testing.T) {
+		assert.Equal(t, frame(response), listen(t, frame("{\n  \"jsonrpc\": \"2.0\",\n  \"id\": 1,\n  \"method\": \"ping\"\n}")))
+	})
+
+	t.Run("content length always", func(t *testing.T) {
+		assert.Equal(t, frame(response), listen(t, ping+"\n", WithStdioFraming(FramingContentLength)))
+	})
+
+	t.Run("newline always", func(t *testing.T) {
+		assert.Equal(t, response+"\n", listen(t, frame(ping), WithStdioFraming(FramingNewline)))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		out := listen(t, frame(strings.Repeat(" ", 100)+ping)+frame(ping),
+			WithMaxLineLength(64), WithStdioFraming(FramingNewline))
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"code":-32600`)
+		assert.JSONEq(t, response, lines[1])
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		server := NewStdioServer(NewDefaultServer("test-server", "1.0.0"), WithStdioErrorWriter(io.Discard))
+		err := server.Listen(context.Background(), strings.NewReader("Content-Length: x\r\n\r\n"), &strings.Builder{})
+		assert.ErrorContains(t, err, "invalid content length")
+	})
+}
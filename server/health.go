@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// defaultHealthEndpoint is the path of the health endpoint when
+// WithHealthEndpoint is given no path
+const defaultHealthEndpoint = "/healthz"
+
+// serverInfoProvider is implemented by servers that can tell who they are
+type serverInfoProvider interface {
+	serverInfo() mcp.Implementation
+}
+
+func (s *DefaultServer) serverInfo() mcp.Implementation {
+	return mcp.Implementation{Name: s.name, Version: s.version}
+}
+
+// HealthStatus is the body of the health endpoint
+type HealthStatus struct {
+	// Ready is false once the server is shutting down
+	Ready    bool   `json:"ready"`
+	Name     string `json:"name,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Sessions int    `json:"sessions"`
+}
+
+// WithHealthEndpoint serves the health of the server on path, /healthz
+// when empty, for liveness and readiness probes. It answers 200 with a
+// HealthStatus while the server takes messages and 503 once Shutdown
+// started.
+func WithHealthEndpoint(path string) SSEOption {
+	return func(s *SSEServer) {
+		if path == "" {
+			path = defaultHealthEndpoint
+		}
+		s.healthEndpoint = normalizePath(path)
+	}
+}
+
+// HealthPath returns the path of the health endpoint, including the base
+// path, or an empty string when it is not served
+func (s *SSEServer) HealthPath() string {
+	if s.healthEndpoint == "" {
+		return ""
+	}
+	return s.basePath + s.healthEndpoint
+}
+
+// HealthHandler returns the handler of the health endpoint, for routing it
+// separately. It works without WithHealthEndpoint.
+func (s *SSEServer) HealthHandler() http.Handler {
+	return http.HandlerFunc(s.handleHealth)
+}
+
+// Health returns the current health of the server
+func (s *SSEServer) Health() HealthStatus {
+	status := HealthStatus{
+		Ready:    !s.drain.isDraining(),
+		Sessions: len(s.SessionIDs()),
+	}
+	if provider, ok := s.mcpServer.(serverInfoProvider); ok {
+		info := provider.serverInfo()
+		status.Name = info.Name
+		status.Version = info.Version
+	}
+	return status
+}
+
+func (s *SSEServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := s.Health()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(status)
+	}
+}
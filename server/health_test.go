@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServerHealthEndpoint(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithHealthEndpoint(""))
+	t.Cleanup(testServer.Close)
+
+	health := func() (int, HealthStatus) {
+		resp, err := http.Get(testServer.URL + "/healthz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var status HealthStatus
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return resp.StatusCode, status
+	}
+
+	code, status := health()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatus{Ready: true, Name: "test", Version: "1.0.0"}, status)
+
+	connectSSE(t, testServer.URL)
+	_, status = health()
+	assert.Equal(t, 1, status.Sessions)
+
+	require.NoError(t, sseServer.Shutdown(context.Background()))
+	code, status = health()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, status.Ready)
+
+	resp, err := http.Post(testServer.URL+"/healthz", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestSSEServerHealthEndpointPath(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"),
+		WithBasePath("/mcp"), WithHealthEndpoint("ready"))
+	t.Cleanup(testServer.Close)
+	assert.Equal(t, "/mcp/ready", sseServer.HealthPath())
+
+	resp, err := http.Get(testServer.URL + "/mcp/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The endpoint is off by default
+	_, testServer = NewTestServer(NewDefaultServer("test", "1.0.0"))
+	t.Cleanup(testServer.Close)
+	resp, err = http.Get(testServer.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// Hooks observe the messages a DefaultServer handles and the sessions it
+// serves. Every field is optional. Hooks run synchronously on the goroutine
+// handling the message, so they should return quickly.
+type Hooks struct {
+	// OnRequest is called before a request or notification is dispatched
+	OnRequest func(ctx context.Context, request JSONRPCRequest)
+	// OnResponse is called with the response to every request and
+	// notification, whether it succeeded or failed
+	OnResponse func(ctx context.Context, request JSONRPCRequest, response JSONRPCResponse)
+	// OnError is called when a request fails, before OnResponse
+	OnError func(ctx context.Context, request JSONRPCRequest, err error)
+	// OnSessionStart is called when a client connects through a transport
+	OnSessionStart func(session *Session)
+	// OnSessionEnd is called once the client of a session is gone
+	OnSessionEnd func(session *Session)
+	// OnToolCall is called after every tools/call with the outcome of the
+	// tool
+	OnToolCall func(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, err error)
+}
+
+// WithHooks registers hooks on the server. It may be given several times;
+// hooks are called in the order they were registered.
+func WithHooks(hooks Hooks) ServerOption {
+	return func(s *DefaultServer) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// runHooks calls fn with every registered set of hooks
+func (s *DefaultServer) runHooks(fn func(Hooks)) {
+	for _, hooks := range s.hooks {
+		fn(hooks)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	var toolResult *mcp.CallToolResult
+	var toolErr error
+	s := NewDefaultServer("test", "1.0.0",
+		WithHooks(Hooks{
+			OnRequest: func(ctx context.Context, request JSONRPCRequest) {
+				record("request " + request.Method)
+			},
+			OnResponse: func(ctx context.Context, request JSONRPCRequest, response JSONRPCResponse) {
+				record("response " + request.Method)
+			},
+			OnError: func(ctx context.Context, request JSONRPCRequest, err error) {
+				record("error " + err.Error())
+			},
+			OnSessionStart: func(session *Session) {
+				record("start " + session.ID())
+			},
+			OnSessionEnd: func(session *Session) {
+				record("end " + session.ID())
+			},
+			OnToolCall: func(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, err error) {
+				record("tool " + request.Params.Name)
+				toolResult, toolErr = result, err
+			},
+		}),
+		WithHooks(Hooks{
+			OnRequest: func(ctx context.Context, request JSONRPCRequest) {
+				record("second request " + request.Method)
+			},
+		}),
+	).(*DefaultServer)
+	s.AddTool(mcp.ToolDefinition{Name: "fail", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("tool failed")
+		})
+
+	s.registerSession("a", func(message any) error { return nil })
+	ctx := withSessionID(context.Background(), "a")
+	s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "ping"})
+	response := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"fail"}`),
+	})
+	assert.Equal(t, "tool failed", toolError(t, response))
+	response = s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "unknown"})
+	require.NotNil(t, response.Error)
+	s.unregisterSession("a")
+	s.unregisterSession("a")
+
+	assert.Equal(t, []string{
+		"start a",
+		"request ping",
+		"second request ping",
+		"response ping",
+		"request tools/call",
+		"second request tools/call",
+		"tool fail",
+		"response tools/call",
+		"request unknown",
+		"second request unknown",
+		"error method not found: unknown",
+		"response unknown",
+		"end a",
+	}, events)
+	assert.Nil(t, toolResult)
+	assert.EqualError(t, toolErr, "tool failed")
+}
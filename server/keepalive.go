@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeepAliveConfig configures the keepalive frames written on idle SSE
+// streams, so proxies and load balancers do not cut them. Unlike
+// WithSessionPing it expects no answer from the client.
+type KeepAliveConfig struct {
+	// Interval is the time between keepalive frames
+	Interval time.Duration
+	// Event writes "event: ping" frames in place of ": ping" comments, for
+	// intermediaries that only count events as traffic
+	Event bool
+}
+
+// WithKeepAlive writes a keepalive frame on every SSE stream each
+// config.Interval
+func WithKeepAlive(config KeepAliveConfig) SSEOption {
+	return func(s *SSEServer) {
+		s.keepAlive = config
+	}
+}
+
+// frame returns the keepalive frame to write
+func (c KeepAliveConfig) frame() string {
+	if c.Event {
+		return "event: ping\ndata: ping\n\n"
+	}
+	return ": ping\n\n"
+}
+
+// ticker returns a channel receiving a tick for every keepalive frame to
+// write, and a function to stop it. The channel is nil without keepalive.
+func (c KeepAliveConfig) ticker() (<-chan time.Time, func()) {
+	if c.Interval <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(c.Interval)
+	return ticker.C, ticker.Stop
+}
+
+// writeFrame writes a raw frame on the session stream. Only the stream
+// handler calls it.
+func (s *sseSession) writeFrame(frame string) error {
+	if _, err := fmt.Fprint(s.writer, frame); err != nil {
+		return err
+	}
+	return s.flusher.Flush()
+}
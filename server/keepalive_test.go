@@ -0,0 +1,45 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServerKeepAlive(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config KeepAliveConfig
+		lines  []string
+	}{
+		{name: "Comment", config: KeepAliveConfig{Interval: 20 * time.Millisecond}, lines: []string{": ping\n", "\n"}},
+		{name: "Event", config: KeepAliveConfig{Interval: 20 * time.Millisecond, Event: true}, lines: []string{"event: ping\n", "data: ping\n", "\n"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithKeepAlive(tc.config))
+			defer testServer.Close()
+
+			resp, err := http.Get(testServer.URL + "/sse")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			reader := bufio.NewReader(resp.Body)
+
+			// Skip the endpoint event
+			for range 3 {
+				_, err := reader.ReadString('\n')
+				require.NoError(t, err)
+			}
+			for range 2 {
+				for _, expected := range tc.lines {
+					line, err := reader.ReadString('\n')
+					require.NoError(t, err)
+					assert.Equal(t, expected, line)
+				}
+			}
+		})
+	}
+}
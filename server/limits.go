@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultMaxMessageSize bounds the size of a message a client sends when
+// no limit is configured
+const defaultMaxMessageSize = 4 << 20
+
+// errMessageTooLarge is answered with mcp.InvalidRequest in place of a message over
+// the size limit of the transport
+var errMessageTooLarge = errors.New("message too large")
+
+// WithMaxBodySize bounds the size in bytes of a message posted to the
+// server. Larger messages are answered with 413 and a JSON-RPC error
+// without being read in full. It defaults to 4 MiB; zero or less lifts
+// the limit.
+func WithMaxBodySize(size int64) SSEOption {
+	return func(s *SSEServer) {
+		s.maxBodySize = size
+	}
+}
+
+// sessionLimitRetryAfter is when a client turned away by WithMaxSessions
+// is told to try again
+const sessionLimitRetryAfter = 5 * time.Second
+
+// WithMaxSessions bounds how many sessions may be open at once. Clients
+// connecting beyond the limit are answered with 503 and a Retry-After
+// header. Zero or less lifts the limit, the default.
+func WithMaxSessions(n int) SSEOption {
+	return func(s *SSEServer) {
+		s.maxSessions = int64(n)
+	}
+}
+
+// acquireSession takes a place for a new session, or answers 503 and
+// returns false when the server is full
+func (s *SSEServer) acquireSession(w http.ResponseWriter) bool {
+	if s.maxSessions <= 0 {
+		return true
+	}
+	if s.openSessions.Add(1) > s.maxSessions {
+		s.openSessions.Add(-1)
+		w.Header().Set("Retry-After", strconv.Itoa(int(sessionLimitRetryAfter/time.Second)))
+		http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// releaseSession gives back the place of a session that is over
+func (s *SSEServer) releaseSession() {
+	if s.maxSessions > 0 {
+		s.openSessions.Add(-1)
+	}
+}
+
+// defaultStdioConcurrency is how many requests a stdio server handles at
+// once unless set with WithStdioConcurrency
+const defaultStdioConcurrency = 32
+
+// WithStdioConcurrency bounds how many requests a stdio server handles at
+// once. Once n requests are running, reading waits for one of them to
+// finish. Notifications, such as notifications/cancelled, and responses
+// to requests of the server are not bounded. It defaults to 32; zero or
+// less lifts the limit.
+func WithStdioConcurrency(n int) StdioOption {
+	return func(s *StdioServer) {
+		s.concurrency = n
+	}
+}
+
+// WithMaxLineLength bounds the size in bytes of a message line read from
+// stdin. Longer lines are skipped and answered with a JSON-RPC error. It
+// defaults to 4 MiB; zero or less lifts the limit.
+func WithMaxLineLength(length int) StdioOption {
+	return func(s *StdioServer) {
+		s.maxLineLength = length
+	}
+}
+
+// readLine reads a line of at most max bytes, counting the newline. The
+// rest of a longer line is discarded and errMessageTooLarge returned.
+func readLine(reader *bufio.Reader, max int) (string, error) {
+	var line []byte
+	tooLarge := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if max > 0 && len(line)+len(chunk) > max {
+			tooLarge, line = true, nil
+		}
+		if !tooLarge {
+			line = append(line, chunk...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return string(line), err
+		}
+		if tooLarge {
+			return "", errMessageTooLarge
+		}
+		return string(line), nil
+	}
+}
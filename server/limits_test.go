@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLine(t *testing.T) {
+	reader := bufio.NewReaderSize(strings.NewReader(
+		"short\n"+strings.Repeat("x", 100)+"\nnext\ntail"), 16)
+
+	line, err := readLine(reader, 32)
+	require.NoError(t, err)
+	assert.Equal(t, "short\n", line)
+
+	_, err = readLine(reader, 32)
+	assert.ErrorIs(t, err, errMessageTooLarge)
+
+	// Reading resumes after the skipped line
+	line, err = readLine(reader, 32)
+	require.NoError(t, err)
+	assert.Equal(t, "next\n", line)
+
+	line, err = readLine(reader, 32)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "tail", line)
+}
+
+func TestStdioServerMaxLineLength(t *testing.T) {
+	ts := setupTestStdioServer(t, WithMaxLineLength(64))
+	defer ts.cleanup(t)
+
+	resp, err := ts.sendRawRequest(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{"padding":"` +
+		strings.Repeat("x", 100) + `"}}`)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcp.InvalidRequest, resp.Error.Code)
+	assert.Equal(t, "message too large", resp.Error.Message)
+
+	resp, err = ts.sendRawRequest(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Error)
+}
+
+func TestSSEServerMaxBodySize(t *testing.T) {
+	_, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithMaxBodySize(64))
+	t.Cleanup(testServer.Close)
+	sessionID, messages := connectSSE(t, testServer.URL)
+	post := func(body string) *http.Response {
+		resp, err := http.Post(
+			fmt.Sprintf("%s/message?sessionId=%s", testServer.URL, sessionID),
+			"application/json",
+			strings.NewReader(body),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{"padding":"` +
+		strings.Repeat("x", 100) + `"}}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	var body JSONRPCResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.NotNil(t, body.Error)
+	assert.Equal(t, mcp.InvalidRequest, body.Error.Code)
+
+	resp = post(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, float64(2), nextMessage(t, messages)["id"])
+}
+
+func TestSSEServerMaxSessions(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"), WithMaxSessions(1))
+	t.Cleanup(testServer.Close)
+
+	first := openSSEStream(t, testServer.URL+"/sse", nil)
+	first.next(t)
+
+	resp, err := http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("Retry-After"))
+
+	// The place of a closed session is free again
+	first.cancel()
+	require.Eventually(t, func() bool {
+		return len(sseServer.SessionIDs()) == 0
+	}, time.Second, 10*time.Millisecond)
+	second := openSSEStream(t, testServer.URL+"/sse", nil)
+	assert.Contains(t, second.next(t)["data"], "sessionId=")
+}
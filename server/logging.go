@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// loggingLevels lists the logging levels from least to most severe
+var loggingLevels = []mcp.LoggingLevel{
+	mcp.LoggingLevelDebug,
+	mcp.LoggingLevelInfo,
+	mcp.LoggingLevelNotice,
+	mcp.LoggingLevelWarning,
+	mcp.LoggingLevelError,
+	mcp.LoggingLevelCritical,
+	mcp.LoggingLevelAlert,
+	mcp.LoggingLevelEmergency,
+}
+
+// severity ranks a logging level, it is -1 for unknown levels
+func severity(level mcp.LoggingLevel) int {
+	return slices.Index(loggingLevels, level)
+}
+
+// Log sends a notifications/message log entry to the client of the request
+// being handled, unless the client asked with logging/setLevel for more
+// severe entries only. Until it does every entry is sent. logger may be
+// empty.
+func Log(ctx context.Context, level mcp.LoggingLevel, logger string, data any) error {
+	if severity(level) < 0 {
+		return fmt.Errorf("invalid logging level: %s", level)
+	}
+	session := clientSessionFromContext(ctx)
+	if session == nil {
+		return ErrNoClientSession
+	}
+	return session.log(level, logger, data)
+}
+
+// Log sends a log entry to the client of the session outside of a request,
+// filtered by the level the client set like Log
+func (s *Session) Log(level mcp.LoggingLevel, logger string, data any) error {
+	if severity(level) < 0 {
+		return fmt.Errorf("invalid logging level: %s", level)
+	}
+	return s.client.log(level, logger, data)
+}
+
+// LogLevel returns the least severe level the client of the session wants
+// log entries for
+func (s *Session) LogLevel() mcp.LoggingLevel {
+	return s.client.minLogLevel()
+}
+
+// BroadcastLog sends a log entry to every connected client that asked for
+// entries of its level. It returns the errors of sessions that could not
+// be notified.
+func (s *DefaultServer) BroadcastLog(level mcp.LoggingLevel, logger string, data any) error {
+	if severity(level) < 0 {
+		return fmt.Errorf("invalid logging level: %s", level)
+	}
+	s.sessions.mu.RLock()
+	sessions := make(map[string]*clientSession, len(s.sessions.sessions))
+	maps.Copy(sessions, s.sessions.sessions)
+	s.sessions.mu.RUnlock()
+
+	var errs []error
+	for id, session := range sessions {
+		if err := session.log(level, logger, data); err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// log sends a log entry unless the client asked for more severe entries
+func (c *clientSession) log(level mcp.LoggingLevel, logger string, data any) error {
+	if severity(level) < severity(c.minLogLevel()) {
+		return nil
+	}
+	return c.notify(mcp.MethodNotificationMessage, mcp.LoggingMessageNotificationParams{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	})
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "work", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := Log(ctx, mcp.LoggingLevelDebug, "worker", "starting"); err != nil {
+				return nil, err
+			}
+			if err := Log(ctx, mcp.LoggingLevelError, "worker", map[string]any{"failed": 1}); err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{}, nil
+		})
+
+	sessionID, messages := connectSSE(t, testServer.URL)
+	callWork := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"work"}}`
+
+	// Everything is sent until the client sets a level
+	postMessage(t, testServer.URL, sessionID, callWork)
+	entry := nextMessage(t, messages)
+	assert.Equal(t, "notifications/message", entry["method"])
+	assert.Equal(t, map[string]any{"level": "debug", "logger": "worker", "data": "starting"}, entry["params"])
+	assert.Equal(t, "error", nextMessage(t, messages)["params"].(map[string]any)["level"])
+	assert.Equal(t, float64(2), nextMessage(t, messages)["id"])
+
+	postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","id":3,"method":"logging/setLevel","params":{"level":"warning"}}`)
+	nextMessage(t, messages)
+	postMessage(t, testServer.URL, sessionID, callWork)
+	entry = nextMessage(t, messages)
+	assert.Equal(t, map[string]any{"level": "error", "logger": "worker", "data": map[string]any{"failed": float64(1)}}, entry["params"])
+	assert.Equal(t, float64(2), nextMessage(t, messages)["id"])
+
+	// The level is per session
+	otherID, otherMessages := connectSSE(t, testServer.URL)
+	postMessage(t, testServer.URL, otherID, callWork)
+	assert.Equal(t, "debug", nextMessage(t, otherMessages)["params"].(map[string]any)["level"])
+
+	select {
+	case data := <-messages:
+		t.Fatalf("unexpected message %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.ErrorIs(t, Log(context.Background(), mcp.LoggingLevelInfo, "", "x"), ErrNoClientSession)
+	assert.Error(t, Log(context.Background(), "verbose", "", "x"))
+}
+
+func TestBroadcastLog(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	verbose, verboseMessages := connectSSE(t, testServer.URL)
+	quiet, quietMessages := connectSSE(t, testServer.URL)
+	postMessage(t, testServer.URL, quiet, `{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"error"}}`)
+	nextMessage(t, quietMessages)
+
+	session, ok := mcpServer.Session(quiet)
+	require.True(t, ok)
+	assert.Equal(t, mcp.LoggingLevelError, session.LogLevel())
+
+	require.NoError(t, mcpServer.BroadcastLog(mcp.LoggingLevelInfo, "server", "ready"))
+	require.NoError(t, mcpServer.BroadcastLog(mcp.LoggingLevelError, "server", "failed"))
+	assert.Equal(t, "ready", nextMessage(t, verboseMessages)["params"].(map[string]any)["data"])
+	assert.Equal(t, "failed", nextMessage(t, verboseMessages)["params"].(map[string]any)["data"])
+	assert.Equal(t, "failed", nextMessage(t, quietMessages)["params"].(map[string]any)["data"])
+
+	// Sessions log outside of requests with their own level
+	other, ok := mcpServer.Session(verbose)
+	require.True(t, ok)
+	require.NoError(t, other.Log(mcp.LoggingLevelDebug, "", "detail"))
+	require.NoError(t, session.Log(mcp.LoggingLevelDebug, "", "detail"))
+	assert.Equal(t, "detail", nextMessage(t, verboseMessages)["params"].(map[string]any)["data"])
+	select {
+	case data := <-quietMessages:
+		t.Fatalf("unexpected message %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Error(t, mcpServer.BroadcastLog("verbose", "", "x"))
+}
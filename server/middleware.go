@@ -0,0 +1,46 @@
+package server
+
+// ToolMiddleware wraps the handler of a tool, to run code before and after
+// it or to call it not at all, like HTTP middleware does
+type ToolMiddleware func(next ToolHandlerFunc) ToolHandlerFunc
+
+type toolMiddleware struct {
+	middleware ToolMiddleware
+	// tools the middleware applies to, all of them when empty
+	tools map[string]bool
+}
+
+// UseToolMiddleware wraps the tools named in tools with middleware, or
+// every tool when none are named. This covers tools added with AddTool or
+// to a session, diagnostic tools and the function set with HandleCallTool.
+// Middleware used first runs first. It may be added while serving and
+// applies from the next call on.
+func (s *DefaultServer) UseToolMiddleware(middleware ToolMiddleware, tools ...string) {
+	entry := toolMiddleware{middleware: middleware}
+	if len(tools) > 0 {
+		entry.tools = make(map[string]bool, len(tools))
+		for _, name := range tools {
+			entry.tools[name] = true
+		}
+	}
+
+	s.middlewareMu.Lock()
+	defer s.middlewareMu.Unlock()
+	// Copy on write, calls iterate over the middleware without the lock
+	s.toolMiddleware = append(s.toolMiddleware[:len(s.toolMiddleware):len(s.toolMiddleware)], entry)
+}
+
+// wrapTool wraps the handler of the tool name with the middleware that
+// applies to it
+func (s *DefaultServer) wrapTool(name string, handler ToolHandlerFunc) ToolHandlerFunc {
+	s.middlewareMu.RLock()
+	middleware := s.toolMiddleware
+	s.middlewareMu.RUnlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if m := middleware[i]; m.tools == nil || m.tools[name] {
+			handler = m.middleware(handler)
+		}
+	}
+	return handler
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_UseToolMiddleware(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	ctx := context.Background()
+
+	var calls []string
+	echo := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "tool "+request.Params.Name)
+		return &mcp.CallToolResult{
+			Content: []interface{}{mcp.TextContent{Type: "text", Text: request.Params.Name}},
+		}, nil
+	}
+	s.AddTool(mcp.ToolDefinition{Name: "public", InputSchema: mcp.ToolSchema{Type: "object"}}, echo)
+	s.AddTool(mcp.ToolDefinition{Name: "admin", InputSchema: mcp.ToolSchema{Type: "object"}}, echo)
+	s.HandleCallTool(func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+		calls = append(calls, "fallback "+name)
+		return &mcp.CallToolResult{}, nil
+	})
+
+	trace := func(label string) ToolMiddleware {
+		return func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, label+" before")
+				result, err := next(ctx, request)
+				calls = append(calls, label+" after")
+				return result, err
+			}
+		}
+	}
+	s.UseToolMiddleware(trace("outer"))
+	s.UseToolMiddleware(trace("inner"))
+	s.UseToolMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("forbidden")
+		}
+	}, "admin")
+
+	public := callTool(t, s, ctx, `{"name":"public"}`)
+	require.Nil(t, public.Error)
+	assert.Equal(t, []string{"outer before", "inner before", "tool public", "inner after", "outer after"}, calls)
+
+	calls = nil
+	admin := callTool(t, s, ctx, `{"name":"admin"}`)
+	assert.Equal(t, "forbidden", toolError(t, admin))
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, calls)
+
+	calls = nil
+	other := callTool(t, s, ctx, `{"name":"other"}`)
+	require.Nil(t, other.Error)
+	assert.Equal(t, []string{"outer before", "inner before", "fallback other", "inner after", "outer after"}, calls)
+}
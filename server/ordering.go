@@ -0,0 +1,51 @@
+package server
+
+import "context"
+
+// sessionSequencer is implemented by servers that may require the requests
+// of a session to be handled one at a time. Transports that read the
+// messages of a session in order keep that order for such sessions.
+type sessionSequencer interface {
+	sequential(sessionID string) bool
+}
+
+// WithSequentialSessions handles the requests of every session one at a
+// time instead of concurrently. Transports that read a session's messages
+// in order, like stdio, handle its requests in that order. Notifications,
+// such as notifications/cancelled, are still handled right away. Sessions
+// can also opt in on their own with Session.SetSequential.
+func WithSequentialSessions() ServerOption {
+	return func(s *DefaultServer) {
+		s.sequentialSessions = true
+	}
+}
+
+// SetSequential sets whether the requests of the session are handled one
+// at a time, in the order the transport read them
+func (s *Session) SetSequential(sequential bool) {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	s.client.sequential = sequential
+}
+
+func (s *DefaultServer) sequential(sessionID string) bool {
+	session, ok := s.session(sessionID)
+	return ok && session.isSequential()
+}
+
+func (c *clientSession) isSequential() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sequential
+}
+
+// inOrder holds the turn of the session of the request until the returned
+// function is called, when the session is sequential
+func inOrder(ctx context.Context, request JSONRPCRequest) func() {
+	session := clientSessionFromContext(ctx)
+	if session == nil || request.ID == nil || !session.isSequential() {
+		return func() {}
+	}
+	session.turn.Lock()
+	return session.turn.Unlock
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequentialSessions(t *testing.T) {
+	run := func(t *testing.T, s *DefaultServer, sequential func(*Session)) (pingDone bool) {
+		t.Helper()
+		started, release := make(chan struct{}), make(chan struct{})
+		s.AddTool(mcp.ToolDefinition{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}},
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				close(started)
+				<-release
+				return &mcp.CallToolResult{}, nil
+			})
+		s.registerSession("a", (&recorder{}).send)
+		if sequential != nil {
+			session, ok := s.Session("a")
+			require.True(t, ok)
+			sequential(session)
+		}
+		ctx := withSessionID(context.Background(), "a")
+
+		go s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0", ID: 1, Method: "tools/call",
+			Params: json.RawMessage(`{"name":"slow"}`),
+		})
+		<-started
+		pinged := make(chan struct{})
+		go func() {
+			s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "ping"})
+			close(pinged)
+		}()
+		defer close(release)
+		select {
+		case <-pinged:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+
+	t.Run("Concurrent", func(t *testing.T) {
+		s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+		assert.True(t, run(t, s, nil))
+	})
+
+	t.Run("AllSessions", func(t *testing.T) {
+		s := NewDefaultServer("test", "1.0.0", WithSequentialSessions()).(*DefaultServer)
+		assert.False(t, run(t, s, nil))
+	})
+
+	t.Run("OptIn", func(t *testing.T) {
+		s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+		assert.False(t, run(t, s, func(session *Session) { session.SetSequential(true) }))
+	})
+
+	t.Run("OptOut", func(t *testing.T) {
+		s := NewDefaultServer("test", "1.0.0", WithSequentialSessions()).(*DefaultServer)
+		assert.True(t, run(t, s, func(session *Session) { session.SetSequential(false) }))
+	})
+}
+
+func TestIsRequest(t *testing.T) {
+	assert.True(t, isRequest(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	assert.True(t, isRequest(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`))
+	assert.False(t, isRequest(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`))
+	assert.False(t, isRequest(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	assert.False(t, isRequest(`not json`))
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// WithPageSize makes tools/list, resources/list, resources/templates/list
+// and prompts/list return the tools, resources, templates and prompts
+// registered on the server at most size at a time, continuing with a
+// nextCursor. By default they are returned all at once.
+func WithPageSize(size int) ServerOption {
+	return func(s *DefaultServer) {
+		s.pageSize = size
+	}
+}
+
+// ownCursorPrefix starts the cursors of the pages of the server's own
+// items, so that they never match the cursors of a list handler, such as
+// those of Paginate
+const ownCursorPrefix = "registry:"
+
+// Paginate returns the page of items that cursor points at and the cursor
+// of the next page, empty on the last page. An empty cursor is the first
+// page and a pageSize of 0 or less puts all items on it. It is meant for
+// list handlers that page through their own catalogs.
+func Paginate[T any](items []T, cursor mcp.Cursor, pageSize int) (page []T, nextCursor mcp.Cursor, err error) {
+	offset := 0
+	if cursor != "" {
+		state, err := mcp.DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %s", err, cursor)
+		}
+		offset = state.Offset
+	}
+	page, next := paginate(items, offset, pageSize)
+	if next > 0 {
+		nextCursor = mcp.EncodeCursor(mcp.CursorState{Offset: next})
+	}
+	return page, nextCursor, nil
+}
+
+// paginate returns the page of items at offset and the offset of the next
+// page, 0 on the last page
+func paginate[T any](items []T, offset, pageSize int) ([]T, int) {
+	offset = min(offset, len(items))
+	if pageSize <= 0 || offset+pageSize >= len(items) {
+		return items[offset:], 0
+	}
+	return items[offset : offset+pageSize], offset + pageSize
+}
+
+// ownPage returns the page of own items when cursor is one of the
+// server's, which means the list handler has already been paged through.
+// ok is false for any other cursor.
+func ownPage[T any](cursor mcp.Cursor, own []T, pageSize int) (page []T, nextCursor mcp.Cursor, ok bool, err error) {
+	rest, isOwn := strings.CutPrefix(string(cursor), ownCursorPrefix)
+	if !isOwn {
+		return nil, "", false, nil
+	}
+	state, err := mcp.DecodeCursor(mcp.Cursor(rest))
+	if err != nil {
+		return nil, "", true, invalidParams("%v: %s", err, cursor)
+	}
+	page, nextCursor = ownPageAt(own, state.Offset, pageSize)
+	return page, nextCursor, true, nil
+}
+
+// ownPageAt returns the page of own items at offset, the first of which
+// follows the last page of the list handler
+func ownPageAt[T any](own []T, offset, pageSize int) ([]T, mcp.Cursor) {
+	page, next := paginate(own, offset, pageSize)
+	if next == 0 {
+		return page, ""
+	}
+	return page, ownCursorPrefix + mcp.EncodeCursor(mcp.CursorState{Offset: next})
+}
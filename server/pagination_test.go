@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	page, next, err := Paginate(items, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, page)
+	require.NotEmpty(t, next)
+
+	page, next, err = Paginate(items, next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, page)
+
+	page, next, err = Paginate(items, next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, page)
+	assert.Empty(t, next)
+
+	page, next, err = Paginate(items, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, items, page)
+	assert.Empty(t, next)
+
+	_, _, err = Paginate(items, "not a cursor", 2)
+	assert.ErrorIs(t, err, mcp.ErrInvalidCursor)
+}
+
+func TestDefaultServer_PageSize(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithPageSize(2))
+	ctx := context.Background()
+
+	// The handler pages through its own tools with its own cursors
+	s.HandleListTools(func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolDefinitionsResult, error) {
+		if cursor == "" {
+			return &mcp.ListToolDefinitionsResult{
+				Tools:      []mcp.ToolDefinition{{Name: "handler-1"}},
+				NextCursor: "handler",
+			}, nil
+		}
+		require.Equal(t, mcp.Cursor("handler"), cursor)
+		return &mcp.ListToolDefinitionsResult{Tools: []mcp.ToolDefinition{{Name: "handler-2"}}}, nil
+	})
+	for i := range 5 {
+		s.AddTool(mcp.ToolDefinition{
+			Name:        fmt.Sprintf("tool-%d", i),
+			InputSchema: mcp.ToolSchema{Type: "object"},
+		}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+	}
+
+	var pages [][]string
+	var cursor mcp.Cursor
+	for id := 1; ; id++ {
+		params, _ := json.Marshal(mcp.ListToolsRequestParams{Cursor: string(cursor)})
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      id,
+			Method:  "tools/list",
+			Params:  params,
+		})
+		require.Nil(t, response.Error)
+		result := response.Result.(*mcp.ListToolDefinitionsResult)
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		pages = append(pages, names)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	assert.Equal(t, [][]string{
+		{"handler-1"},
+		{"handler-2", "tool-0", "tool-1"},
+		{"tool-2", "tool-3"},
+		{"tool-4"},
+	}, pages)
+}
+
+func TestDefaultServer_PageSizeWithPaginate(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithPageSize(2))
+	ctx := context.Background()
+
+	// The handler pages through its own prompts with Paginate, whose
+	// cursors must reach it rather than be taken for the server's
+	catalog := []mcp.Prompt{{Name: "handler-1"}, {Name: "handler-2"}, {Name: "handler-3"}}
+	s.HandleListPrompts(func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListPromptsResult, error) {
+		page, next, err := Paginate(catalog, cursor, 2)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ListPromptsResult{Prompts: page, NextCursor: string(next)}, nil
+	})
+	for i := range 3 {
+		s.AddPrompt(mcp.NewPrompt(fmt.Sprintf("prompt-%d", i)), nil)
+	}
+
+	var pages [][]string
+	var cursor string
+	for id := 1; ; id++ {
+		params, _ := json.Marshal(mcp.ListPromptsRequestParams{Cursor: cursor})
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      id,
+			Method:  "prompts/list",
+			Params:  params,
+		})
+		require.Nil(t, response.Error)
+		result := response.Result.(*mcp.ListPromptsResult)
+		var names []string
+		for _, prompt := range result.Prompts {
+			names = append(names, prompt.Name)
+		}
+		pages = append(pages, names)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	assert.Equal(t, [][]string{
+		{"handler-1", "handler-2"},
+		{"handler-3", "prompt-0", "prompt-1"},
+		{"prompt-2"},
+	}, pages)
+}
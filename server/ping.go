@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// SessionPingConfig configures the liveness pings sent to every session
+type SessionPingConfig struct {
+	// Interval is the time between pings
+	Interval time.Duration
+	// Timeout bounds how long a ping may go unanswered. It defaults to
+	// Interval.
+	Timeout time.Duration
+	// MaxFailures is how many pings in a row may fail before the session
+	// is evicted. It defaults to 3.
+	MaxFailures int
+}
+
+// WithSessionPing pings every connected session and evicts sessions that
+// fail config.MaxFailures pings in a row. Evicted sessions are closed in
+// their transport and end with the OnSessionEnd hook, so dead clients do
+// not linger until their connection is noticed to be gone.
+func WithSessionPing(config SessionPingConfig) ServerOption {
+	return func(s *DefaultServer) {
+		if config.Interval <= 0 {
+			return
+		}
+		if config.Timeout <= 0 {
+			config.Timeout = config.Interval
+		}
+		if config.MaxFailures <= 0 {
+			config.MaxFailures = 3
+		}
+		s.sessionPing = &config
+	}
+}
+
+// pingSession pings session until it is unregistered or evicted
+func (s *DefaultServer) pingSession(session *clientSession) {
+	config := s.sessionPing
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-session.closed:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		_, err := session.request(ctx, mcp.MethodPing, nil)
+		cancel()
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		if failures >= config.MaxFailures {
+			s.evictSession(session, err)
+			return
+		}
+	}
+}
+
+// evictSession unregisters a session that stopped answering and closes it
+// in its transport
+func (s *DefaultServer) evictSession(session *clientSession, err error) {
+	s.sessions.mu.RLock()
+	current := s.sessions.sessions[session.id] == session
+	s.sessions.mu.RUnlock()
+	if !current {
+		return
+	}
+
+	s.log(withSessionID(context.Background(), session.id), slog.LevelWarn,
+		"session evicted", mcp.MethodPing, slog.String("error", err.Error()))
+	s.unregisterSession(session.id)
+	if session.closeTransport != nil {
+		session.closeTransport()
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSessionPing(t *testing.T) {
+	ended := make(chan string, 2)
+	s := NewDefaultServer("test", "1.0.0",
+		WithSessionPing(SessionPingConfig{Interval: 10 * time.Millisecond, MaxFailures: 2}),
+		WithHooks(Hooks{OnSessionEnd: func(session *Session) { ended <- session.ID() }}),
+	).(*DefaultServer)
+
+	var pings atomic.Int32
+	s.registerSession("alive", func(message any) error {
+		if request, ok := message.(serverRequest); ok && request.Method == "ping" {
+			pings.Add(1)
+			go s.handleClientResponse("alive", json.RawMessage(fmt.Sprintf(
+				`{"jsonrpc":"2.0","id":%d,"result":{}}`, request.ID,
+			)))
+		}
+		return nil
+	})
+	var closed atomic.Bool
+	s.registerSessionWith("dead", (&recorder{}).send, sessionOptions{
+		close: func() { closed.Store(true) },
+	})
+
+	select {
+	case id := <-ended:
+		assert.Equal(t, "dead", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("dead session was not evicted")
+	}
+	assert.True(t, closed.Load())
+	_, ok := s.Session("dead")
+	assert.False(t, ok)
+
+	require.Eventually(t, func() bool { return pings.Load() >= 3 }, 5*time.Second, 5*time.Millisecond)
+	_, ok = s.Session("alive")
+	assert.True(t, ok)
+
+	s.unregisterSession("alive")
+	assert.Equal(t, "alive", <-ended)
+}
+
+func TestSSEServerEvictsDeadSessions(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0",
+		WithSessionPing(SessionPingConfig{Interval: 10 * time.Millisecond, MaxFailures: 1}))
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	_, messages := connectSSE(t, testServer.URL)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream of an unresponsive client stayed open")
+		}
+	}
+}
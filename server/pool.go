@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrServerBusy is returned when the worker pool queue is full and the
+// rejection policy is RejectWhenFull
+var ErrServerBusy = errors.New("server busy")
+
+// ErrServerShutdown is returned for requests that reach the worker pool
+// after DefaultServer.Shutdown stopped it
+var ErrServerShutdown = errors.New("server shut down")
+
+// RejectionPolicy decides what happens to a request when the worker pool
+// queue is full
+type RejectionPolicy int
+
+const (
+	// BlockWhenFull waits for queue space until the request context is done
+	BlockWhenFull RejectionPolicy = iota
+	// RejectWhenFull fails the request immediately with ErrServerBusy
+	RejectWhenFull
+)
+
+// WorkerPoolConfig configures the pool that runs request handlers
+type WorkerPoolConfig struct {
+	// Size is the number of worker goroutines
+	Size int
+	// QueueDepth is the number of requests that may wait for a worker
+	QueueDepth int
+	// Policy applies when QueueDepth requests are already waiting
+	Policy RejectionPolicy
+	// Metrics receives pool events, every hook is optional
+	Metrics WorkerPoolMetrics
+}
+
+// WorkerPoolMetrics hooks are called synchronously from the dispatch path
+// and must not block
+type WorkerPoolMetrics struct {
+	// OnQueued is called after a request is queued with the new queue depth
+	OnQueued func(method string, depth int)
+	// OnRejected is called when a request is turned away
+	OnRejected func(method string)
+	// OnStart is called when a worker picks up a request
+	OnStart func(method string, wait time.Duration)
+	// OnDone is called when a handler returns
+	OnDone func(method string, duration time.Duration)
+}
+
+// Shutdown stops the workers of WithWorkerPool once the handlers they are
+// running return, or ctx is done. Requests that have not reached a worker
+// fail with ErrServerShutdown. Without a pool it does nothing.
+// SSEServer.Shutdown calls it.
+func (s *DefaultServer) Shutdown(ctx context.Context) error {
+	if s.pool == nil {
+		return nil
+	}
+	return s.pool.shutdown(ctx)
+}
+
+// shutdowner is implemented by servers with workers of their own to stop
+// when a transport shuts down
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+type poolJob struct {
+	ctx      context.Context
+	method   string
+	run      func(ctx context.Context)
+	enqueued time.Time
+	done     chan struct{}
+}
+
+type workerPool struct {
+	queue   chan *poolJob
+	policy  RejectionPolicy
+	metrics WorkerPoolMetrics
+	workers sync.WaitGroup
+	// stop tells the workers to return, stopped closes once they have
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// newWorkerPool starts the workers. They run until shutdown is called.
+func newWorkerPool(config WorkerPoolConfig) *workerPool {
+	if config.Size < 1 {
+		config.Size = 1
+	}
+	if config.QueueDepth < 0 {
+		config.QueueDepth = 0
+	}
+
+	p := &workerPool{
+		queue:   make(chan *poolJob, config.QueueDepth),
+		policy:  config.Policy,
+		metrics: config.Metrics,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	p.workers.Add(config.Size)
+	for i := 0; i < config.Size; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *workerPool) work() {
+	defer p.workers.Done()
+	for {
+		var job *poolJob
+		select {
+		case <-p.stop:
+			return
+		case job = <-p.queue:
+		}
+		// Callers that gave up while queued are not run at all
+		if job.ctx.Err() == nil {
+			if p.metrics.OnStart != nil {
+				p.metrics.OnStart(job.method, time.Since(job.enqueued))
+			}
+			start := time.Now()
+			job.run(job.ctx)
+			if p.metrics.OnDone != nil {
+				p.metrics.OnDone(job.method, time.Since(start))
+			}
+		}
+		close(job.done)
+	}
+}
+
+// shutdown stops the workers once the handlers they are running return,
+// or ctx is done. Requests still queued are not run.
+func (p *workerPool) shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		go func() {
+			p.workers.Wait()
+			close(p.stopped)
+		}()
+	})
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// submit queues run and waits for it to finish. The caller stops waiting
+// when ctx is done; run must only write state owned by the job.
+func (p *workerPool) submit(
+	ctx context.Context,
+	method string,
+	run func(ctx context.Context),
+) error {
+	job := &poolJob{
+		ctx:      ctx,
+		method:   method,
+		run:      run,
+		enqueued: time.Now(),
+		done:     make(chan struct{}),
+	}
+
+	select {
+	case <-p.stop:
+		return ErrServerShutdown
+	default:
+	}
+
+	switch p.policy {
+	case RejectWhenFull:
+		select {
+		case p.queue <- job:
+		default:
+			if p.metrics.OnRejected != nil {
+				p.metrics.OnRejected(method)
+			}
+			return ErrServerBusy
+		}
+	default:
+		select {
+		case p.queue <- job:
+		case <-ctx.Done():
+			if p.metrics.OnRejected != nil {
+				p.metrics.OnRejected(method)
+			}
+			return ctx.Err()
+		case <-p.stop:
+			return ErrServerShutdown
+		}
+	}
+
+	if p.metrics.OnQueued != nil {
+		p.metrics.OnQueued(method, len(p.queue))
+	}
+
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.stopped:
+		// No worker is left to pick the job up, unless one already ran it
+		select {
+		case <-job.done:
+			return nil
+		default:
+			return ErrServerShutdown
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_WorkerPool(t *testing.T) {
+	var queued, rejected, started, done atomic.Int32
+	s := NewDefaultServer("test", "1.0.0", WithWorkerPool(WorkerPoolConfig{
+		Size:       1,
+		QueueDepth: 1,
+		Policy:     RejectWhenFull,
+		Metrics: WorkerPoolMetrics{
+			OnQueued:   func(string, int) { queued.Add(1) },
+			OnRejected: func(string) { rejected.Add(1) },
+			OnStart:    func(string, time.Duration) { started.Add(1) },
+			OnDone:     func(string, time.Duration) { done.Add(1) },
+		},
+	}))
+
+	release := make(chan struct{})
+	running := make(chan struct{}, 2)
+	s.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			running <- struct{}{}
+			<-release
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+
+	call := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"block"}`),
+	}
+	ctx := context.Background()
+
+	results := make(chan JSONRPCResponse, 2)
+	go func() { results <- s.Request(ctx, call) }()
+	<-running
+
+	go func() { results <- s.Request(ctx, call) }()
+	require.Eventually(t, func() bool {
+		return queued.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	// The worker is busy and the queue is full
+	busy := s.Request(ctx, call)
+	require.NotNil(t, busy.Error)
+	assert.Equal(t, mcp.ServerUnavailable, busy.Error.Code)
+	assert.Equal(t, int32(1), rejected.Load())
+
+	// Pings skip the pool
+	ping := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "ping"})
+	assert.Nil(t, ping.Error)
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		result := <-results
+		assert.Nil(t, result.Error)
+	}
+	assert.Equal(t, int32(2), started.Load())
+	assert.Equal(t, int32(2), done.Load())
+}
+
+func TestDefaultServer_WorkerPoolCanceled(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithWorkerPool(WorkerPoolConfig{
+		Size: 1,
+	}))
+
+	release := make(chan struct{})
+	defer close(release)
+	s.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			<-release
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"block"}`),
+	})
+	require.NotNil(t, result.Error)
+	assert.Contains(t, result.Error.Message, context.DeadlineExceeded.Error())
+}
+
+func TestDefaultServer_WorkerPoolShutdown(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithWorkerPool(WorkerPoolConfig{
+		Size:       1,
+		QueueDepth: 1,
+	}))
+
+	release := make(chan struct{})
+	running := make(chan struct{}, 1)
+	s.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			running <- struct{}{}
+			<-release
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+	call := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"block"}`),
+	}
+	ctx := context.Background()
+
+	first := make(chan JSONRPCResponse, 1)
+	go func() { first <- s.Request(ctx, call) }()
+	<-running
+
+	// Shutdown waits for the running handler, giving up with ctx
+	shutdown := s.(*DefaultServer).Shutdown
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, shutdown(timeout), context.DeadlineExceeded)
+
+	close(release)
+	assert.Nil(t, (<-first).Error)
+	require.NoError(t, shutdown(ctx))
+
+	// Requests after shutdown are turned away
+	result := s.Request(ctx, call)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, mcp.ServerUnavailable, result.Error.Code)
+	assert.Contains(t, result.Error.Message, ErrServerShutdown.Error())
+}
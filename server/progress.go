@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+type progressTokenKey struct{}
+
+func withProgressToken(ctx context.Context, token mcp.AnyProgressToken) context.Context {
+	return context.WithValue(ctx, progressTokenKey{}, token)
+}
+
+// progressToken returns the token from the request _meta, if any
+func progressToken(params json.RawMessage) (mcp.AnyProgressToken, bool) {
+	var request struct {
+		Meta struct {
+			ProgressToken *mcp.AnyProgressToken `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if json.Unmarshal(params, &request) != nil || request.Meta.ProgressToken == nil {
+		return mcp.AnyProgressToken{}, false
+	}
+	return *request.Meta.ProgressToken, true
+}
+
+// Progress reports the progress of the request being handled to its
+// caller. It is obtained with ProgressFromContext.
+type Progress struct {
+	token  *mcp.AnyProgressToken
+	notify notifyFunc
+}
+
+// ProgressFromContext returns the progress reporter of the request being
+// handled. When the caller did not ask for progress with a progressToken
+// in _meta, reports are dropped, so handlers can report unconditionally.
+func ProgressFromContext(ctx context.Context) *Progress {
+	progress := &Progress{notify: notifierFromContext(ctx)}
+	if token, ok := ctx.Value(progressTokenKey{}).(mcp.AnyProgressToken); ok {
+		progress.token = &token
+	}
+	return progress
+}
+
+// Enabled reports whether the caller asked for progress
+func (p *Progress) Enabled() bool {
+	return p.token != nil && p.notify != nil
+}
+
+// Report sends a notifications/progress. progress must increase with
+// every report; total is 0 when unknown and message may be empty.
+func (p *Progress) Report(progress, total float64, message string) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	params := mcp.ProgressParams{
+		ProgressToken: *p.token,
+		Progress:      progress,
+		Message:       message,
+	}
+	if total > 0 {
+		params.Total = &total
+	}
+	if err := p.notify(mcp.MethodNotificationProgress, params); err != nil {
+		return fmt.Errorf("failed to report progress: %w", err)
+	}
+	return nil
+}
+
+// ReportReadProgress tells the client how many bytes of a resource have
+// been read so far, for reads of huge resources. total is 0 when the size
+// is unknown. It does nothing unless the caller asked for progress.
+func ReportReadProgress(ctx context.Context, read, total int64) error {
+	return ProgressFromContext(ctx).Report(float64(read), float64(total), "")
+}
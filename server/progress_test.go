@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_ReadProgressAndCancel(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+
+	handlerDone := make(chan error, 1)
+	s.HandleReadResource(
+		func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+			for read := int64(100); read <= 300; read += 100 {
+				require.NoError(t, ReportReadProgress(ctx, read, 1000))
+			}
+			<-ctx.Done()
+			handlerDone <- ctx.Err()
+			return &mcp.ReadResourceResult{}, nil
+		},
+	)
+
+	var mu sync.Mutex
+	var progress []json.RawMessage
+	ctx := withSessionID(context.Background(), "session")
+	ctx = withNotifier(ctx, func(method string, params any) error {
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		assert.Equal(t, "notifications/progress", method)
+		mu.Lock()
+		progress = append(progress, data)
+		mu.Unlock()
+		return nil
+	})
+
+	responses := make(chan JSONRPCResponse, 1)
+	go func() {
+		responses <- s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      float64(7),
+			Method:  "resources/read",
+			Params:  json.RawMessage(`{"uri":"file:///huge","_meta":{"progressToken":"read-1"}}`),
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(progress) == 3
+	}, time.Second, time.Millisecond)
+
+	// A cancellation from another session does not match
+	other := withSessionID(context.Background(), "other")
+	s.Request(other, JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  json.RawMessage(`{"requestId":7}`),
+	})
+	select {
+	case <-handlerDone:
+		t.Fatal("request cancelled from another session")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  json.RawMessage(`{"requestId":7,"reason":"user stopped"}`),
+	})
+	assert.ErrorIs(t, <-handlerDone, context.Canceled)
+
+	response := <-responses
+	assert.Nil(t, response.Result)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, errRequestCancelled.Error(), response.Error.Message)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.JSONEq(t, `{"progressToken":"read-1","progress":300,"total":1000}`, string(progress[2]))
+}
+
+func TestReportReadProgressWithoutToken(t *testing.T) {
+	called := false
+	ctx := withNotifier(context.Background(), func(string, any) error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, ReportReadProgress(ctx, 1, 2))
+	assert.False(t, called)
+}
+
+func TestProgressFromContext(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	s.AddTool(mcp.ToolDefinition{Name: "build", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			progress := ProgressFromContext(ctx)
+			if err := progress.Report(1, 2, "compiling"); err != nil {
+				return nil, err
+			}
+			if err := progress.Report(2, 2, "linking"); err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{}, nil
+		})
+
+	var progress []string
+	ctx := withNotifier(context.Background(), func(method string, params any) error {
+		assert.Equal(t, "notifications/progress", method)
+		data, err := json.Marshal(params)
+		require.NoError(t, err)
+		progress = append(progress, string(data))
+		return nil
+	})
+
+	response := callTool(t, s, ctx, `{"name":"build","_meta":{"progressToken":42}}`)
+	require.Nil(t, response.Error)
+	require.Len(t, progress, 2)
+	assert.JSONEq(t, `{"progressToken":42,"progress":1,"total":2,"message":"compiling"}`, progress[0])
+	assert.JSONEq(t, `{"progressToken":42,"progress":2,"total":2,"message":"linking"}`, progress[1])
+
+	// Without a token nothing is sent
+	progress = nil
+	response = callTool(t, s, ctx, `{"name":"build"}`)
+	require.Nil(t, response.Error)
+	assert.Empty(t, progress)
+	assert.False(t, ProgressFromContext(context.Background()).Enabled())
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// PromptHandlerFunc gets a prompt registered with AddPrompt
+type PromptHandlerFunc func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error)
+
+// AddPrompt registers a prompt together with the function that gets it.
+// The prompt is listed by prompts/list and gets of its name go to handler
+// instead of the function set with HandleGetPrompt. Adding a prompt with
+// the name of an existing one replaces it.
+func (s *DefaultServer) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc) {
+	s.registry.update(RegistryKindPrompt, func() bool {
+		addPrompt(s.registry, prompt, handler)
+		return true
+	})
+}
+
+// RemovePrompt unregisters a prompt added with AddPrompt. It reports
+// whether the prompt was registered.
+func (s *DefaultServer) RemovePrompt(name string) bool {
+	return s.registry.update(RegistryKindPrompt, func() bool {
+		return s.registry.remove(registryKey{RegistryKindPrompt, name})
+	})
+}
+
+// addPrompt adds a prompt and its handler to r, which the caller holds
+func addPrompt(r *Registry, prompt mcp.Prompt, handler PromptHandlerFunc) {
+	r.add(registryKey{RegistryKindPrompt, prompt.Name}, &registryEntry{value: prompt, handler: handler})
+}
+
+// registeredPrompt returns the handler of a prompt added with AddPrompt
+func (s *DefaultServer) registeredPrompt(name string) (PromptHandlerFunc, bool) {
+	entry, ok := s.registry.entry(registryKey{RegistryKindPrompt, name})
+	if !ok {
+		return nil, false
+	}
+	handler, ok := entry.handler.(PromptHandlerFunc)
+	return handler, ok
+}
+
+// getPrompt gets a prompt added with AddPrompt, and otherwise calls the
+// function set with HandleGetPrompt
+func (s *DefaultServer) getPrompt(
+	ctx context.Context,
+	name string,
+	arguments map[string]string,
+) (*mcp.GetPromptResult, error) {
+	if handler, ok := s.registeredPrompt(name); ok {
+		return handler(ctx, mcp.GetPromptRequest{
+			Method: mcp.MethodPromptsGet,
+			Params: mcp.GetPromptRequestParams{Name: name, Arguments: arguments},
+		})
+	}
+	return s.handlers[mcp.MethodPromptsGet].(GetPromptFunc)(ctx, name, arguments)
+}
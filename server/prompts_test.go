@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_AddPrompt(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	s.HandleGetPrompt(func(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("from the handler"), nil
+	})
+	s.AddPrompt(
+		mcp.NewPrompt("summarize", mcp.WithArgument("topic", mcp.Required())),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("", mcp.NewPromptMessage(
+				mcp.RoleUser,
+				mcp.NewTextContent("Summarize "+request.Params.Arguments["topic"]),
+			)), nil
+		},
+	)
+
+	var client recorder
+	s.registerSession("client", client.send)
+	ctx := withSessionID(context.Background(), "client")
+	init := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, init.Error)
+	capabilities := init.Result.(*mcp.InitializeResult).Capabilities
+	require.NotNil(t, capabilities.Prompts)
+	assert.True(t, capabilities.Prompts.ListChanged)
+
+	list := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "prompts/list"})
+	require.Nil(t, list.Error)
+	prompts := list.Result.(*mcp.ListPromptsResult).Prompts
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "summarize", prompts[0].Name)
+
+	get := func(name string) *mcp.GetPromptResult {
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      3,
+			Method:  "prompts/get",
+			Params:  json.RawMessage(`{"name": "` + name + `", "arguments": {"topic": "Go"}}`),
+		})
+		require.Nil(t, response.Error)
+		return response.Result.(*mcp.GetPromptResult)
+	}
+	assert.Equal(t, mcp.NewTextContent("Summarize Go"), get("summarize").Messages[0].Content)
+	// Other prompts go to the handler
+	assert.Equal(t, "from the handler", get("other").Description)
+
+	assert.True(t, s.RemovePrompt("summarize"))
+	assert.False(t, s.RemovePrompt("summarize"))
+	assert.Equal(t, "from the handler", get("summarize").Description)
+	assert.Equal(t, []string{mcp.MethodNotificationPromptsListChanged}, client.methods())
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+)
+
+// defaultEventQueueSize is how many events may wait to be written on an
+// SSE stream when no size is configured
+const defaultEventQueueSize = 64
+
+// ErrEventQueueFull is returned for an event that does not fit in the
+// queue of its session under QueueDrop or QueueClose
+var ErrEventQueueFull = errors.New("event queue full")
+
+// errSessionClosed is returned for an event sent to a session whose
+// stream has ended
+var errSessionClosed = errors.New("session closed")
+
+// QueueFullPolicy decides what happens to an event sent to a session whose
+// queue is full
+type QueueFullPolicy int
+
+const (
+	// QueueBlock makes the sender wait for room in the queue
+	QueueBlock QueueFullPolicy = iota
+	// QueueDrop drops the event
+	QueueDrop
+	// QueueClose drops the event and closes the session, for clients too
+	// slow to keep up
+	QueueClose
+)
+
+// EventQueueConfig configures the queue of events waiting to be written
+// on the stream of each SSE session
+type EventQueueConfig struct {
+	// Size is how many events may wait. It defaults to 64.
+	Size int
+	// Policy applies once Size events are waiting
+	Policy QueueFullPolicy
+}
+
+// WithEventQueue configures the outbound event queue of every session.
+// Events are written in the order they are queued by the one goroutine
+// serving the stream.
+func WithEventQueue(config EventQueueConfig) SSEOption {
+	return func(s *SSEServer) {
+		if config.Size <= 0 {
+			config.Size = defaultEventQueueSize
+		}
+		s.eventQueue = config
+	}
+}
+
+// enqueue queues message to be written on the session stream
+func (s *sseSession) enqueue(message *encodedMessage) error {
+	select {
+	case <-s.done:
+		message.discard()
+		return errSessionClosed
+	default:
+	}
+
+	if s.policy == QueueBlock {
+		select {
+		case s.queue <- message:
+			return nil
+		case <-s.done:
+			message.discard()
+			return errSessionClosed
+		}
+	}
+
+	select {
+	case s.queue <- message:
+		return nil
+	default:
+	}
+	message.discard()
+	if s.policy == QueueClose {
+		s.close()
+		return fmt.Errorf("%w, session closed", ErrEventQueueFull)
+	}
+	return ErrEventQueueFull
+}
+
+// flush writes the events still queued, once the session has been closed
+// while its client is still connected
+func (s *sseSession) flush() {
+	for {
+		select {
+		case message := <-s.queue:
+			if err := s.writeEvent(message); err != nil {
+				s.discardQueued()
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// discardQueued drops the events still queued
+func (s *sseSession) discardQueued() {
+	for {
+		select {
+		case message := <-s.queue:
+			message.discard()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEServerConcurrentEvents(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+	t.Cleanup(testServer.Close)
+	sessionID, messages := connectSSE(t, testServer.URL)
+
+	const senders = 50
+	var wg sync.WaitGroup
+	for i := range senders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, sseServer.SendEventToSession(sessionID, notification{
+				JSONRPC: "2.0",
+				Method:  "notifications/test",
+				Params:  map[string]any{"sender": i},
+			}))
+		}()
+	}
+	wg.Wait()
+
+	seen := map[float64]bool{}
+	for range senders {
+		message := nextMessage(t, messages)
+		seen[message["params"].(map[string]any)["sender"].(float64)] = true
+	}
+	assert.Len(t, seen, senders)
+}
+
+func TestSSESessionQueuePolicies(t *testing.T) {
+	newSession := func(policy QueueFullPolicy) *sseSession {
+		return &sseSession{
+			done:   make(chan struct{}),
+			queue:  make(chan *encodedMessage, 1),
+			policy: policy,
+		}
+	}
+	message := func() *encodedMessage {
+		encoded, err := encodeMessage(notification{JSONRPC: "2.0", Method: "notifications/test"})
+		require.NoError(t, err)
+		return encoded
+	}
+
+	t.Run("Drop", func(t *testing.T) {
+		session := newSession(QueueDrop)
+		require.NoError(t, session.enqueue(message()))
+		assert.ErrorIs(t, session.enqueue(message()), ErrEventQueueFull)
+		assert.Len(t, session.queue, 1)
+		assert.NotPanics(t, func() { close(session.done) })
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		session := newSession(QueueClose)
+		require.NoError(t, session.enqueue(message()))
+		assert.ErrorIs(t, session.enqueue(message()), ErrEventQueueFull)
+		select {
+		case <-session.done:
+		default:
+			t.Fatal("session not closed")
+		}
+		assert.ErrorIs(t, session.enqueue(message()), errSessionClosed)
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		session := newSession(QueueBlock)
+		require.NoError(t, session.enqueue(message()))
+		result := make(chan error, 1)
+		go func() { result <- session.enqueue(message()) }()
+		select {
+		case err := <-result:
+			t.Fatalf("enqueue returned %v with a full queue", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		<-session.queue
+		require.NoError(t, <-result)
+
+		go func() { result <- session.enqueue(message()) }()
+		time.Sleep(10 * time.Millisecond)
+		session.close()
+		assert.ErrorIs(t, <-result, errSessionClosed)
+	})
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket: Rate requests per second on average, with
+// bursts of up to Burst requests
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig configures the request rate limiter. Every limit is
+// optional; a request must fit within all the limits that apply to it.
+type RateLimitConfig struct {
+	// Global limits the requests of all sessions together
+	Global *RateLimit
+	// PerSession limits the requests of each session
+	PerSession *RateLimit
+	// PerMethod limits the requests of all sessions to a method
+	PerMethod map[string]RateLimit
+}
+
+// RateLimitError is returned for a request turned away by the rate
+// limiter. It is answered with mcp.ServerUnavailable and the retry delay in the error
+// data as retryAfterMs.
+type RateLimitError struct {
+	// RetryAfter is how long until the request would be let through
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// WithRateLimit limits the rate of requests the server handles.
+// Notifications are not limited.
+func WithRateLimit(config RateLimitConfig) ServerOption {
+	return func(s *DefaultServer) {
+		s.limiter = &rateLimiter{
+			config:  config,
+			buckets: make(map[string]*tokenBucket),
+			now:     time.Now,
+		}
+	}
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the last request
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	b.last = now
+}
+
+// wait returns how long until the bucket has a token
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	if b.limit.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / b.limit.Rate * float64(time.Second)))
+}
+
+type rateLimiter struct {
+	config RateLimitConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// allow takes a token from every bucket that applies to a request, or from
+// none when one of them is empty
+func (l *rateLimiter) allow(sessionID string, method string) error {
+	if l == nil || strings.HasPrefix(method, "notifications/") {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var buckets []*tokenBucket
+	use := func(key string, limit *RateLimit) {
+		if limit == nil {
+			return
+		}
+		bucket, ok := l.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{limit: *limit, tokens: float64(limit.Burst), last: now}
+			l.buckets[key] = bucket
+		}
+		bucket.refill(now)
+		buckets = append(buckets, bucket)
+	}
+	use("global", l.config.Global)
+	if sessionID != "" {
+		use("session/"+sessionID, l.config.PerSession)
+	}
+	if limit, ok := l.config.PerMethod[method]; ok {
+		use("method/"+method, &limit)
+	}
+
+	var retryAfter time.Duration
+	for _, bucket := range buckets {
+		retryAfter = max(retryAfter, bucket.wait())
+	}
+	if retryAfter > 0 {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return nil
+}
+
+// forget drops the bucket of a session that is gone
+func (l *rateLimiter) forget(sessionID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, "session/"+sessionID)
+}
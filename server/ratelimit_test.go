@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithRateLimit(RateLimitConfig{
+		Global:     &RateLimit{Rate: 10, Burst: 4},
+		PerSession: &RateLimit{Rate: 1, Burst: 2},
+		PerMethod:  map[string]RateLimit{"tools/list": {Rate: 1, Burst: 1}},
+	})).(*DefaultServer)
+	now := time.Unix(0, 0)
+	s.limiter.now = func() time.Time { return now }
+	s.registerSession("a", (&recorder{}).send)
+	s.registerSession("b", (&recorder{}).send)
+	request := func(sessionID, method string) *JSONRPCError {
+		ctx := withSessionID(context.Background(), sessionID)
+		return s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method}).Error
+	}
+
+	require.Nil(t, request("a", "ping"))
+	require.Nil(t, request("a", "ping"))
+	limited := request("a", "ping")
+	require.NotNil(t, limited)
+	assert.Equal(t, mcp.ServerUnavailable, limited.Code)
+	assert.Equal(t, int64(1000), limited.Data.(map[string]any)["retryAfterMs"])
+
+	// Other sessions have their own bucket, down to the method limit
+	require.Nil(t, request("b", "tools/list"))
+	assert.NotNil(t, request("b", "tools/list"))
+
+	// The global bucket is down to one token; a rejected request takes none
+	require.Nil(t, request("", "ping"))
+	limited = request("", "ping")
+	require.NotNil(t, limited)
+	assert.Equal(t, int64(100), limited.Data.(map[string]any)["retryAfterMs"])
+
+	now = now.Add(time.Second)
+	assert.Nil(t, request("a", "ping"))
+	assert.Nil(t, request("b", "tools/list"))
+
+	// Notifications are never limited
+	for range 10 {
+		assert.Nil(t, request("a", "notifications/initialized"))
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+)
+
+// errHandlerPanic is returned in place of the result of a handler that
+// panicked. What the handler panicked with stays in the server log.
+var errHandlerPanic = errors.New("internal error")
+
+// safeHandleRequest runs handleRequest and turns a panic of the handler
+// into errHandlerPanic. The panic and its stack are logged.
+func (s *DefaultServer) safeHandleRequest(
+	ctx context.Context,
+	method string,
+	params json.RawMessage,
+) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log(ctx, slog.LevelError, "handler panicked", method,
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+			resp, err = nil, errHandlerPanic
+		}
+	}()
+	return s.handleRequest(ctx, method, params)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerPanic(t *testing.T) {
+	for name, opts := range map[string][]ServerOption{
+		"Inline":     nil,
+		"WorkerPool": {WithWorkerPool(WorkerPoolConfig{Size: 1})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			s := NewDefaultServer("test", "1.0.0",
+				append(opts, WithSlogLogger(slog.New(slog.NewJSONHandler(&logs, nil))))...)
+			s.AddTool(mcp.ToolDefinition{Name: "boom", InputSchema: mcp.ToolSchema{Type: "object"}},
+				func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					panic("secret detail")
+				})
+
+			response := s.Request(context.Background(), JSONRPCRequest{
+				JSONRPC: "2.0", ID: 1, Method: "tools/call",
+				Params: json.RawMessage(`{"name":"boom"}`),
+			})
+			require.NotNil(t, response.Error)
+			assert.Equal(t, mcp.InternalError, response.Error.Code)
+			assert.Equal(t, "internal error", response.Error.Message)
+			var record map[string]any
+			require.NoError(t, json.NewDecoder(&logs).Decode(&record))
+			assert.Equal(t, "handler panicked", record["msg"])
+			assert.Equal(t, "tools/call", record["method"])
+			assert.Equal(t, "secret detail", record["panic"])
+			assert.Contains(t, record["stack"], "goroutine")
+
+			// The server keeps serving
+			response = s.Request(context.Background(), JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "ping"})
+			assert.Nil(t, response.Error)
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package server
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// RegistryKind identifies the kind of entry held in a Registry
+type RegistryKind string
+
+const (
+	RegistryKindTool             RegistryKind = "tool"
+	RegistryKindResource         RegistryKind = "resource"
+	RegistryKindResourceTemplate RegistryKind = "resourceTemplate"
+	RegistryKindPrompt           RegistryKind = "prompt"
+)
+
+// RegistryChangeType describes what happened to a registry entry
+type RegistryChangeType string
+
+const (
+	RegistryChangeAdded    RegistryChangeType = "added"
+	RegistryChangeUpdated  RegistryChangeType = "updated"
+	RegistryChangeRemoved  RegistryChangeType = "removed"
+	RegistryChangeRestored RegistryChangeType = "restored"
+)
+
+// RegistryChange is a single changelog record. Previous and Current hold the
+// JSON encoded definitions before and after the change.
+type RegistryChange struct {
+	Kind        RegistryKind       `json:"kind"`
+	Name        string             `json:"name"`
+	Type        RegistryChangeType `json:"type"`
+	Time        time.Time          `json:"time"`
+	SchemaDrift bool               `json:"schemaDrift,omitempty"`
+	Previous    json.RawMessage    `json:"previous,omitempty"`
+	Current     json.RawMessage    `json:"current,omitempty"`
+}
+
+// maxChangelog bounds the changes a Registry keeps; older ones are dropped
+const maxChangelog = 1000
+
+type registryKey struct {
+	kind RegistryKind
+	name string
+}
+
+type registryEntry struct {
+	value any
+	// handler serves the entry on a DefaultServer, nil for entries added
+	// to a Registry directly
+	handler any
+	// seq orders entries by when they were first added
+	seq uint64
+}
+
+// Registry holds the entities a server exposes. Removing an entry keeps a
+// tombstone so it can be restored, and so re-adding an entry with the same
+// name can be compared against what was there before.
+type Registry struct {
+	mu         sync.RWMutex
+	entries    map[registryKey]*registryEntry
+	tombstones map[registryKey]*registryEntry
+	changelog  []RegistryChange
+	now        func() time.Time
+	listeners  []func(kind RegistryKind)
+	seq        uint64
+	// staged registries collect the changes of a batch instead of
+	// notifying, see BatchUpdate
+	staged bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:    make(map[registryKey]*registryEntry),
+		tombstones: make(map[registryKey]*registryEntry),
+		now:        time.Now,
+	}
+}
+
+// AddTool adds or replaces a tool. The returned change reports whether the
+// input schema differs from the previous or tombstoned definition.
+func (r *Registry) AddTool(tool mcp.ToolDefinition) RegistryChange {
+	r.mu.Lock()
+	change := r.add(registryKey{RegistryKindTool, tool.Name}, &registryEntry{value: tool})
+	r.mu.Unlock()
+	r.notify(RegistryKindTool)
+	return change
+}
+
+// RemoveTool soft-deletes a tool, keeping a tombstone of its definition
+func (r *Registry) RemoveTool(name string) bool {
+	r.mu.Lock()
+	removed := r.remove(registryKey{RegistryKindTool, name})
+	r.mu.Unlock()
+	if removed {
+		r.notify(RegistryKindTool)
+	}
+	return removed
+}
+
+// RestoreTool brings back a tool that was previously removed
+func (r *Registry) RestoreTool(name string) bool {
+	r.mu.Lock()
+	restored := r.restore(registryKey{RegistryKindTool, name})
+	r.mu.Unlock()
+	if restored {
+		r.notify(RegistryKindTool)
+	}
+	return restored
+}
+
+// AddResource adds or replaces a resource, keyed by its URI
+func (r *Registry) AddResource(resource mcp.Resource) RegistryChange {
+	r.mu.Lock()
+	change := r.add(registryKey{RegistryKindResource, resource.Uri}, &registryEntry{value: resource})
+	r.mu.Unlock()
+	r.notify(RegistryKindResource)
+	return change
+}
+
+// RemoveResource soft-deletes a resource, keeping a tombstone of its
+// definition
+func (r *Registry) RemoveResource(uri string) bool {
+	r.mu.Lock()
+	removed := r.remove(registryKey{RegistryKindResource, uri})
+	r.mu.Unlock()
+	if removed {
+		r.notify(RegistryKindResource)
+	}
+	return removed
+}
+
+// AddResourceTemplate adds or replaces a resource template, keyed by its
+// URI template
+func (r *Registry) AddResourceTemplate(template mcp.ResourceTemplate) RegistryChange {
+	r.mu.Lock()
+	change := r.add(
+		registryKey{RegistryKindResourceTemplate, template.UriTemplate},
+		&registryEntry{value: template},
+	)
+	r.mu.Unlock()
+	r.notify(RegistryKindResourceTemplate)
+	return change
+}
+
+// RemoveResourceTemplate soft-deletes a resource template, keeping a
+// tombstone of its definition
+func (r *Registry) RemoveResourceTemplate(uriTemplate string) bool {
+	r.mu.Lock()
+	removed := r.remove(registryKey{RegistryKindResourceTemplate, uriTemplate})
+	r.mu.Unlock()
+	if removed {
+		r.notify(RegistryKindResourceTemplate)
+	}
+	return removed
+}
+
+// AddPrompt adds or replaces a prompt, keyed by its name
+func (r *Registry) AddPrompt(prompt mcp.Prompt) RegistryChange {
+	r.mu.Lock()
+	change := r.add(registryKey{RegistryKindPrompt, prompt.Name}, &registryEntry{value: prompt})
+	r.mu.Unlock()
+	r.notify(RegistryKindPrompt)
+	return change
+}
+
+// RemovePrompt soft-deletes a prompt, keeping a tombstone of its definition
+func (r *Registry) RemovePrompt(name string) bool {
+	r.mu.Lock()
+	removed := r.remove(registryKey{RegistryKindPrompt, name})
+	r.mu.Unlock()
+	if removed {
+		r.notify(RegistryKindPrompt)
+	}
+	return removed
+}
+
+// OnListChanged registers fn to be called after a committed change to the
+// entries of a kind, for sending list_changed notifications. A batch calls
+// fn once per kind it changed.
+func (r *Registry) OnListChanged(fn func(kind RegistryKind)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// BatchUpdate runs fn against a staged copy of the registry and commits
+// all of its changes at once, so readers never observe a partial update
+// and listeners are notified once per changed kind. Nothing is committed
+// if fn panics. fn must only use the registry it is given; calling r
+// from fn deadlocks.
+func (r *Registry) BatchUpdate(fn func(reg *Registry)) {
+	_ = r.batch(func(stage *Registry) error {
+		fn(stage)
+		return nil
+	})
+}
+
+// batch runs fn against a staged copy of the registry and commits its
+// changes at once unless fn fails or panics
+func (r *Registry) batch(fn func(stage *Registry) error) error {
+	r.mu.Lock()
+	stage := &Registry{
+		entries:    make(map[registryKey]*registryEntry, len(r.entries)),
+		tombstones: make(map[registryKey]*registryEntry, len(r.tombstones)),
+		now:        r.now,
+		seq:        r.seq,
+		staged:     true,
+	}
+	for key, entry := range r.entries {
+		stage.entries[key] = entry
+	}
+	for key, entry := range r.tombstones {
+		stage.tombstones[key] = entry
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			r.mu.Unlock()
+		}
+	}()
+	if err := fn(stage); err != nil {
+		return err
+	}
+
+	stage.mu.Lock()
+	r.entries = stage.entries
+	r.tombstones = stage.tombstones
+	r.seq = stage.seq
+	r.appendChangelog(stage.changelog...)
+	changed := []RegistryKind{}
+	for _, change := range stage.changelog {
+		if !slices.Contains(changed, change.Kind) {
+			changed = append(changed, change.Kind)
+		}
+	}
+	stage.mu.Unlock()
+	committed = true
+	r.mu.Unlock()
+
+	for _, kind := range changed {
+		r.notify(kind)
+	}
+	return nil
+}
+
+// Tool returns the active tool with the given name
+func (r *Registry) Tool(name string) (mcp.ToolDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[registryKey{RegistryKindTool, name}]
+	if !ok {
+		return mcp.ToolDefinition{}, false
+	}
+	return entry.value.(mcp.ToolDefinition), true
+}
+
+// RemovedTool returns the tombstoned definition of a removed tool
+func (r *Registry) RemovedTool(name string) (mcp.ToolDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.tombstones[registryKey{RegistryKindTool, name}]
+	if !ok {
+		return mcp.ToolDefinition{}, false
+	}
+	return entry.value.(mcp.ToolDefinition), true
+}
+
+// Tools returns all active tools sorted by name
+func (r *Registry) Tools() []mcp.ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := []mcp.ToolDefinition{}
+	for _, key := range r.sortedKeys(RegistryKindTool) {
+		tools = append(tools, r.entries[key].value.(mcp.ToolDefinition))
+	}
+	return tools
+}
+
+// Resource returns the active resource with the given URI
+func (r *Registry) Resource(uri string) (mcp.Resource, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[registryKey{RegistryKindResource, uri}]
+	if !ok {
+		return mcp.Resource{}, false
+	}
+	return entry.value.(mcp.Resource), true
+}
+
+// Resources returns all active resources sorted by URI
+func (r *Registry) Resources() []mcp.Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resources := []mcp.Resource{}
+	for _, key := range r.sortedKeys(RegistryKindResource) {
+		resources = append(resources, r.entries[key].value.(mcp.Resource))
+	}
+	return resources
+}
+
+// ResourceTemplates returns all active resource templates sorted by URI
+// template
+func (r *Registry) ResourceTemplates() []mcp.ResourceTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	templates := []mcp.ResourceTemplate{}
+	for _, key := range r.sortedKeys(RegistryKindResourceTemplate) {
+		templates = append(templates, r.entries[key].value.(mcp.ResourceTemplate))
+	}
+	return templates
+}
+
+// Prompts returns all active prompts sorted by name
+func (r *Registry) Prompts() []mcp.Prompt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	prompts := []mcp.Prompt{}
+	for _, key := range r.sortedKeys(RegistryKindPrompt) {
+		prompts = append(prompts, r.entries[key].value.(mcp.Prompt))
+	}
+	return prompts
+}
+
+// Changelog returns the last changes recorded, up to 1000, oldest first
+func (r *Registry) Changelog() []RegistryChange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	changelog := make([]RegistryChange, len(r.changelog))
+	copy(changelog, r.changelog)
+	return changelog
+}
+
+// ChangelogSince returns the changes recorded after t
+func (r *Registry) ChangelogSince(t time.Time) []RegistryChange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	changelog := []RegistryChange{}
+	for _, change := range r.changelog {
+		if change.Time.After(t) {
+			changelog = append(changelog, change)
+		}
+	}
+	return changelog
+}
+
+func (r *Registry) add(key registryKey, entry *registryEntry) RegistryChange {
+	change := RegistryChange{
+		Kind:    key.kind,
+		Name:    key.name,
+		Type:    RegistryChangeAdded,
+		Current: marshalDefinition(entry.value),
+	}
+
+	if prev, ok := r.entries[key]; ok {
+		entry.seq = prev.seq
+		change.Type = RegistryChangeUpdated
+		change.Previous = marshalDefinition(prev.value)
+		change.SchemaDrift = schemaDrift(prev.value, entry.value)
+	} else if prev, ok := r.tombstones[key]; ok {
+		change.Previous = marshalDefinition(prev.value)
+		change.SchemaDrift = schemaDrift(prev.value, entry.value)
+		delete(r.tombstones, key)
+	}
+	if entry.seq == 0 {
+		r.seq++
+		entry.seq = r.seq
+	}
+
+	r.entries[key] = entry
+	return r.record(change)
+}
+
+func (r *Registry) remove(key registryKey) bool {
+	entry, ok := r.entries[key]
+	if !ok {
+		return false
+	}
+	delete(r.entries, key)
+	r.tombstones[key] = entry
+	r.record(RegistryChange{
+		Kind:     key.kind,
+		Name:     key.name,
+		Type:     RegistryChangeRemoved,
+		Previous: marshalDefinition(entry.value),
+	})
+	return true
+}
+
+func (r *Registry) restore(key registryKey) bool {
+	entry, ok := r.tombstones[key]
+	if !ok {
+		return false
+	}
+	if _, ok := r.entries[key]; ok {
+		return false
+	}
+	delete(r.tombstones, key)
+	r.entries[key] = entry
+	r.record(RegistryChange{
+		Kind:    key.kind,
+		Name:    key.name,
+		Type:    RegistryChangeRestored,
+		Current: marshalDefinition(entry.value),
+	})
+	return true
+}
+
+// update runs fn holding the lock and notifies the listeners of kind when
+// fn reports a change, so that an entry and its handler change together
+func (r *Registry) update(kind RegistryKind, fn func() bool) bool {
+	r.mu.Lock()
+	changed := fn()
+	r.mu.Unlock()
+	if changed {
+		r.notify(kind)
+	}
+	return changed
+}
+
+// entry returns the active entry of key
+func (r *Registry) entry(key registryKey) (*registryEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[key]
+	return entry, ok
+}
+
+// entriesBySeq returns the active entries of kind in the order they were
+// first added
+func (r *Registry) entriesBySeq(kind RegistryKind) []*registryEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := []*registryEntry{}
+	for key, entry := range r.entries {
+		if key.kind == kind {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	return entries
+}
+
+// notify calls the listeners registered with OnListChanged. It must be
+// called without holding the lock.
+func (r *Registry) notify(kind RegistryKind) {
+	if r.staged {
+		return
+	}
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(kind)
+	}
+}
+
+func (r *Registry) record(change RegistryChange) RegistryChange {
+	change.Time = r.now()
+	r.appendChangelog(change)
+	return change
+}
+
+// appendChangelog records changes, dropping the oldest beyond maxChangelog
+func (r *Registry) appendChangelog(changes ...RegistryChange) {
+	r.changelog = append(r.changelog, changes...)
+	if over := len(r.changelog) - maxChangelog; over > 0 {
+		r.changelog = slices.Delete(r.changelog, 0, over)
+	}
+}
+
+func (r *Registry) sortedKeys(kind RegistryKind) []registryKey {
+	keys := []registryKey{}
+	for key := range r.entries {
+		if key.kind == kind {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].name < keys[j].name
+	})
+	return keys
+}
+
+func marshalDefinition(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// schemaDrift reports whether the part of a definition that clients depend on
+// changed. For tools that is the input schema; descriptions may change freely.
+func schemaDrift(prev, next any) bool {
+	switch p := prev.(type) {
+	case mcp.ToolDefinition:
+		n, ok := next.(mcp.ToolDefinition)
+		if !ok {
+			return true
+		}
+		return string(marshalDefinition(p.InputSchema)) !=
+			string(marshalDefinition(n.InputSchema))
+	}
+	return string(marshalDefinition(prev)) != string(marshalDefinition(next))
+}
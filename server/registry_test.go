@@ -0,0 +1,161 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTool(name string, props ...string) mcp.ToolDefinition {
+	properties := mcp.ToolInputSchemaProperties{}
+	for _, p := range props {
+		properties[p] = map[string]interface{}{"type": "number"}
+	}
+	return mcp.ToolDefinition{
+		Name:        name,
+		Description: name + " tool",
+		InputSchema: mcp.ToolSchema{
+			Type:       "object",
+			Properties: properties,
+		},
+	}
+}
+
+func TestRegistry_AddRemoveRestore(t *testing.T) {
+	r := NewRegistry()
+
+	change := r.AddTool(testTool("add", "a", "b"))
+	assert.Equal(t, RegistryChangeAdded, change.Type)
+	assert.False(t, change.SchemaDrift)
+
+	r.AddTool(testTool("subtract", "a", "b"))
+	tools := r.Tools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "add", tools[0].Name)
+	assert.Equal(t, "subtract", tools[1].Name)
+
+	assert.True(t, r.RemoveTool("add"))
+	assert.False(t, r.RemoveTool("add"))
+	_, ok := r.Tool("add")
+	assert.False(t, ok)
+
+	removed, ok := r.RemovedTool("add")
+	assert.True(t, ok)
+	assert.Equal(t, "add", removed.Name)
+
+	assert.True(t, r.RestoreTool("add"))
+	assert.False(t, r.RestoreTool("add"))
+	_, ok = r.Tool("add")
+	assert.True(t, ok)
+	_, ok = r.RemovedTool("add")
+	assert.False(t, ok)
+
+	changelog := r.Changelog()
+	require.Len(t, changelog, 4)
+	assert.Equal(t, RegistryChangeAdded, changelog[0].Type)
+	assert.Equal(t, RegistryChangeAdded, changelog[1].Type)
+	assert.Equal(t, RegistryChangeRemoved, changelog[2].Type)
+	assert.Equal(t, RegistryChangeRestored, changelog[3].Type)
+	assert.Equal(t, RegistryKindTool, changelog[3].Kind)
+}
+
+func TestRegistry_SchemaDrift(t *testing.T) {
+	r := NewRegistry()
+	r.AddTool(testTool("add", "a", "b"))
+	r.RemoveTool("add")
+
+	// Same schema, different description is not drift
+	same := testTool("add", "a", "b")
+	same.Description = "adds numbers"
+	change := r.AddTool(same)
+	assert.Equal(t, RegistryChangeAdded, change.Type)
+	assert.False(t, change.SchemaDrift)
+	assert.NotEmpty(t, change.Previous)
+
+	r.RemoveTool("add")
+	change = r.AddTool(testTool("add", "a", "b", "c"))
+	assert.True(t, change.SchemaDrift)
+	assert.Contains(t, string(change.Previous), `"b"`)
+	assert.Contains(t, string(change.Current), `"c"`)
+
+	// Replacing an active tool is recorded as an update
+	change = r.AddTool(testTool("add", "x"))
+	assert.Equal(t, RegistryChangeUpdated, change.Type)
+	assert.True(t, change.SchemaDrift)
+}
+
+func TestRegistry_ChangelogSince(t *testing.T) {
+	r := NewRegistry()
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	r.AddTool(testTool("add"))
+	first := r.Changelog()[0].Time
+
+	r.RemoveTool("add")
+	since := r.ChangelogSince(first)
+	require.Len(t, since, 1)
+	assert.Equal(t, RegistryChangeRemoved, since[0].Type)
+}
+
+func TestRegistry_BatchUpdate(t *testing.T) {
+	r := NewRegistry()
+	r.AddTool(testTool("add", "a", "b"))
+
+	var notified []RegistryKind
+	r.OnListChanged(func(kind RegistryKind) {
+		notified = append(notified, kind)
+	})
+
+	r.BatchUpdate(func(reg *Registry) {
+		reg.AddTool(testTool("subtract", "a", "b"))
+		reg.AddTool(testTool("multiply", "a", "b"))
+		reg.RemoveTool("add")
+
+		// The live registry is untouched until the batch commits
+		assert.Len(t, reg.Tools(), 2)
+	})
+
+	assert.Equal(t, []RegistryKind{RegistryKindTool}, notified)
+	tools := r.Tools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "multiply", tools[0].Name)
+	assert.Equal(t, "subtract", tools[1].Name)
+	_, ok := r.RemovedTool("add")
+	assert.True(t, ok)
+	assert.Len(t, r.Changelog(), 4)
+
+	// A batch without changes notifies nobody
+	r.BatchUpdate(func(reg *Registry) {})
+	assert.Len(t, notified, 1)
+
+	// A panicking batch commits nothing
+	assert.Panics(t, func() {
+		r.BatchUpdate(func(reg *Registry) {
+			reg.RemoveTool("multiply")
+			panic("boom")
+		})
+	})
+	_, ok = r.Tool("multiply")
+	assert.True(t, ok)
+	assert.Len(t, notified, 1)
+
+	r.RemoveTool("multiply")
+	assert.Len(t, notified, 2)
+}
+
+func TestRegistry_ChangelogLimit(t *testing.T) {
+	r := NewRegistry()
+	for range maxChangelog + 10 {
+		r.AddTool(testTool("add", "a"))
+	}
+	changelog := r.Changelog()
+	require.Len(t, changelog, maxChangelog)
+	assert.Equal(t, RegistryChangeUpdated, changelog[0].Type)
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultReplayWindow is how long a session waits for its client to
+// reconnect when no window is configured
+const defaultReplayWindow = 30 * time.Second
+
+// EventReplayConfig configures how SSE sessions survive dropped streams
+type EventReplayConfig struct {
+	// Size is how many of the latest events each session keeps
+	Size int
+	// Window is how long a session whose stream dropped waits for its
+	// client to reconnect. It defaults to 30 seconds.
+	Window time.Duration
+}
+
+// WithEventReplay keeps SSE sessions whose stream drops for config.Window.
+// A client reconnecting with the sessionId query parameter or the
+// Mcp-Session-Id header gets its session back, and with a Last-Event-ID
+// header the kept events it has not seen are written again before the
+// events sent while it was away.
+func WithEventReplay(config EventReplayConfig) SSEOption {
+	return func(s *SSEServer) {
+		if config.Window <= 0 {
+			config.Window = defaultReplayWindow
+		}
+		s.replay = config
+	}
+}
+
+type replayedEvent struct {
+	id   uint64
+	data []byte
+}
+
+// eventReplay keeps the latest events of a session. Only the stream
+// handler touches it.
+type eventReplay struct {
+	events []replayedEvent
+	size   int
+}
+
+func newEventReplay(size int) *eventReplay {
+	return &eventReplay{size: size}
+}
+
+func (r *eventReplay) add(id uint64, data []byte) {
+	if len(r.events) == r.size {
+		r.events = append(r.events[:0], r.events[1:]...)
+	}
+	r.events = append(r.events, replayedEvent{id: id, data: data})
+}
+
+// resumableSession returns the session a reconnecting client asks for
+func (s *SSEServer) resumableSession(r *http.Request) (*sseSession, bool) {
+	sessionID := r.URL.Query().Get("sessionId")
+	if sessionID == "" {
+		sessionID = r.Header.Get("Mcp-Session-Id")
+	}
+	if sessionID == "" {
+		return nil, false
+	}
+	session, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return nil, false
+	}
+	return session.(*sseSession), true
+}
+
+// attach makes w the stream of the session, taking over from the stream
+// attached before. The returned channel is closed when another stream
+// takes over, and release must be called once w is no longer written.
+func (s *sseSession) attach(w http.ResponseWriter, flusher *http.ResponseController) (<-chan struct{}, func()) {
+	s.attachMu.Lock()
+	defer s.attachMu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		<-s.released
+	}
+	s.writer = w
+	s.flusher = flusher
+	s.generation++
+	stop := make(chan struct{})
+	released := make(chan struct{})
+	s.stop = stop
+	s.released = released
+	return stop, func() { close(released) }
+}
+
+// replayAfter writes the kept events that follow lastEventID
+func (s *sseSession) replayAfter(lastEventID string) error {
+	if lastEventID == "" {
+		return nil
+	}
+	after, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for _, event := range s.replay.events {
+		if event.id <= after {
+			continue
+		}
+		if err := s.writeData(event.id, event.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expire ends the session unless its client reconnects within window
+func (s *sseSession) expire(window time.Duration) {
+	s.attachMu.Lock()
+	generation := s.generation
+	s.attachMu.Unlock()
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-s.done:
+	}
+
+	s.attachMu.Lock()
+	defer s.attachMu.Unlock()
+	if s.generation == generation {
+		s.close()
+		s.finish()
+	}
+}
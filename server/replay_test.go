@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseStream is a raw SSE stream, read one event at a time
+type sseStream struct {
+	reader *bufio.Reader
+	cancel context.CancelFunc
+}
+
+func openSSEStream(t *testing.T, url string, header http.Header) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+	})
+	return &sseStream{reader: bufio.NewReader(resp.Body), cancel: cancel}
+}
+
+// next returns the fields of the next event
+func (s *sseStream) next(t *testing.T) map[string]string {
+	t.Helper()
+	event := map[string]string{}
+	for {
+		line, err := s.reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return event
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		event[field] = value
+	}
+}
+
+func TestSSEServerEventIDs(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+	t.Cleanup(testServer.Close)
+
+	stream := openSSEStream(t, testServer.URL+"/sse", nil)
+	endpoint := stream.next(t)
+	assert.Empty(t, endpoint["id"])
+	sessionID := strings.Split(endpoint["data"], "sessionId=")[1]
+
+	for range 2 {
+		require.NoError(t, sseServer.SendEventToSession(sessionID, notification{JSONRPC: "2.0", Method: "notifications/test"}))
+	}
+	assert.Equal(t, "1", stream.next(t)["id"])
+	assert.Equal(t, "2", stream.next(t)["id"])
+}
+
+func TestSSEServerEventReplay(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	sseServer, testServer := NewTestServer(mcpServer, WithEventReplay(EventReplayConfig{Size: 10}))
+	t.Cleanup(testServer.Close)
+
+	stream := openSSEStream(t, testServer.URL+"/sse", nil)
+	sessionID := strings.Split(stream.next(t)["data"], "sessionId=")[1]
+	send := func(method string) {
+		require.NoError(t, sseServer.SendEventToSession(sessionID, notification{JSONRPC: "2.0", Method: method}))
+	}
+	send("notifications/one")
+	send("notifications/two")
+	assert.Equal(t, "1", stream.next(t)["id"])
+	assert.Equal(t, "2", stream.next(t)["id"])
+
+	// The client saw only the first event before its stream dropped
+	stream.cancel()
+	send("notifications/three")
+
+	header := http.Header{}
+	header.Set("Mcp-Session-Id", sessionID)
+	header.Set("Last-Event-ID", "1")
+	resumed := openSSEStream(t, testServer.URL+"/sse", header)
+	assert.Equal(t, sessionID, strings.Split(resumed.next(t)["data"], "sessionId=")[1])
+
+	event := resumed.next(t)
+	assert.Equal(t, "2", event["id"])
+	assert.Contains(t, event["data"], "notifications/two")
+	event = resumed.next(t)
+	assert.Equal(t, "3", event["id"])
+	assert.Contains(t, event["data"], "notifications/three")
+
+	// The session still answers requests
+	sendJSONRPCRequest(t, testServer.URL, sessionID, JSONRPCRequest{JSONRPC: "2.0", ID: "1", Method: "ping"})
+	event = resumed.next(t)
+	assert.Equal(t, "4", event["id"])
+	assert.Contains(t, event["data"], `"result":{}`)
+}
+
+func TestSSEServerEventReplayExpires(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	_, testServer := NewTestServer(mcpServer, WithEventReplay(EventReplayConfig{Size: 10, Window: 20 * time.Millisecond}))
+	t.Cleanup(testServer.Close)
+
+	stream := openSSEStream(t, testServer.URL+"/sse", nil)
+	sessionID := strings.Split(stream.next(t)["data"], "sessionId=")[1]
+	stream.cancel()
+
+	require.Eventually(t, func() bool {
+		_, ok := mcpServer.session(sessionID)
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	resumed := openSSEStream(t, testServer.URL+"/sse?sessionId="+sessionID, nil)
+	assert.NotEqual(t, sessionID, strings.Split(resumed.next(t)["data"], "sessionId=")[1])
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ResourceHandlerFunc reads a resource registered with AddResource
+type ResourceHandlerFunc func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error)
+
+// ResourceTemplateHandlerFunc reads a resource matching a template
+// registered with AddResourceTemplate. params holds the values of the
+// template's variables extracted from the requested URI.
+type ResourceTemplateHandlerFunc func(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+	params map[string]string,
+) (*mcp.ReadResourceResult, error)
+
+type resourceTemplate struct {
+	definition mcp.ResourceTemplate
+	template   *mcp.URITemplate
+	handler    ResourceTemplateHandlerFunc
+}
+
+// AddResource registers a resource together with the function that reads
+// it. The resource is listed by resources/list and reads of its URI go to
+// handler instead of the function set with HandleReadResource. Contents
+// that leave their MIME type empty get the one of the resource. Adding a
+// resource with the URI of an existing one replaces it.
+func (s *DefaultServer) AddResource(resource mcp.Resource, handler ResourceHandlerFunc) {
+	s.registry.update(RegistryKindResource, func() bool {
+		addResource(s.registry, resource, handler)
+		return true
+	})
+}
+
+// RemoveResource unregisters a resource added with AddResource. It reports
+// whether the resource was registered.
+func (s *DefaultServer) RemoveResource(uri string) bool {
+	return s.registry.update(RegistryKindResource, func() bool {
+		return s.registry.remove(registryKey{RegistryKindResource, uri})
+	})
+}
+
+// AddResourceTemplate registers a resource template together with the
+// function that reads resources matching it, such as "users://{id}/profile".
+// The template is listed by resources/templates/list. Reads of URIs that
+// no resource added with AddResource claims are matched against the
+// templates in the order they were added. Adding a template with the URI
+// template of an existing one replaces it.
+func (s *DefaultServer) AddResourceTemplate(
+	template mcp.ResourceTemplate,
+	handler ResourceTemplateHandlerFunc,
+) error {
+	entry, err := newResourceTemplate(template, handler)
+	if err != nil {
+		return err
+	}
+	s.registry.update(RegistryKindResourceTemplate, func() bool {
+		addResourceTemplate(s.registry, entry)
+		return true
+	})
+	return nil
+}
+
+// RemoveResourceTemplate unregisters a resource template added with
+// AddResourceTemplate. It reports whether the template was registered.
+func (s *DefaultServer) RemoveResourceTemplate(uriTemplate string) bool {
+	return s.registry.update(RegistryKindResourceTemplate, func() bool {
+		return s.registry.remove(registryKey{RegistryKindResourceTemplate, uriTemplate})
+	})
+}
+
+// newResourceTemplate parses the URI template of template
+func newResourceTemplate(template mcp.ResourceTemplate, handler ResourceTemplateHandlerFunc) (*resourceTemplate, error) {
+	parsed, err := mcp.ParseURITemplate(template.UriTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add resource template: %w", err)
+	}
+	return &resourceTemplate{definition: template, template: parsed, handler: handler}, nil
+}
+
+// addResource adds a resource and its handler to r, which the caller holds
+func addResource(r *Registry, resource mcp.Resource, handler ResourceHandlerFunc) {
+	r.add(registryKey{RegistryKindResource, resource.Uri}, &registryEntry{value: resource, handler: handler})
+}
+
+// addResourceTemplate adds a parsed template to r, which the caller holds
+func addResourceTemplate(r *Registry, template *resourceTemplate) {
+	r.add(
+		registryKey{RegistryKindResourceTemplate, template.definition.UriTemplate},
+		&registryEntry{value: template.definition, handler: template},
+	)
+}
+
+// readRegisteredResource reads a resource added to the session of the
+// request or with AddResource, or one matching a template added with
+// AddResourceTemplate. ok is false when none claims uri.
+func (s *DefaultServer) readRegisteredResource(
+	ctx context.Context,
+	uri string,
+) (result *mcp.ReadResourceResult, ok bool, err error) {
+	request := mcp.ReadResourceRequest{
+		Method: mcp.MethodResourcesRead,
+		Params: mcp.ReadResourceRequestParams{Uri: uri},
+	}
+
+	var mimeType string
+	if scoped, found := clientSessionFromContext(ctx).resource(uri); found {
+		ok = true
+		mimeType = scoped.resource.MimeType
+		result, err = scoped.handler(ctx, request)
+	} else if handler, resource, found := s.registeredResource(uri); found {
+		ok = true
+		mimeType = resource.MimeType
+		result, err = handler(ctx, request)
+	} else {
+		for _, t := range s.registeredTemplates() {
+			params, matched := t.template.Match(uri)
+			if !matched {
+				continue
+			}
+			ok = true
+			mimeType = t.definition.MimeType
+			result, err = t.handler(ctx, request, params)
+			break
+		}
+	}
+	if !ok || err != nil || result == nil {
+		return result, ok, err
+	}
+
+	for i, contents := range result.Contents {
+		result.Contents[i] = withMimeType(contents, mimeType)
+	}
+	return result, true, nil
+}
+
+// registeredResource returns the handler of a resource added with
+// AddResource and its definition
+func (s *DefaultServer) registeredResource(uri string) (ResourceHandlerFunc, mcp.Resource, bool) {
+	entry, ok := s.registry.entry(registryKey{RegistryKindResource, uri})
+	if !ok {
+		return nil, mcp.Resource{}, false
+	}
+	handler, ok := entry.handler.(ResourceHandlerFunc)
+	return handler, entry.value.(mcp.Resource), ok
+}
+
+// registeredTemplates returns the templates added with
+// AddResourceTemplate in the order they were added
+func (s *DefaultServer) registeredTemplates() []*resourceTemplate {
+	templates := []*resourceTemplate{}
+	for _, entry := range s.registry.entriesBySeq(RegistryKindResourceTemplate) {
+		if template, ok := entry.handler.(*resourceTemplate); ok {
+			templates = append(templates, template)
+		}
+	}
+	return templates
+}
+
+// withMimeType sets the MIME type of resource contents that have none
+func withMimeType(contents any, mimeType string) any {
+	switch c := contents.(type) {
+	case mcp.TextResourceContents:
+		if c.MimeType == "" {
+			c.MimeType = mimeType
+		}
+		return c
+	case mcp.BlobResourceContents:
+		if c.MimeType == "" {
+			c.MimeType = mimeType
+		}
+		return c
+	case *mcp.TextResourceContents:
+		if c.MimeType == "" {
+			c.MimeType = mimeType
+		}
+	case *mcp.BlobResourceContents:
+		if c.MimeType == "" {
+			c.MimeType = mimeType
+		}
+	case *ResourceStream:
+		if c.MimeType == "" {
+			c.MimeType = mimeType
+		}
+	}
+	return contents
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readResource(t *testing.T, s MCPServer, uri string) JSONRPCResponse {
+	t.Helper()
+	params, err := json.Marshal(map[string]string{"uri": uri})
+	require.NoError(t, err)
+	return s.Request(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "resources/read",
+		Params:  params,
+	})
+}
+
+func TestDefaultServer_AddResource(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+
+	s.AddResource(mcp.Resource{
+		Uri:      "file:///notes.md",
+		Name:     "notes",
+		MimeType: "text/markdown",
+	}, func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []interface{}{mcp.TextResourceContents{
+				Uri:  request.Params.Uri,
+				Text: "# Notes",
+			}},
+		}, nil
+	})
+	s.AddResource(mcp.Resource{
+		Uri:  "file:///logo.png",
+		Name: "logo",
+	}, func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []interface{}{mcp.BlobResourceContents{
+				Uri:      request.Params.Uri,
+				Blob:     "iVBORw0KGgo=",
+				MimeType: "image/png",
+			}},
+		}, nil
+	})
+
+	list := s.Request(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "resources/list",
+	})
+	require.Nil(t, list.Error)
+	resources := list.Result.(*mcp.ListResourcesResult).Resources
+	require.Len(t, resources, 2)
+	assert.Equal(t, "file:///logo.png", resources[0].Uri)
+	assert.Equal(t, "file:///notes.md", resources[1].Uri)
+
+	notes := readResource(t, s, "file:///notes.md")
+	require.Nil(t, notes.Error)
+	text := notes.Result.(*mcp.ReadResourceResult).Contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "# Notes", text.Text)
+	assert.Equal(t, "text/markdown", text.MimeType)
+
+	logo := readResource(t, s, "file:///logo.png")
+	require.Nil(t, logo.Error)
+	blob := logo.Result.(*mcp.ReadResourceResult).Contents[0].(mcp.BlobResourceContents)
+	assert.Equal(t, "image/png", blob.MimeType)
+
+	// Unregistered URIs still go to the read handler
+	unknown := readResource(t, s, "file:///unknown")
+	require.Nil(t, unknown.Error)
+	assert.Empty(t, unknown.Result.(*mcp.ReadResourceResult).Contents)
+
+	assert.True(t, s.RemoveResource("file:///notes.md"))
+	assert.False(t, s.RemoveResource("file:///notes.md"))
+	notes = readResource(t, s, "file:///notes.md")
+	require.Nil(t, notes.Error)
+	assert.Empty(t, notes.Result.(*mcp.ReadResourceResult).Contents)
+}
+
+func TestDefaultServer_AddResourceTemplate(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+
+	s.AddResource(mcp.Resource{
+		Uri:  "users://me/profile",
+		Name: "my profile",
+	}, func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []interface{}{mcp.TextResourceContents{Uri: request.Params.Uri, Text: "me"}},
+		}, nil
+	})
+	require.NoError(t, s.AddResourceTemplate(mcp.ResourceTemplate{
+		UriTemplate: "users://{id}/profile",
+		Name:        "user profile",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, request mcp.ReadResourceRequest, params map[string]string) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []interface{}{mcp.TextResourceContents{
+				Uri:  request.Params.Uri,
+				Text: `{"id":"` + params["id"] + `"}`,
+			}},
+		}, nil
+	}))
+	assert.Error(t, s.AddResourceTemplate(mcp.ResourceTemplate{UriTemplate: "users://{id"}, nil))
+
+	list := s.Request(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "resources/templates/list",
+	})
+	require.Nil(t, list.Error)
+	templates := list.Result.(*mcp.ListResourceTemplatesResult).ResourceTemplates
+	require.Len(t, templates, 1)
+	assert.Equal(t, "users://{id}/profile", templates[0].UriTemplate)
+
+	profile := readResource(t, s, "users://42/profile")
+	require.Nil(t, profile.Error)
+	text := profile.Result.(*mcp.ReadResourceResult).Contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, `{"id":"42"}`, text.Text)
+	assert.Equal(t, "application/json", text.MimeType)
+
+	// Resources take precedence over templates
+	me := readResource(t, s, "users://me/profile")
+	require.Nil(t, me.Error)
+	assert.Equal(t, "me", me.Result.(*mcp.ReadResourceResult).Contents[0].(mcp.TextResourceContents).Text)
+
+	assert.True(t, s.RemoveResourceTemplate("users://{id}/profile"))
+	profile = readResource(t, s, "users://42/profile")
+	require.Nil(t, profile.Error)
+	assert.Empty(t, profile.Result.(*mcp.ReadResourceResult).Contents)
+}
+
+func TestDefaultServer_MixedResourceContents(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	s.AddResource(mcp.Resource{Uri: "file:///bundle", Name: "bundle", MimeType: "application/octet-stream"},
+		func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []interface{}{
+				mcp.NewTextResourceContents(request.Params.Uri, "text/plain", "manifest"),
+				mcp.NewBlobResourceContents(request.Params.Uri, "", []byte{0x00, 0x01}),
+			}}, nil
+		})
+
+	response := readResource(t, s, "file:///bundle")
+	require.Nil(t, response.Error)
+	contents := response.Result.(*mcp.ReadResourceResult).Contents
+	require.Len(t, contents, 2)
+	assert.Equal(t, mcp.NewTextResourceContents("file:///bundle", "text/plain", "manifest"), contents[0])
+	blob := contents[1].(mcp.BlobResourceContents)
+	assert.Equal(t, "application/octet-stream", blob.MimeType)
+	data, err := blob.Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x01}, data)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ErrRootsNotSupported is returned when the client did not declare the
+// roots capability
+var ErrRootsNotSupported = errors.New("client does not support roots")
+
+// ListRoots returns the roots of the client of the request being handled.
+// Clients that announce changes to their roots are asked once and their
+// roots are cached until they send notifications/roots/list_changed; other
+// clients are asked every time.
+func ListRoots(ctx context.Context) ([]mcp.Root, error) {
+	session := clientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoClientSession
+	}
+
+	session.mu.Lock()
+	capability := session.clientCapabilities.Roots
+	roots, cached := session.roots, session.rootsCached
+	generation := session.rootsGeneration
+	session.mu.Unlock()
+	if capability == nil {
+		return nil, ErrRootsNotSupported
+	}
+	if cached {
+		return slices.Clone(roots), nil
+	}
+
+	data, err := session.request(ctx, mcp.MethodRootsList, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.ListRootsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal roots: %w", err)
+	}
+
+	// Roots that changed while they were being listed are not cached
+	session.mu.Lock()
+	if capability.ListChanged && generation == session.rootsGeneration {
+		session.roots, session.rootsCached = result.Roots, true
+	}
+	session.mu.Unlock()
+	return slices.Clone(result.Roots), nil
+}
+
+// invalidateRoots drops the cached roots of the client
+func (c *clientSession) invalidateRoots() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roots, c.rootsCached = nil, false
+	c.rootsGeneration++
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRoots(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+
+	var asked atomic.Int32
+	client := func(sessionID string) sendFunc {
+		return func(message any) error {
+			request, ok := message.(serverRequest)
+			if !ok || request.Method != "roots/list" {
+				return nil
+			}
+			n := asked.Add(1)
+			go s.handleClientResponse(sessionID, json.RawMessage(fmt.Sprintf(
+				`{"jsonrpc":"2.0","id":%d,"result":{"roots":[{"uri":"file:///project%d","name":"project"}]}}`,
+				request.ID, n,
+			)))
+			return nil
+		}
+	}
+	initialize := func(sessionID, capabilities string) context.Context {
+		s.registerSession(sessionID, client(sessionID))
+		ctx := withSessionID(context.Background(), sessionID)
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params: json.RawMessage(`{
+				"capabilities": ` + capabilities + `,
+				"clientInfo": {"name": "test-client", "version": "1.0.0"},
+				"protocolVersion": "2024-11-05"
+			}`),
+		})
+		require.Nil(t, response.Error)
+		return ctx
+	}
+	var roots []mcp.Root
+	s.AddTool(mcp.ToolDefinition{Name: "roots", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var err error
+			roots, err = ListRoots(ctx)
+			return &mcp.CallToolResult{}, err
+		})
+	listRoots := func(ctx context.Context) []mcp.Root {
+		t.Helper()
+		response := callTool(t, s, ctx, `{"name":"roots"}`)
+		require.Nil(t, response.Error)
+		return roots
+	}
+
+	// Roots are cached until the client says they changed
+	ctx := initialize("a", `{"roots": {"listChanged": true}}`)
+	assert.Equal(t, []mcp.Root{{Uri: "file:///project1", Name: "project"}}, listRoots(ctx))
+	assert.Equal(t, []mcp.Root{{Uri: "file:///project1", Name: "project"}}, listRoots(ctx))
+	assert.Equal(t, int32(1), asked.Load())
+	s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", Method: "notifications/roots/list_changed"})
+	assert.Equal(t, []mcp.Root{{Uri: "file:///project2", Name: "project"}}, listRoots(ctx))
+	assert.Equal(t, int32(2), asked.Load())
+
+	// Clients that do not announce changes are asked every time
+	ctx = initialize("b", `{"roots": {}}`)
+	listRoots(ctx)
+	listRoots(ctx)
+	assert.Equal(t, int32(4), asked.Load())
+
+	ctx = initialize("c", `{}`)
+	response := callTool(t, s, ctx, `{"name":"roots"}`)
+	assert.Equal(t, ErrRootsNotSupported.Error(), toolError(t, response))
+
+	_, err := ListRoots(context.Background())
+	assert.ErrorIs(t, err, ErrNoClientSession)
+}
+
+func TestListRootsSessionClosed(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	asked := make(chan struct{})
+	s.registerSession("a", func(message any) error {
+		if _, ok := message.(serverRequest); ok {
+			close(asked)
+		}
+		return nil
+	})
+	ctx := withSessionID(context.Background(), "a")
+	response := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {"roots": {}},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, response.Error)
+
+	s.sessions.mu.RLock()
+	session := s.sessions.sessions["a"]
+	s.sessions.mu.RUnlock()
+
+	// The client never answers, a request without a deadline ends with the session
+	result := make(chan error, 1)
+	go func() {
+		_, err := ListRoots(withClientSession(ctx, session))
+		result <- err
+	}()
+	select {
+	case <-asked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("client was not asked for its roots")
+	}
+	s.unregisterSession("a")
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, errSessionClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("request outlived its session")
+	}
+
+	session.mu.Lock()
+	assert.Empty(t, session.pending)
+	session.mu.Unlock()
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ErrSamplingNotSupported is returned when the client did not declare the
+// sampling capability
+var ErrSamplingNotSupported = errors.New("client does not support sampling")
+
+// RequestSampling asks the client of the request being handled to sample a
+// message from its model, and blocks until it answers or ctx ends. It can
+// only be called from handlers of requests that arrived through a
+// transport.
+func RequestSampling(
+	ctx context.Context,
+	request mcp.CreateMessageRequest,
+) (*mcp.CreateMessageResult, error) {
+	session := clientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoClientSession
+	}
+	if !session.supportsSampling() {
+		return nil, ErrSamplingNotSupported
+	}
+
+	data, err := session.request(ctx, mcp.MethodSamplingCreateMessage, request.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.CreateMessageResult
+	if err := mcp.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sampling result: %w", err)
+	}
+	return &result, nil
+}
+
+// ClientSampling is a SamplingFunc that samples through the client of the
+// request being handled, for use with NewPromptChain
+func ClientSampling(
+	ctx context.Context,
+	params mcp.CreateMessageRequestParams,
+) (*mcp.CreateMessageResult, error) {
+	return RequestSampling(ctx, mcp.CreateMessageRequest{
+		Method: mcp.MethodSamplingCreateMessage,
+		Params: params,
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postMessage(t *testing.T, serverURL, sessionID, body string) {
+	t.Helper()
+	resp, err := http.Post(
+		fmt.Sprintf("%s/message?sessionId=%s", serverURL, sessionID),
+		"application/json",
+		strings.NewReader(body),
+	)
+	// Called from goroutines too, so no require
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestRequestSampling(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "summarize", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := RequestSampling(ctx, mcp.CreateMessageRequest{
+				Method: "sampling/createMessage",
+				Params: mcp.CreateMessageRequestParams{
+					MaxTokens: 10,
+					Messages: []mcp.SamplingMessage{{
+						Role:    mcp.RoleUser,
+						Content: mcp.TextContent{Type: "text", Text: "summarize this"},
+					}},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{
+				Content: []interface{}{mcp.TextContent{
+					Type: "text",
+					Text: result.Model + ": " + result.Content.(mcp.TextContent).Text,
+				}},
+			}, nil
+		})
+
+	initialize := func(sessionID string, messages chan string, capabilities string) {
+		postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"capabilities": `+capabilities+`,
+			"protocolVersion": "2024-11-05"
+		}}`)
+		nextMessage(t, messages)
+	}
+
+	sessionID, messages := connectSSE(t, testServer.URL)
+	initialize(sessionID, messages, `{"sampling": {}}`)
+
+	go postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"summarize"}}`)
+	sampling := nextMessage(t, messages)
+	assert.Equal(t, "sampling/createMessage", sampling["method"])
+	assert.Equal(t, float64(10), sampling["params"].(map[string]any)["maxTokens"])
+
+	id, err := json.Marshal(sampling["id"])
+	require.NoError(t, err)
+	postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","id":`+string(id)+`,"result":{
+		"role": "assistant",
+		"model": "test-model",
+		"content": {"type": "text", "text": "short"}
+	}}`)
+	result := nextMessage(t, messages)
+	assert.Equal(t, float64(2), result["id"])
+	content := result["result"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	assert.Equal(t, "test-model: short", content["text"])
+
+	// Clients that did not declare sampling are not asked
+	otherID, otherMessages := connectSSE(t, testServer.URL)
+	initialize(otherID, otherMessages, `{}`)
+	postMessage(t, testServer.URL, otherID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"summarize"}}`)
+	result = nextMessage(t, otherMessages)
+	toolResult := result["result"].(map[string]any)
+	assert.Equal(t, true, toolResult["isError"])
+	assert.Contains(t, toolResult["content"].([]any)[0].(map[string]any)["text"], ErrSamplingNotSupported.Error())
+
+	// Nor can a request that did not come through a transport
+	direct := callTool(t, mcpServer, context.Background(), `{"name":"summarize"}`)
+	assert.Contains(t, toolError(t, direct), ErrNoClientSession.Error())
+}
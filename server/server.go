@@ -3,8 +3,14 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/huangyul/go-mcp/mcp"
 )
@@ -23,16 +29,28 @@ type JSONRPCResponse struct {
 	Error   *JSONRPCError `json:"error,omitempty"`
 }
 
-type JSONRPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+// notification is a JSON-RPC message that expects no response
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
 }
 
+// isNotification reports whether request is a notification, which
+// carries no ID and must not be answered
+func isNotification(request JSONRPCRequest) bool {
+	return request.ID == nil
+}
+
+// JSONRPCError is the error object of a JSONRPCResponse
+type JSONRPCError = mcp.JSONRPCErrorError
+
 type MCPServer interface {
 	Request(ctx context.Context, request JSONRPCRequest) JSONRPCResponse
 	HandleInitialize(InitializeFunc)
 	HandlePing(PingFunc)
 	HandleListResources(ListResourcesFunc)
+	HandleListResourceTemplates(ListResourceTemplatesFunc)
 	HandleReadResource(ReadResourceFunc)
 	HandleSubscribe(SubscribeFunc)
 	HandleUnsubscribe(UnsubscribeFunc)
@@ -43,13 +61,32 @@ type MCPServer interface {
 	HandleSetLevel(SetLevelFunc)
 	HandleComplete(CompleteFunc)
 	HandleNotification(string, NotificationFunc)
+	AddTool(mcp.ToolDefinition, ToolHandlerFunc)
+	RemoveTool(string) bool
+	DeleteTools(...string)
+	ReplaceTool(mcp.ToolDefinition, ToolHandlerFunc) bool
+	UseToolMiddleware(ToolMiddleware, ...string)
+	AddResource(mcp.Resource, ResourceHandlerFunc)
+	AddStreamResource(mcp.Resource, StreamResourceHandlerFunc)
+	RemoveResource(string) bool
+	AddResourceTemplate(mcp.ResourceTemplate, ResourceTemplateHandlerFunc) error
+	RemoveResourceTemplate(string) bool
+	AddPrompt(mcp.Prompt, PromptHandlerFunc)
+	RemovePrompt(string) bool
+	BatchUpdate(func(*Batch)) error
+	NotifyResourceUpdated(string) error
+	BroadcastLog(mcp.LoggingLevel, string, any) error
+	AddPromptCompletion(string, string, CompletionProvider)
+	AddResourceCompletion(string, string, CompletionProvider)
 }
 
 type InitializeFunc func(ctx context.Context, capabilities mcp.ClientCapabilities, clientInfo mcp.Implementation, protocolVersion string) (*mcp.InitializeResult, error)
 
 type PingFunc func(ctx context.Context) error
 
-type ListResourcesFunc func(ctx context.Context, cursor *string) (*mcp.ListResourcesResult, error)
+type ListResourcesFunc func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListResourcesResult, error)
+
+type ListResourceTemplatesFunc func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListResourceTemplatesResult, error)
 
 type ReadResourceFunc func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
 
@@ -57,11 +94,11 @@ type SubscribeFunc func(ctx context.Context, uri string) error
 
 type UnsubscribeFunc func(ctx context.Context, uri string) error
 
-type ListPromptsFunc func(ctx context.Context, cursor *string) (*mcp.ListPromptsResult, error)
+type ListPromptsFunc func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListPromptsResult, error)
 
 type GetPromptFunc func(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error)
 
-type ListToolsFunc func(ctx context.Context, cursor *string) (*mcp.ListToolsResult, error)
+type ListToolsFunc func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolDefinitionsResult, error)
 
 type CallToolFunc func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error)
 
@@ -75,15 +112,89 @@ type DefaultServer struct {
 	handlers map[string]interface{}
 	name     string
 	version  string
+	pool     *workerPool
+	limiter  *rateLimiter
+	// auditSink receives a record of audited requests, see WithAuditSink
+	auditSink AuditSink
+	tracer    Tracer
+	// sessionPing is set by WithSessionPing
+	sessionPing *SessionPingConfig
+	logger      *slog.Logger
+	inflight    sync.Map
+
+	// tools, resources and resource templates registered with AddTool,
+	// AddResource and AddResourceTemplate, together with their handlers
+	registry *Registry
+	// middlewareMu guards the tool middleware
+	middlewareMu   sync.RWMutex
+	toolMiddleware []toolMiddleware
+	// pageSize limits the registered items per list page, 0 for no limit
+	pageSize  int
+	hooks     []Hooks
+	validator SchemaValidator
+
+	// connected clients, registered by the transports
+	sessions sessions
+
+	completions completions
+
+	diagnostics      bool
+	emptyCollections mcp.EmptyCollections
+	instructions     string
+	// protocolVersions the server speaks, newest first
+	protocolVersions   []string
+	sequentialSessions bool
+	started            time.Time
+	requests           atomic.Int64
+	failures           atomic.Int64
+}
+
+// ServerOption configures a DefaultServer
+type ServerOption func(*DefaultServer)
+
+// WithWorkerPool runs request handlers on a dedicated pool of workers
+// instead of the transport's goroutines. Pings skip the pool so liveness
+// checks are answered even when every worker is busy. The workers run
+// until Shutdown.
+func WithWorkerPool(config WorkerPoolConfig) ServerOption {
+	return func(s *DefaultServer) {
+		s.pool = newWorkerPool(config)
+	}
+}
+
+// WithInstructions sets the instructions returned by initialize, which
+// hosts use to tell the model how to use the server. They are added to
+// the result of the initialize handler unless it set its own.
+func WithInstructions(instructions string) ServerOption {
+	return func(s *DefaultServer) {
+		s.instructions = instructions
+	}
+}
+
+// WithEmptyCollections sets how empty collections in results are encoded.
+// By default, mcp.EmptyAsNull, results are sent as handlers return them.
+// Other policies apply to a copy of each result, never the handler's value.
+func WithEmptyCollections(policy mcp.EmptyCollections) ServerOption {
+	return func(s *DefaultServer) {
+		s.emptyCollections = policy
+	}
 }
 
 // NewDefaultServer creates a new server with default handlers
-func NewDefaultServer(name, version string) MCPServer {
+func NewDefaultServer(name, version string, opts ...ServerOption) MCPServer {
 	s := &DefaultServer{
-		handlers: make(map[string]interface{}),
-		name:     name,
-		version:  version,
+		handlers:  make(map[string]interface{}),
+		name:      name,
+		version:   version,
+		started:   time.Now(),
+		registry:  NewRegistry(),
+		validator: basicValidator{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.registry.OnListChanged(s.notifyListChanged)
 
 	// Register default initialize handler
 	s.HandleInitialize(s.defaultInitialize)
@@ -91,6 +202,7 @@ func NewDefaultServer(name, version string) MCPServer {
 	// Register default handlers for other methods
 	s.HandlePing(s.defaultPing)
 	s.HandleListResources(s.defaultListResources)
+	s.HandleListResourceTemplates(s.defaultListResourceTemplates)
 	s.HandleReadResource(s.defaultReadResource)
 	s.HandleSubscribe(s.defaultSubscribe)
 	s.HandleUnsubscribe(s.defaultUnsubscribe)
@@ -104,12 +216,60 @@ func NewDefaultServer(name, version string) MCPServer {
 	return s
 }
 
-func (s *DefaultServer) Request(ctx context.Context, request JSONRPCRequest) JSONRPCResponse {
-	resp, err := s.handleRequest(ctx, request.Method, request.Params)
+func (s *DefaultServer) Request(ctx context.Context, request JSONRPCRequest) (response JSONRPCResponse) {
+	// Transports may already have tagged the request for their own logs
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID == "" {
+		correlationID = requestCorrelationID(request.Params)
+		ctx = withCorrelationID(ctx, correlationID)
+	}
+
+	if !strings.HasPrefix(request.Method, "notifications/") {
+		var done func()
+		ctx, done = s.trackRequest(ctx, request.ID)
+		defer done()
+	}
+	if session, ok := s.session(sessionIDFromContext(ctx)); ok {
+		ctx = withClientSession(ctx, session)
+	}
+	ctx, span := s.startRequestSpan(ctx, request)
+	defer func() { span.End(responseError(response)) }()
+
+	s.runHooks(func(h Hooks) {
+		if h.OnRequest != nil {
+			h.OnRequest(ctx, request)
+		}
+	})
+	defer s.runHooks(func(h Hooks) {
+		if h.OnResponse != nil {
+			h.OnResponse(ctx, request, response)
+		}
+	})
+
+	s.requests.Add(1)
+	done := inOrder(ctx, request)
+	started := time.Now()
+	resp, err := s.dispatch(ctx, request)
+	done()
+	// Whatever a cancelled handler produced is partial, drop it
+	if errors.Is(context.Cause(ctx), errRequestCancelled) {
+		resp, err = nil, errRequestCancelled
+	}
+	s.audit(ctx, request, started, resp, err)
 	if err != nil {
-		errorCode := -32603
-		if err.Error() == fmt.Sprintf("mehtod not found: %s", request.Method) {
-			errorCode = -32601
+		s.failures.Add(1)
+		s.log(ctx, slog.LevelWarn, "request failed", request.Method,
+			slog.Duration("duration", time.Since(started)),
+			slog.String("error", err.Error()))
+		s.runHooks(func(h Hooks) {
+			if h.OnError != nil {
+				h.OnError(ctx, request, err)
+			}
+		})
+		errorCode, errorData := errorCode(err)
+		data := map[string]any{correlationIDMetaKey: correlationID}
+		for key, value := range errorData {
+			data[key] = value
 		}
 		return JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -117,9 +277,30 @@ func (s *DefaultServer) Request(ctx context.Context, request JSONRPCRequest) JSO
 			Error: &JSONRPCError{
 				Code:    errorCode,
 				Message: err.Error(),
+				Data:    data,
 			},
 		}
 	}
+	resp = withResultMeta(resp, correlationIDMetaKey, correlationID)
+	if resp != nil && s.emptyCollections != mcp.EmptyAsNull {
+		resp, err = mcp.NormalizeEmpty(resp, s.emptyCollections)
+		if err != nil {
+			s.runHooks(func(h Hooks) {
+				if h.OnError != nil {
+					h.OnError(ctx, request, err)
+				}
+			})
+			return JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      request.ID,
+				Error: &JSONRPCError{
+					Code:    mcp.InternalError,
+					Message: err.Error(),
+					Data:    map[string]any{correlationIDMetaKey: correlationID},
+				},
+			}
+		}
+	}
 	return JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      request.ID,
@@ -127,6 +308,30 @@ func (s *DefaultServer) Request(ctx context.Context, request JSONRPCRequest) JSO
 	}
 }
 
+// dispatch runs the request handler once the rate limiter lets it through,
+// on the worker pool when one is configured
+func (s *DefaultServer) dispatch(
+	ctx context.Context,
+	request JSONRPCRequest,
+) (interface{}, error) {
+	if err := s.limiter.allow(sessionIDFromContext(ctx), request.Method); err != nil {
+		return nil, err
+	}
+	if s.pool == nil || request.Method == mcp.MethodPing ||
+		strings.HasPrefix(request.Method, "notifications/") {
+		return s.safeHandleRequest(ctx, request.Method, request.Params)
+	}
+
+	var resp interface{}
+	var err error
+	if poolErr := s.pool.submit(ctx, request.Method, func(ctx context.Context) {
+		resp, err = s.safeHandleRequest(ctx, request.Method, request.Params)
+	}); poolErr != nil {
+		return nil, poolErr
+	}
+	return resp, err
+}
+
 // Request is the main entrypoint of the server
 func (s *DefaultServer) handleRequest(
 	ctx context.Context,
@@ -137,9 +342,19 @@ func (s *DefaultServer) handleRequest(
 	if params == nil {
 		params = json.RawMessage("{}")
 	}
+	// A malformed _meta is ignored rather than failing the request
+	if meta, err := mcp.MetaOf(params); err == nil && meta != nil {
+		ctx = withRequestMeta(ctx, meta)
+	}
 
 	// Handle notifications
 	if strings.Contains(method, "notifications") {
+		if method == mcp.MethodNotificationCancelled {
+			s.cancelRequest(ctx, params)
+		}
+		if method == mcp.MethodNotificationRootsListChanged {
+			clientSessionFromContext(ctx).invalidateRoots()
+		}
 		if s.handlers[method] == nil {
 			return nil, nil
 		}
@@ -150,182 +365,313 @@ func (s *DefaultServer) handleRequest(
 	// Handle all other methods
 	_, ok := s.handlers[method]
 	if !ok {
-		return nil, fmt.Errorf("method not found: %s", method)
+		return nil, methodNotFound(method)
+	}
+	if token, ok := progressToken(params); ok {
+		ctx = withProgressToken(ctx, token)
 	}
 
 	switch method {
-	case "initialize":
+	case mcp.MethodInitialize:
 		var p struct {
 			Capabilities    *mcp.ClientCapabilities `json:"capabilities"`
 			ClientInfo      *mcp.Implementation     `json:"clientInfo"`
 			ProtocolVersion string                  `json:"protocolVersion"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.ClientInfo == nil {
-			return nil, fmt.Errorf("missing required field: clientInfo")
+			return nil, invalidParams("missing required field: clientInfo")
 		}
 		if p.Capabilities == nil {
-			return nil, fmt.Errorf("missing required field: capabilities")
+			return nil, invalidParams("missing required field: capabilities")
 		}
 		if p.ProtocolVersion == "" {
-			return nil, fmt.Errorf("missing required field: protocolVersion")
+			return nil, invalidParams("missing required field: protocolVersion")
 		}
-		return s.handlers["initialize"].(InitializeFunc)(
+		result, err := s.handlers[mcp.MethodInitialize].(InitializeFunc)(
 			ctx,
 			*p.Capabilities,
 			*p.ClientInfo,
 			p.ProtocolVersion,
 		)
-
-	case "ping":
+		if err == nil && result != nil {
+			// Handlers may return a shared result, fill in a copy. Capabilities
+			// is a value, its members are replaced rather than changed.
+			copied := *result
+			result = &copied
+		}
+		if err == nil && result != nil && result.ProtocolVersion == "" {
+			result.ProtocolVersion = s.negotiateProtocolVersion(p.ProtocolVersion)
+		}
+		if err == nil && result != nil && result.Instructions == "" {
+			result.Instructions = s.instructions
+		}
+		if err == nil && result != nil && s.hasTools(ctx) && result.Capabilities.Tools == nil {
+			result.Capabilities.Tools = &mcp.ServerCapabilitiesTools{ListChanged: true}
+		}
+		if err == nil && result != nil && len(s.registry.Prompts()) > 0 && result.Capabilities.Prompts == nil {
+			result.Capabilities.Prompts = &mcp.ServerCapabilitiesPrompts{ListChanged: true}
+		}
+		if err == nil && result != nil {
+			var declared struct {
+				Capabilities struct {
+					Elicitation json.RawMessage `json:"elicitation"`
+				} `json:"capabilities"`
+			}
+			_ = json.Unmarshal(params, &declared)
+			s.initializeSession(ctx, sessionInit{
+				clientInfo:         *p.ClientInfo,
+				clientCapabilities: *p.Capabilities,
+				elicitation:        declared.Capabilities.Elicitation != nil,
+				capabilities:       result.Capabilities,
+				protocolVersion:    result.ProtocolVersion,
+			})
+		}
+		return result, err
+
+	case mcp.MethodPing:
 		if len(params) > 0 && string(params) != "null" &&
 			string(params) != "{}" {
-			return nil, fmt.Errorf("ping method does not accept parameters")
+			return nil, invalidParams("ping method does not accept parameters")
+		}
+		return struct{}{}, s.handlers[mcp.MethodPing].(PingFunc)(ctx)
+
+	case mcp.MethodResourcesList:
+		var p struct {
+			Cursor mcp.Cursor `json:"cursor,omitempty"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams("failed to parse parameters: %v", err)
+		}
+		resources := clientSessionFromContext(ctx).scopedResources(s.registry.Resources())
+		page, next, ok, err := ownPage(p.Cursor, resources, s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &mcp.ListResourcesResult{Resources: page, NextCursor: string(next)}, nil
 		}
-		return struct{}{}, s.handlers["ping"].(PingFunc)(ctx)
+		result, err := s.handlers[mcp.MethodResourcesList].(ListResourcesFunc)(ctx, p.Cursor)
+		// Registered resources follow the last page
+		if err == nil && result != nil && result.NextCursor == "" {
+			page, next := ownPageAt(resources, 0, s.pageSize)
+			merged := *result
+			merged.Resources = slices.Concat(result.Resources, page)
+			merged.NextCursor = string(next)
+			return &merged, nil
+		}
+		return result, err
 
-	case "resources/list":
+	case mcp.MethodResourcesTemplatesList:
 		var p struct {
-			Cursor *string `json:"cursor,omitempty"`
+			Cursor mcp.Cursor `json:"cursor,omitempty"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
+		}
+		templates := s.registry.ResourceTemplates()
+		page, next, ok, err := ownPage(p.Cursor, templates, s.pageSize)
+		if err != nil {
+			return nil, err
 		}
-		return s.handlers["resources/list"].(ListResourcesFunc)(ctx, p.Cursor)
+		if ok {
+			return &mcp.ListResourceTemplatesResult{ResourceTemplates: page, NextCursor: string(next)}, nil
+		}
+		result, err := s.handlers[mcp.MethodResourcesTemplatesList].(ListResourceTemplatesFunc)(ctx, p.Cursor)
+		// Registered templates follow the last page
+		if err == nil && result != nil && result.NextCursor == "" {
+			page, next := ownPageAt(templates, 0, s.pageSize)
+			merged := *result
+			merged.ResourceTemplates = slices.Concat(result.ResourceTemplates, page)
+			merged.NextCursor = string(next)
+			return &merged, nil
+		}
+		return result, err
 
-	case "resources/read":
+	case mcp.MethodResourcesRead:
 		var p struct {
 			URI string `json:"uri"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.URI == "" {
-			return nil, fmt.Errorf("uri is required")
+			return nil, invalidParams("uri is required")
+		}
+		if result, ok, err := s.readRegisteredResource(ctx, p.URI); ok {
+			return result, err
 		}
-		return s.handlers["resources/read"].(ReadResourceFunc)(ctx, p.URI)
+		return s.handlers[mcp.MethodResourcesRead].(ReadResourceFunc)(ctx, p.URI)
 
-	case "resources/subscribe":
+	case mcp.MethodResourcesSubscribe:
 		var p struct {
 			URI string `json:"uri"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.URI == "" {
-			return nil, fmt.Errorf("uri is required")
+			return nil, invalidParams("uri is required")
+		}
+		err := s.handlers[mcp.MethodResourcesSubscribe].(SubscribeFunc)(ctx, p.URI)
+		if err == nil {
+			s.setSubscribed(ctx, p.URI, true)
 		}
-		err := s.handlers["resources/subscribe"].(SubscribeFunc)(ctx, p.URI)
 		return struct{}{}, err
 
-	case "resources/unsubscribe":
+	case mcp.MethodResourcesUnsubscribe:
 		var p struct {
 			URI string `json:"uri"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.URI == "" {
-			return nil, fmt.Errorf("uri is required")
+			return nil, invalidParams("uri is required")
+		}
+		err := s.handlers[mcp.MethodResourcesUnsubscribe].(UnsubscribeFunc)(ctx, p.URI)
+		if err == nil {
+			s.setSubscribed(ctx, p.URI, false)
 		}
-		err := s.handlers["resources/unsubscribe"].(UnsubscribeFunc)(ctx, p.URI)
 		return struct{}{}, err
 
-	case "prompts/list":
+	case mcp.MethodPromptsList:
 		var p struct {
-			Cursor *string `json:"cursor,omitempty"`
+			Cursor mcp.Cursor `json:"cursor,omitempty"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
-		return s.handlers["prompts/list"].(ListPromptsFunc)(ctx, p.Cursor)
+		prompts := s.registry.Prompts()
+		page, next, ok, err := ownPage(p.Cursor, prompts, s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &mcp.ListPromptsResult{Prompts: page, NextCursor: string(next)}, nil
+		}
+		result, err := s.handlers[mcp.MethodPromptsList].(ListPromptsFunc)(ctx, p.Cursor)
+		// Registered prompts follow the last page
+		if err == nil && result != nil && result.NextCursor == "" {
+			page, next := ownPageAt(prompts, 0, s.pageSize)
+			merged := *result
+			merged.Prompts = slices.Concat(result.Prompts, page)
+			merged.NextCursor = string(next)
+			return &merged, nil
+		}
+		return result, err
 
-	case "prompts/get":
+	case mcp.MethodPromptsGet:
 		var p struct {
 			Name      string            `json:"name"`
 			Arguments map[string]string `json:"arguments,omitempty"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.Name == "" {
-			return nil, fmt.Errorf("name is required")
+			return nil, invalidParams("name is required")
 		}
-		return s.handlers["prompts/get"].(GetPromptFunc)(
-			ctx,
-			p.Name,
-			p.Arguments,
-		)
+		return s.getPrompt(ctx, p.Name, p.Arguments)
 
-	case "tools/list":
+	case mcp.MethodToolsList:
 		var p struct {
-			Cursor *string `json:"cursor,omitempty"`
+			Cursor mcp.Cursor `json:"cursor,omitempty"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
-		return s.handlers["tools/list"].(ListToolsFunc)(ctx, p.Cursor)
+		tools := clientSessionFromContext(ctx).scopedTools(s.registry.Tools())
+		if s.diagnostics {
+			tools = append(tools, diagnosticTools()...)
+		}
+		page, next, ok, err := ownPage(p.Cursor, tools, s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &mcp.ListToolDefinitionsResult{Tools: page, NextCursor: next}, nil
+		}
+		result, err := s.handlers[mcp.MethodToolsList].(ListToolsFunc)(ctx, p.Cursor)
+		// Registered and diagnostic tools follow the last page
+		if err == nil && result != nil && result.NextCursor == "" {
+			page, next := ownPageAt(tools, 0, s.pageSize)
+			merged := *result
+			merged.Tools = slices.Concat(result.Tools, page)
+			merged.NextCursor = next
+			return &merged, nil
+		}
+		return result, err
 
-	case "tools/call":
+	case mcp.MethodToolsCall:
 		var p struct {
 			Name      string                 `json:"name"`
 			Arguments map[string]interface{} `json:"arguments,omitempty"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.Name == "" {
-			return nil, fmt.Errorf("name is required")
+			return nil, invalidParams("name is required")
+		}
+		request := mcp.CallToolRequest{
+			Method: mcp.MethodToolsCall,
+			Params: mcp.CallToolRequestParams{
+				Name:      p.Name,
+				Arguments: p.Arguments,
+			},
+		}
+		result, err := s.callTool(ctx, request)
+		s.runHooks(func(h Hooks) {
+			if h.OnToolCall != nil {
+				h.OnToolCall(ctx, request, result, err)
+			}
+		})
+		if err != nil && !isProtocolError(err) {
+			return &mcp.CallToolResult{
+				Content: []interface{}{mcp.TextContent{Type: "text", Text: err.Error()}},
+				IsError: true,
+			}, nil
 		}
-		return s.handlers["tools/call"].(CallToolFunc)(ctx, p.Name, p.Arguments)
+		return result, err
 
-	case "logging/setLevel":
+	case mcp.MethodLoggingSetLevel:
 		var p struct {
 			Level mcp.LoggingLevel `json:"level"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
-		}
-		// Validate logging level
-		valid := false
-		for _, l := range []mcp.LoggingLevel{
-			mcp.LoggingLevelEmergency,
-			mcp.LoggingLevelAlert,
-			mcp.LoggingLevelCritical,
-			mcp.LoggingLevelError,
-			mcp.LoggingLevelWarning,
-			mcp.LoggingLevelNotice,
-			mcp.LoggingLevelInfo,
-			mcp.LoggingLevelDebug,
-		} {
-			if p.Level == l {
-				valid = true
-				break
-			}
+			return nil, invalidParams("failed to parse parameters: %v", err)
+		}
+		if severity(p.Level) < 0 {
+			return nil, invalidParams("invalid logging level: %s", p.Level)
 		}
-		if !valid {
-			return nil, fmt.Errorf("invalid logging level: %s", p.Level)
+		err := s.handlers[mcp.MethodLoggingSetLevel].(SetLevelFunc)(ctx, p.Level)
+		if session := clientSessionFromContext(ctx); err == nil && session != nil {
+			session.setMinLogLevel(p.Level)
 		}
-		err := s.handlers["logging/setLevel"].(SetLevelFunc)(ctx, p.Level)
 		return struct{}{}, err
 
-	case "completion/complete":
+	case mcp.MethodCompletionComplete:
 		var p struct {
 			Ref      interface{}                       `json:"ref"`
 			Argument mcp.CompleteRequestParamsArgument `json:"argument"`
 		}
 		if err := json.Unmarshal(params, &p); err != nil {
-			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+			return nil, invalidParams("failed to parse parameters: %v", err)
 		}
 		if p.Ref == nil {
-			return nil, fmt.Errorf("ref is required")
+			return nil, invalidParams("ref is required")
 		}
 		if p.Argument.Name == "" {
-			return nil, fmt.Errorf("argument name is required")
+			return nil, invalidParams("argument name is required")
 		}
-		return s.handlers["completion/complete"].(CompleteFunc)(
+		ref, _ := json.Marshal(p.Ref)
+		if result, ok, err := s.completions.complete(ctx, ref, p.Argument); ok {
+			return result, err
+		}
+		return s.handlers[mcp.MethodCompletionComplete].(CompleteFunc)(
 			ctx,
 			p.Ref,
 			p.Argument,
@@ -339,73 +685,79 @@ func (s *DefaultServer) handleRequest(
 func (s *DefaultServer) HandleInitialize(
 	f InitializeFunc,
 ) {
-	s.handlers["initialize"] = f
+	s.handlers[mcp.MethodInitialize] = f
 }
 
 func (s *DefaultServer) HandlePing(
 	f PingFunc,
 ) {
-	s.handlers["ping"] = f
+	s.handlers[mcp.MethodPing] = f
 }
 
 func (s *DefaultServer) HandleListResources(
 	f ListResourcesFunc,
 ) {
-	s.handlers["resources/list"] = f
+	s.handlers[mcp.MethodResourcesList] = f
+}
+
+func (s *DefaultServer) HandleListResourceTemplates(
+	f ListResourceTemplatesFunc,
+) {
+	s.handlers[mcp.MethodResourcesTemplatesList] = f
 }
 
 func (s *DefaultServer) HandleReadResource(
 	f ReadResourceFunc,
 ) {
-	s.handlers["resources/read"] = f
+	s.handlers[mcp.MethodResourcesRead] = f
 }
 
 func (s *DefaultServer) HandleSubscribe(
 	f SubscribeFunc,
 ) {
-	s.handlers["resources/subscribe"] = f
+	s.handlers[mcp.MethodResourcesSubscribe] = f
 }
 
 func (s *DefaultServer) HandleUnsubscribe(
 	f UnsubscribeFunc,
 ) {
-	s.handlers["resources/unsubscribe"] = f
+	s.handlers[mcp.MethodResourcesUnsubscribe] = f
 }
 
 func (s *DefaultServer) HandleListPrompts(
 	f ListPromptsFunc,
 ) {
-	s.handlers["prompts/list"] = f
+	s.handlers[mcp.MethodPromptsList] = f
 }
 
 func (s *DefaultServer) HandleGetPrompt(
 	f GetPromptFunc,
 ) {
-	s.handlers["prompts/get"] = f
+	s.handlers[mcp.MethodPromptsGet] = f
 }
 
 func (s *DefaultServer) HandleListTools(
 	f ListToolsFunc,
 ) {
-	s.handlers["tools/list"] = f
+	s.handlers[mcp.MethodToolsList] = f
 }
 
 func (s *DefaultServer) HandleCallTool(
 	f CallToolFunc,
 ) {
-	s.handlers["tools/call"] = f
+	s.handlers[mcp.MethodToolsCall] = f
 }
 
 func (s *DefaultServer) HandleSetLevel(
 	f SetLevelFunc,
 ) {
-	s.handlers["logging/setLevel"] = f
+	s.handlers[mcp.MethodLoggingSetLevel] = f
 }
 
 func (s *DefaultServer) HandleComplete(
 	f CompleteFunc,
 ) {
-	s.handlers["completion/complete"] = f
+	s.handlers[mcp.MethodCompletionComplete] = f
 }
 
 func (s *DefaultServer) HandleNotification(
@@ -427,9 +779,12 @@ func (s *DefaultServer) defaultInitialize(
 			Name:    s.name,
 			Version: s.version,
 		},
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: s.negotiateProtocolVersion(protocolVersion),
 		Capabilities: mcp.ServerCapabilities{
-			Resources: &mcp.ServerCapabilitiesResources{},
+			Resources: &mcp.ServerCapabilitiesResources{
+				Subscribe:   true,
+				ListChanged: true,
+			},
 		},
 	}, nil
 }
@@ -440,13 +795,22 @@ func (s *DefaultServer) defaultPing(ctx context.Context) error {
 
 func (s *DefaultServer) defaultListResources(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListResourcesResult, error) {
 	return &mcp.ListResourcesResult{
 		Resources: []mcp.Resource{},
 	}, nil
 }
 
+func (s *DefaultServer) defaultListResourceTemplates(
+	ctx context.Context,
+	cursor mcp.Cursor,
+) (*mcp.ListResourceTemplatesResult, error) {
+	return &mcp.ListResourceTemplatesResult{
+		ResourceTemplates: []mcp.ResourceTemplate{},
+	}, nil
+}
+
 func (s *DefaultServer) defaultReadResource(
 	ctx context.Context,
 	uri string,
@@ -472,7 +836,7 @@ func (s *DefaultServer) defaultUnsubscribe(
 
 func (s *DefaultServer) defaultListPrompts(
 	ctx context.Context,
-	cursor *string,
+	cursor mcp.Cursor,
 ) (*mcp.ListPromptsResult, error) {
 	return &mcp.ListPromptsResult{
 		Prompts: []mcp.Prompt{},
@@ -491,10 +855,10 @@ func (s *DefaultServer) defaultGetPrompt(
 
 func (s *DefaultServer) defaultListTools(
 	ctx context.Context,
-	cursor *string,
-) (*mcp.ListToolsResult, error) {
-	return &mcp.ListToolsResult{
-		Tools: []mcp.Tool{},
+	cursor mcp.Cursor,
+) (*mcp.ListToolDefinitionsResult, error) {
+	return &mcp.ListToolDefinitionsResult{
+		Tools: []mcp.ToolDefinition{},
 	}, nil
 }
 
@@ -1,12 +1,16 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"testing"
 
 	"github.com/huangyul/go-mcp/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDefaultServer(t *testing.T) {
@@ -74,7 +78,7 @@ func TestDefaultServer_Request(t *testing.T) {
 				JSONRPC: "2.0",
 				ID:      5,
 				Method:  "invalid",
-				Params:  json.RawMessage(`{}`),
+				Params:  json.RawMessage(`{"_meta":{"correlationId":"test-correlation"}}`),
 			},
 			expectedError: JSONRPCResponse{
 				JSONRPC: "2.0",
@@ -82,6 +86,7 @@ func TestDefaultServer_Request(t *testing.T) {
 				Error: &JSONRPCError{
 					Code:    -32601,
 					Message: "method not found: invalid",
+					Data:    map[string]any{"correlationId": "test-correlation"},
 				},
 			},
 		},
@@ -188,3 +193,204 @@ func getMethodName(handlerName string) string {
 		return ""
 	}
 }
+
+func TestDefaultServer_CorrelationID(t *testing.T) {
+	var logs bytes.Buffer
+	s := NewDefaultServer("test", "1.0.0", WithLogger(log.New(&logs, "", 0)))
+	ctx := context.Background()
+
+	var handlerID string
+	s.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			handlerID = CorrelationIDFromContext(ctx)
+			if name == "fail" {
+				return nil, fmt.Errorf("%w: tool failed", ErrInvalidParams)
+			}
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+
+	// Adopted from the request _meta and returned in the result _meta
+	result := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"ok","_meta":{"correlationId":"abc"}}`),
+	})
+	require.Nil(t, result.Error)
+	assert.Equal(t, "abc", handlerID)
+	callResult, ok := result.Result.(*mcp.CallToolResult)
+	require.True(t, ok)
+	assert.Equal(t, "abc", callResult.Meta["correlationId"])
+
+	// Generated when absent and attached to the error and log line
+	result = s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"fail"}`),
+	})
+	require.NotNil(t, result.Error)
+	assert.NotEmpty(t, handlerID)
+	assert.NotEqual(t, "abc", handlerID)
+	assert.Equal(t, map[string]any{"correlationId": handlerID}, result.Error.Data)
+	assert.Contains(t, logs.String(), `msg="request failed" method=tools/call correlationId=`+handlerID)
+	assert.Contains(t, logs.String(), `error="invalid params: tool failed"`)
+}
+
+func TestDefaultServer_CorrelationIDSharedResult(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	ctx := context.Background()
+
+	// A handler may hand out the same result to every request
+	shared := &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent("static")}}
+	s.HandleCallTool(
+		func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			return shared, nil
+		},
+	)
+
+	call := func(id int, correlationID string) *mcp.CallToolResult {
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      id,
+			Method:  "tools/call",
+			Params:  json.RawMessage(`{"name":"static","_meta":{"correlationId":"` + correlationID + `"}}`),
+		})
+		require.Nil(t, response.Error)
+		return response.Result.(*mcp.CallToolResult)
+	}
+	first, second := call(1, "first"), call(2, "second")
+	assert.Equal(t, "first", first.Meta["correlationId"])
+	assert.Equal(t, "second", second.Meta["correlationId"])
+	assert.Equal(t, shared.Content, second.Content)
+	assert.Nil(t, shared.Meta)
+}
+
+func TestDefaultServer_EmptyCollections(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []ServerOption
+		expected string
+	}{
+		{name: "Default", expected: `{"tools":null}`},
+		{
+			name:     "Empty",
+			opts:     []ServerOption{WithEmptyCollections(mcp.EmptyAsEmpty)},
+			expected: `{"tools":[]}`,
+		},
+		{
+			name:     "Omitted",
+			opts:     []ServerOption{WithEmptyCollections(mcp.EmptyOmitted)},
+			expected: `{"tools":[]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDefaultServer("test", "1.0.0", tc.opts...)
+			s.HandleListTools(
+				func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolDefinitionsResult, error) {
+					return &mcp.ListToolDefinitionsResult{}, nil
+				},
+			)
+
+			result := s.Request(context.Background(), JSONRPCRequest{
+				JSONRPC: "2.0",
+				ID:      1,
+				Method:  "tools/list",
+				Params:  json.RawMessage(`{"_meta":{"correlationId":"id"}}`),
+			})
+			require.Nil(t, result.Error)
+
+			data, err := json.Marshal(result.Result)
+			require.NoError(t, err)
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			delete(decoded, "_meta")
+			data, err = json.Marshal(decoded)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestDefaultServer_Instructions(t *testing.T) {
+	initialize := func(s MCPServer) *mcp.InitializeResult {
+		response := s.Request(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params: json.RawMessage(`{
+				"capabilities": {},
+				"clientInfo": {"name": "test-client", "version": "1.0.0"},
+				"protocolVersion": "2024-11-05"
+			}`),
+		})
+		require.Nil(t, response.Error)
+		return response.Result.(*mcp.InitializeResult)
+	}
+
+	assert.Empty(t, initialize(NewDefaultServer("test", "1.0.0")).Instructions)
+
+	s := NewDefaultServer("test", "1.0.0", WithInstructions("Search before you fetch."))
+	assert.Equal(t, "Search before you fetch.", initialize(s).Instructions)
+
+	s.HandleInitialize(func(ctx context.Context, capabilities mcp.ClientCapabilities, clientInfo mcp.Implementation, protocolVersion string) (*mcp.InitializeResult, error) {
+		return &mcp.InitializeResult{ProtocolVersion: protocolVersion, Instructions: "Custom."}, nil
+	})
+	assert.Equal(t, "Custom.", initialize(s).Instructions)
+}
+
+func TestDefaultServer_InitializeKeepsHandlerResult(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0", WithInstructions("Search before you fetch."))
+	s.AddTool(mcp.ToolDefinition{Name: "registered"}, nil)
+
+	// A handler may hand out the same result to every client
+	shared := &mcp.InitializeResult{ServerInfo: mcp.Implementation{Name: "test", Version: "1.0.0"}}
+	s.HandleInitialize(func(ctx context.Context, capabilities mcp.ClientCapabilities, clientInfo mcp.Implementation, protocolVersion string) (*mcp.InitializeResult, error) {
+		return shared, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		response := s.Request(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "initialize",
+			Params: json.RawMessage(`{
+				"capabilities": {},
+				"clientInfo": {"name": "test-client", "version": "1.0.0"},
+				"protocolVersion": "2024-11-05"
+			}`),
+		})
+		require.Nil(t, response.Error)
+		result := response.Result.(*mcp.InitializeResult)
+		assert.Equal(t, "2024-11-05", result.ProtocolVersion)
+		assert.Equal(t, "Search before you fetch.", result.Instructions)
+		assert.NotNil(t, result.Capabilities.Tools)
+	}
+	assert.Equal(t, &mcp.InitializeResult{ServerInfo: mcp.Implementation{Name: "test", Version: "1.0.0"}}, shared)
+}
+
+func TestDefaultServer_ListKeepsHandlerResult(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	ctx := context.Background()
+
+	// A handler may hand out the same list to every request
+	shared := &mcp.ListToolDefinitionsResult{
+		Tools: make([]mcp.ToolDefinition, 1, 4),
+	}
+	shared.Tools[0] = mcp.ToolDefinition{Name: "static"}
+	s.HandleListTools(
+		func(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolDefinitionsResult, error) {
+			return shared, nil
+		},
+	)
+	s.AddTool(mcp.ToolDefinition{Name: "registered"}, nil)
+
+	for i := 0; i < 2; i++ {
+		response := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: i, Method: "tools/list"})
+		require.Nil(t, response.Error)
+		assert.Len(t, response.Result.(*mcp.ListToolDefinitionsResult).Tools, 2)
+	}
+	assert.Len(t, shared.Tools, 1)
+	assert.Equal(t, mcp.ToolDefinition{}, shared.Tools[:2][1])
+}
@@ -0,0 +1,371 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// stdioSessionID identifies the single client of a stdio server
+const stdioSessionID = "stdio"
+
+// ErrNoClientSession is returned when a request to the client is made
+// outside of a request that arrived through a transport
+var ErrNoClientSession = errors.New("no client session")
+
+// sendFunc writes a message to a client
+type sendFunc func(message any) error
+
+// sessionTracker is implemented by servers that want to reach connected
+// clients outside of a request. Transports register a session when a
+// client connects and unregister it once the client is gone, and hand
+// over the responses the client sends to requests made by the server.
+type sessionTracker interface {
+	registerSession(sessionID string, send sendFunc)
+	unregisterSession(sessionID string)
+	handleClientResponse(sessionID string, response json.RawMessage)
+}
+
+// sessionOptionsTracker is implemented by session trackers that take
+// what a transport knows about a session besides how to reach it
+type sessionOptionsTracker interface {
+	registerSessionWith(sessionID string, send sendFunc, opts sessionOptions)
+}
+
+// sessionOptions describe a session to its tracker
+type sessionOptions struct {
+	// identity the client authenticated with, if any
+	identity any
+	// close ends the session in the transport, nil if it cannot
+	close func()
+}
+
+// clientSession is a connected client, the resources it subscribed to,
+// the capabilities exchanged when it initialized and the requests the
+// server is waiting on it to answer
+type clientSession struct {
+	id                 string
+	send               sendFunc
+	subscriptions      map[string]bool
+	capabilities       mcp.ServerCapabilities
+	clientCapabilities mcp.ClientCapabilities
+	// elicitation is whether the client declared the elicitation
+	// capability, which ClientCapabilities predates
+	elicitation bool
+	// identity the client authenticated with when it connected
+	identity any
+	// closeTransport ends the session in its transport, if it can
+	closeTransport func()
+	// closed is closed once the session is unregistered
+	closed chan struct{}
+
+	// mu guards the fields below. Capabilities are written holding both mu
+	// and sessions.mu, so either is enough to read them.
+	mu         sync.Mutex
+	pending    map[string]chan clientResponse
+	requestID  int64
+	logLevel   mcp.LoggingLevel
+	clientInfo mcp.Implementation
+	// protocolVersion is the version agreed on in initialize
+	protocolVersion string
+	values          map[string]any
+	tools           map[string]toolEntry
+	resources       map[string]sessionResource
+	// sequential sessions take turns on turn to handle requests
+	sequential bool
+	turn       sync.Mutex
+	// roots the client listed, see ListRoots
+	roots           []mcp.Root
+	rootsCached     bool
+	rootsGeneration int
+}
+
+// clientResponse is the answer of a client to a request of the server
+type clientResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *JSONRPCError   `json:"error,omitempty"`
+}
+
+// serverRequest is a JSON-RPC request from the server to a client
+type serverRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+func (c *clientSession) notify(method string, params any) error {
+	return c.send(notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// request sends a request to the client and waits for its result. The
+// client is told when ctx ends before it answered.
+func (c *clientSession) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	responseCh := make(chan clientResponse, 1)
+	c.mu.Lock()
+	c.requestID++
+	id := c.requestID
+	key := strconv.FormatInt(id, 10)
+	c.pending[key] = responseCh
+	c.mu.Unlock()
+
+	forget := func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}
+
+	err := c.send(serverRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		forget()
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		forget()
+		_ = c.notify(mcp.MethodNotificationCancelled, map[string]any{
+			"requestId": id,
+			"reason":    context.Cause(ctx).Error(),
+		})
+		return nil, ctx.Err()
+	case <-c.closed:
+		forget()
+		return nil, fmt.Errorf("%s request: %w", method, errSessionClosed)
+	case response := <-responseCh:
+		if response.Error != nil {
+			return nil, fmt.Errorf("client returned error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	}
+}
+
+// sessions holds the connected clients of a DefaultServer
+type sessions struct {
+	mu       sync.RWMutex
+	sessions map[string]*clientSession
+}
+
+func (s *DefaultServer) registerSession(sessionID string, send sendFunc) {
+	s.registerSessionWith(sessionID, send, sessionOptions{})
+}
+
+func (s *DefaultServer) registerSessionWith(sessionID string, send sendFunc, opts sessionOptions) {
+	session := &clientSession{
+		id:             sessionID,
+		identity:       opts.identity,
+		closeTransport: opts.close,
+		closed:         make(chan struct{}),
+		send:           send,
+		subscriptions:  make(map[string]bool),
+		pending:        make(map[string]chan clientResponse),
+		sequential:     s.sequentialSessions,
+	}
+	s.sessions.mu.Lock()
+	if s.sessions.sessions == nil {
+		s.sessions.sessions = make(map[string]*clientSession)
+	}
+	s.sessions.sessions[sessionID] = session
+	s.sessions.mu.Unlock()
+
+	s.runHooks(func(h Hooks) {
+		if h.OnSessionStart != nil {
+			h.OnSessionStart(&Session{client: session})
+		}
+	})
+	if s.sessionPing != nil {
+		go s.pingSession(session)
+	}
+}
+
+func (s *DefaultServer) unregisterSession(sessionID string) {
+	s.sessions.mu.Lock()
+	session, ok := s.sessions.sessions[sessionID]
+	delete(s.sessions.sessions, sessionID)
+	s.sessions.mu.Unlock()
+	s.limiter.forget(sessionID)
+
+	if ok {
+		close(session.closed)
+		s.runHooks(func(h Hooks) {
+			if h.OnSessionEnd != nil {
+				h.OnSessionEnd(&Session{client: session})
+			}
+		})
+	}
+}
+
+// handleClientResponse hands a response of the client to the request of
+// the server waiting for it. Responses nobody waits for are dropped.
+func (s *DefaultServer) handleClientResponse(sessionID string, data json.RawMessage) {
+	session, ok := s.session(sessionID)
+	if !ok {
+		return
+	}
+	var response clientResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return
+	}
+
+	session.mu.Lock()
+	responseCh, ok := session.pending[string(response.ID)]
+	delete(session.pending, string(response.ID))
+	session.mu.Unlock()
+	if ok {
+		responseCh <- response
+	}
+}
+
+// supportsSampling reports whether the client declared the sampling
+// capability
+func (c *clientSession) supportsSampling() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clientCapabilities.Sampling != nil
+}
+
+// minLogLevel returns the least severe level the client wants log entries
+// for
+func (c *clientSession) minLogLevel() mcp.LoggingLevel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.logLevel == "" {
+		return mcp.LoggingLevelDebug
+	}
+	return c.logLevel
+}
+
+func (c *clientSession) setMinLogLevel(level mcp.LoggingLevel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logLevel = level
+}
+
+func (s *DefaultServer) session(sessionID string) (*clientSession, bool) {
+	s.sessions.mu.RLock()
+	defer s.sessions.mu.RUnlock()
+	session, ok := s.sessions.sessions[sessionID]
+	return session, ok
+}
+
+// setSubscribed records whether the session of the request is subscribed
+// to uri. Requests that did not come through a session are ignored.
+func (s *DefaultServer) setSubscribed(ctx context.Context, uri string, subscribed bool) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	session, ok := s.sessions.sessions[sessionIDFromContext(ctx)]
+	if !ok {
+		return
+	}
+	if subscribed {
+		session.subscriptions[uri] = true
+	} else {
+		delete(session.subscriptions, uri)
+	}
+}
+
+// sessionInit is what a client and the server exchanged in initialize
+type sessionInit struct {
+	clientInfo         mcp.Implementation
+	clientCapabilities mcp.ClientCapabilities
+	// elicitation is whether the client declared the elicitation
+	// capability, which ClientCapabilities predates
+	elicitation     bool
+	capabilities    mcp.ServerCapabilities
+	protocolVersion string
+}
+
+// initializeSession records what the client of the session of the request
+// and the server exchanged in initialize
+func (s *DefaultServer) initializeSession(ctx context.Context, init sessionInit) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	if session, ok := s.sessions.sessions[sessionIDFromContext(ctx)]; ok {
+		session.mu.Lock()
+		session.clientInfo = init.clientInfo
+		session.clientCapabilities = init.clientCapabilities
+		session.elicitation = init.elicitation
+		session.capabilities = init.capabilities
+		session.protocolVersion = init.protocolVersion
+		session.mu.Unlock()
+	}
+}
+
+// listChangedNotification returns the list_changed notification of kind
+// and whether the capabilities a client was told about announce it.
+// method is empty for kinds without one.
+func listChangedNotification(kind RegistryKind) (method string, advertised func(mcp.ServerCapabilities) bool) {
+	switch kind {
+	case RegistryKindTool:
+		return mcp.MethodNotificationToolsListChanged, func(c mcp.ServerCapabilities) bool {
+			return c.Tools != nil && c.Tools.ListChanged
+		}
+	case RegistryKindResource, RegistryKindResourceTemplate:
+		return mcp.MethodNotificationResourcesListChanged, func(c mcp.ServerCapabilities) bool {
+			return c.Resources != nil && c.Resources.ListChanged
+		}
+	case RegistryKindPrompt:
+		return mcp.MethodNotificationPromptsListChanged, func(c mcp.ServerCapabilities) bool {
+			return c.Prompts != nil && c.Prompts.ListChanged
+		}
+	}
+	return "", nil
+}
+
+// notifyListChanged sends the list_changed notification of kind to every
+// session that was told the server sends it
+func (s *DefaultServer) notifyListChanged(kind RegistryKind) {
+	method, advertised := listChangedNotification(kind)
+	if method == "" {
+		return
+	}
+
+	s.sessions.mu.RLock()
+	var notify []notifyFunc
+	for _, session := range s.sessions.sessions {
+		if advertised(session.capabilities) {
+			notify = append(notify, session.notify)
+		}
+	}
+	s.sessions.mu.RUnlock()
+
+	// A client that went away is unregistered by its transport
+	for _, fn := range notify {
+		_ = fn(method, nil)
+	}
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated to every
+// connected client subscribed to uri. It returns the errors of sessions
+// that could not be notified.
+func (s *DefaultServer) NotifyResourceUpdated(uri string) error {
+	s.sessions.mu.RLock()
+	var notify []notifyFunc
+	var ids []string
+	for id, session := range s.sessions.sessions {
+		if session.subscriptions[uri] {
+			notify = append(notify, session.notify)
+			ids = append(ids, id)
+		}
+	}
+	s.sessions.mu.RUnlock()
+
+	var errs []error
+	for i, fn := range notify {
+		err := fn(mcp.MethodNotificationResourceUpdated, map[string]string{"uri": uri})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", ids[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// Session is a connected client as seen by handlers. It keeps state for
+// the duration of the connection and holds tools and resources that only
+// this client sees, next to the ones registered on the server.
+type Session struct {
+	client *clientSession
+}
+
+type toolEntry struct {
+	tool    mcp.ToolDefinition
+	handler ToolHandlerFunc
+}
+
+type sessionResource struct {
+	resource mcp.Resource
+	handler  ResourceHandlerFunc
+}
+
+// SessionFromContext returns the session of the request being handled, or
+// nil outside of a request that arrived through a transport
+func SessionFromContext(ctx context.Context) *Session {
+	client := clientSessionFromContext(ctx)
+	if client == nil {
+		return nil
+	}
+	return &Session{client: client}
+}
+
+// Session returns a connected session by its ID
+func (s *DefaultServer) Session(sessionID string) (*Session, bool) {
+	client, ok := s.session(sessionID)
+	if !ok {
+		return nil, false
+	}
+	return &Session{client: client}, true
+}
+
+// ID returns the ID the transport gave the session
+func (s *Session) ID() string {
+	return s.client.id
+}
+
+// ClientInfo returns the name and version the client sent when it
+// initialized
+func (s *Session) ClientInfo() mcp.Implementation {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	return s.client.clientInfo
+}
+
+// Get returns a value stored with Set
+func (s *Session) Get(key string) (any, bool) {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	value, ok := s.client.values[key]
+	return value, ok
+}
+
+// Set stores a value for the rest of the session
+func (s *Session) Set(key string, value any) {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	if s.client.values == nil {
+		s.client.values = make(map[string]any)
+	}
+	s.client.values[key] = value
+}
+
+// Delete removes a value stored with Set
+func (s *Session) Delete(key string) {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	delete(s.client.values, key)
+}
+
+// AddTool registers a tool only this session lists and can call. It takes
+// the place of a tool of the same name added to the server.
+func (s *Session) AddTool(tool mcp.ToolDefinition, handler ToolHandlerFunc) {
+	s.client.mu.Lock()
+	if s.client.tools == nil {
+		s.client.tools = make(map[string]toolEntry)
+	}
+	s.client.tools[tool.Name] = toolEntry{tool: tool, handler: handler}
+	s.client.mu.Unlock()
+	s.client.notifyListChanged(RegistryKindTool)
+}
+
+// RemoveTool unregisters a tool added with AddTool. It reports whether the
+// tool was registered.
+func (s *Session) RemoveTool(name string) bool {
+	s.client.mu.Lock()
+	_, ok := s.client.tools[name]
+	delete(s.client.tools, name)
+	s.client.mu.Unlock()
+	if ok {
+		s.client.notifyListChanged(RegistryKindTool)
+	}
+	return ok
+}
+
+// AddResource registers a resource only this session lists and can read.
+// It takes the place of a resource of the same URI added to the server.
+func (s *Session) AddResource(resource mcp.Resource, handler ResourceHandlerFunc) {
+	s.client.mu.Lock()
+	if s.client.resources == nil {
+		s.client.resources = make(map[string]sessionResource)
+	}
+	s.client.resources[resource.Uri] = sessionResource{resource: resource, handler: handler}
+	s.client.mu.Unlock()
+	s.client.notifyListChanged(RegistryKindResource)
+}
+
+// RemoveResource unregisters a resource added with AddResource. It reports
+// whether the resource was registered.
+func (s *Session) RemoveResource(uri string) bool {
+	s.client.mu.Lock()
+	_, ok := s.client.resources[uri]
+	delete(s.client.resources, uri)
+	s.client.mu.Unlock()
+	if ok {
+		s.client.notifyListChanged(RegistryKindResource)
+	}
+	return ok
+}
+
+// notifyListChanged tells the client its list of kind changed, if it was
+// told the server sends such notifications
+func (c *clientSession) notifyListChanged(kind RegistryKind) {
+	method, advertised := listChangedNotification(kind)
+	c.mu.Lock()
+	send := method != "" && advertised(c.capabilities)
+	c.mu.Unlock()
+	if send {
+		_ = c.notify(method, nil)
+	}
+}
+
+// scopedTools returns the tools of the server as the session sees them,
+// with its own tools replacing and following them
+func (c *clientSession) scopedTools(tools []mcp.ToolDefinition) []mcp.ToolDefinition {
+	if c == nil {
+		return tools
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.tools) == 0 {
+		return tools
+	}
+	tools = slices.DeleteFunc(tools, func(tool mcp.ToolDefinition) bool {
+		_, ok := c.tools[tool.Name]
+		return ok
+	})
+	for _, name := range slices.Sorted(maps.Keys(c.tools)) {
+		tools = append(tools, c.tools[name].tool)
+	}
+	return tools
+}
+
+// scopedResources returns the resources of the server as the session sees
+// them, with its own resources replacing and following them
+func (c *clientSession) scopedResources(resources []mcp.Resource) []mcp.Resource {
+	if c == nil {
+		return resources
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.resources) == 0 {
+		return resources
+	}
+	resources = slices.DeleteFunc(resources, func(resource mcp.Resource) bool {
+		_, ok := c.resources[resource.Uri]
+		return ok
+	})
+	for _, uri := range slices.Sorted(maps.Keys(c.resources)) {
+		resources = append(resources, c.resources[uri].resource)
+	}
+	return resources
+}
+
+func (c *clientSession) tool(name string) (toolEntry, bool) {
+	if c == nil {
+		return toolEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tool, ok := c.tools[name]
+	return tool, ok
+}
+
+func (c *clientSession) resource(uri string) (sessionResource, bool) {
+	if c == nil {
+		return sessionResource{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resource, ok := c.resources[uri]
+	return resource, ok
+}
+
+func (c *clientSession) hasTools() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tools) > 0
+}
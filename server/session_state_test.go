@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the messages sent to a session
+type recorder struct {
+	mu       sync.Mutex
+	messages []any
+}
+
+func (r *recorder) send(message any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *recorder) methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var methods []string
+	for _, message := range r.messages {
+		if n, ok := message.(notification); ok {
+			methods = append(methods, n.Method)
+		}
+	}
+	return methods
+}
+
+func toolNames(t *testing.T, s MCPServer, ctx context.Context) []string {
+	t.Helper()
+	response := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.Nil(t, response.Error)
+	var names []string
+	for _, tool := range response.Result.(*mcp.ListToolDefinitionsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestSession(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	textResult := func(text string) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []interface{}{mcp.TextContent{Type: "text", Text: text}}}, nil
+		}
+	}
+	s.AddTool(mcp.ToolDefinition{Name: "shared", InputSchema: mcp.ToolSchema{Type: "object"}}, textResult("server"))
+	s.AddTool(mcp.ToolDefinition{Name: "count", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			session := SessionFromContext(ctx)
+			count, _ := session.Get("count")
+			n, _ := count.(int)
+			session.Set("count", n+1)
+			return &mcp.CallToolResult{}, nil
+		})
+
+	var a, b recorder
+	s.registerSession("a", a.send)
+	s.registerSession("b", b.send)
+	ctxA := withSessionID(context.Background(), "a")
+	ctxB := withSessionID(context.Background(), "b")
+
+	init := s.Request(ctxA, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {},
+			"clientInfo": {"name": "tenant-a", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, init.Error)
+
+	sessionA, ok := s.Session("a")
+	require.True(t, ok)
+	assert.Equal(t, "a", sessionA.ID())
+	assert.Equal(t, "tenant-a", sessionA.ClientInfo().Name)
+	_, ok = s.Session("missing")
+	assert.False(t, ok)
+	assert.Nil(t, SessionFromContext(context.Background()))
+
+	// State is kept per session
+	callTool(t, s, ctxA, `{"name":"count"}`)
+	callTool(t, s, ctxA, `{"name":"count"}`)
+	callTool(t, s, ctxB, `{"name":"count"}`)
+	count, _ := sessionA.Get("count")
+	assert.Equal(t, 2, count)
+	sessionB, _ := s.Session("b")
+	count, _ = sessionB.Get("count")
+	assert.Equal(t, 1, count)
+	sessionB.Delete("count")
+	_, ok = sessionB.Get("count")
+	assert.False(t, ok)
+
+	// Session tools are only seen by their session
+	sessionA.AddTool(mcp.ToolDefinition{Name: "private", InputSchema: mcp.ToolSchema{Type: "object"}}, textResult("private"))
+	sessionA.AddTool(mcp.ToolDefinition{Name: "shared", InputSchema: mcp.ToolSchema{Type: "object"}}, textResult("session"))
+	assert.Equal(t, []string{"notifications/tools/list_changed", "notifications/tools/list_changed"}, a.methods())
+	assert.Empty(t, b.methods(), "b did not initialize with list_changed")
+
+	assert.Equal(t, []string{"count", "private", "shared"}, toolNames(t, s, ctxA))
+	assert.Equal(t, []string{"count", "shared"}, toolNames(t, s, ctxB))
+
+	shared := callTool(t, s, ctxA, `{"name":"shared"}`)
+	require.Nil(t, shared.Error)
+	assert.Equal(t, "session", shared.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+	shared = callTool(t, s, ctxB, `{"name":"shared"}`)
+	require.Nil(t, shared.Error)
+	assert.Equal(t, "server", shared.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	assert.True(t, sessionA.RemoveTool("shared"))
+	assert.False(t, sessionA.RemoveTool("shared"))
+	assert.Equal(t, []string{"count", "shared", "private"}, toolNames(t, s, ctxA))
+
+	// And so are session resources
+	sessionB.AddResource(mcp.Resource{Uri: "tenant://b/notes", Name: "notes", MimeType: "text/plain"},
+		func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []interface{}{
+				mcp.TextResourceContents{Uri: request.Params.Uri, Text: "b's notes"},
+			}}, nil
+		})
+	list := s.Request(ctxB, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "resources/list"})
+	require.Nil(t, list.Error)
+	require.Len(t, list.Result.(*mcp.ListResourcesResult).Resources, 1)
+	list = s.Request(ctxA, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "resources/list"})
+	require.Nil(t, list.Error)
+	assert.Empty(t, list.Result.(*mcp.ListResourcesResult).Resources)
+
+	read := s.Request(ctxB, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      3,
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"tenant://b/notes"}`),
+	})
+	require.Nil(t, read.Error)
+	contents := read.Result.(*mcp.ReadResourceResult).Contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "b's notes", contents.Text)
+	assert.Equal(t, "text/plain", contents.MimeType)
+	assert.True(t, sessionB.RemoveResource("tenant://b/notes"))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectSSE opens a session and returns its ID and the data of the
+// messages it receives
+func connectSSE(t *testing.T, serverURL string) (string, chan string) {
+	t.Helper()
+	resp, err := http.Get(serverURL + "/sse")
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	reader := bufio.NewReader(resp.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	sessionID := strings.TrimSpace(strings.Split(dataLine, "sessionId=")[1])
+
+	messages := make(chan string, 10)
+	go readSSEMessages(reader, messages)
+	return sessionID, messages
+}
+
+func nextMessage(t *testing.T, messages chan string) map[string]any {
+	t.Helper()
+	select {
+	case data := <-messages:
+		var message map[string]any
+		require.NoError(t, json.Unmarshal([]byte(data), &message))
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for SSE message")
+		return nil
+	}
+}
+
+func TestNotifyResourceUpdated(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	_, testServer := NewTestServer(mcpServer)
+	// Cleanups run last in first out, streams close before the server
+	t.Cleanup(testServer.Close)
+
+	sessionA, messagesA := connectSSE(t, testServer.URL)
+	sessionB, messagesB := connectSSE(t, testServer.URL)
+
+	subscribe := func(sessionID, method, uri string) {
+		sendJSONRPCRequest(t, testServer.URL, sessionID, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      "1",
+			Method:  method,
+			Params:  json.RawMessage(`{"uri":"` + uri + `"}`),
+		})
+	}
+	subscribe(sessionA, "resources/subscribe", "test://a")
+	subscribe(sessionB, "resources/subscribe", "test://b")
+	nextMessage(t, messagesA)
+	nextMessage(t, messagesB)
+
+	require.NoError(t, mcpServer.NotifyResourceUpdated("test://a"))
+	message := nextMessage(t, messagesA)
+	assert.Equal(t, "notifications/resources/updated", message["method"])
+	assert.Equal(t, map[string]any{"uri": "test://a"}, message["params"])
+	select {
+	case data := <-messagesB:
+		t.Fatalf("unsubscribed session got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	subscribe(sessionA, "resources/unsubscribe", "test://a")
+	nextMessage(t, messagesA)
+	require.NoError(t, mcpServer.NotifyResourceUpdated("test://a"))
+	require.NoError(t, mcpServer.NotifyResourceUpdated("test://b"))
+	message = nextMessage(t, messagesB)
+	assert.Equal(t, map[string]any{"uri": "test://b"}, message["params"])
+	select {
+	case data := <-messagesA:
+		t.Fatalf("unsubscribed session got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifyListChanged(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	noop := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "first", InputSchema: mcp.ToolSchema{Type: "object"}}, noop)
+
+	sessionID, messages := connectSSE(t, testServer.URL)
+	_, uninitializedMessages := connectSSE(t, testServer.URL)
+
+	sendJSONRPCRequest(t, testServer.URL, sessionID, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      "1",
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"capabilities": {},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	capabilities := nextMessage(t, messages)["result"].(map[string]any)["capabilities"]
+	assert.Equal(t, map[string]any{"listChanged": true}, capabilities.(map[string]any)["tools"])
+
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "second", InputSchema: mcp.ToolSchema{Type: "object"}}, noop)
+	assert.Equal(t, "notifications/tools/list_changed", nextMessage(t, messages)["method"])
+	assert.True(t, mcpServer.RemoveTool("first"))
+	assert.Equal(t, "notifications/tools/list_changed", nextMessage(t, messages)["method"])
+
+	mcpServer.AddResource(mcp.Resource{Uri: "test://a", Name: "a"}, nil)
+	assert.Equal(t, "notifications/resources/list_changed", nextMessage(t, messages)["method"])
+
+	// Sessions that never initialized were not told about list changes
+	select {
+	case data := <-uninitializedMessages:
+		t.Fatalf("uninitialized session got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+)
+
+// WithSlogLogger logs failed requests, panicking handlers and failed audit
+// writes to logger. Records carry the session, method and correlation ID
+// of the request as attributes. Without a logger only errors are logged,
+// to slog.Default.
+func WithSlogLogger(logger *slog.Logger) ServerOption {
+	return func(s *DefaultServer) {
+		s.logger = logger
+	}
+}
+
+// WithLogger logs to logger as text records.
+//
+// Deprecated: use WithSlogLogger.
+func WithLogger(logger *log.Logger) ServerOption {
+	return WithSlogLogger(slog.New(slog.NewTextHandler(logger.Writer(), nil)))
+}
+
+// WithStdioLogger logs errors of the stdio transport to logger instead of
+// standard error
+func WithStdioLogger(logger *slog.Logger) StdioOption {
+	return func(s *StdioServer) {
+		s.logger = logger
+	}
+}
+
+// WithStdioErrorWriter logs errors of the stdio transport to w as text
+// records instead of standard error. w must not be the output the server
+// writes messages to.
+func WithStdioErrorWriter(w io.Writer) StdioOption {
+	return WithStdioLogger(slog.New(slog.NewTextHandler(w, nil)))
+}
+
+// WithSSELogger logs errors of the SSE transport to logger. They are not
+// logged by default.
+func WithSSELogger(logger *slog.Logger) SSEOption {
+	return func(s *SSEServer) {
+		s.logger = logger
+	}
+}
+
+// defaultStdioLogger writes to standard error, as standard output carries
+// the messages
+func defaultStdioLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// log writes a record about the request being handled, with its session,
+// method and correlation ID
+func (s *DefaultServer) log(
+	ctx context.Context,
+	level slog.Level,
+	msg string,
+	method string,
+	attrs ...slog.Attr,
+) {
+	logger := s.logger
+	if logger == nil {
+		if level < slog.LevelError {
+			return
+		}
+		logger = slog.Default()
+	}
+	attrs = append([]slog.Attr{
+		slog.String("method", method),
+		slog.String("correlationId", CorrelationIDFromContext(ctx)),
+	}, attrs...)
+	if sessionID := sessionIDFromContext(ctx); sessionID != "" {
+		attrs = append(attrs, slog.String("session", sessionID))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithStdioLogger(t *testing.T) {
+	var logs syncBuffer
+	ts := setupTestStdioServer(t, WithStdioLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	defer ts.cleanup(t)
+
+	resp, err := ts.sendRawRequest(`not json`)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcp.ParseError, resp.Error.Code)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), `msg="failed to handle message" session=stdio`)
+	}, time.Second, 5*time.Millisecond)
+	assert.Contains(t, logs.String(), "level=ERROR")
+}
+
+func TestWithStdioErrorWriter(t *testing.T) {
+	var logs, out syncBuffer
+	server := NewStdioServer(NewDefaultServer("test-server", "1.0.0"), WithStdioErrorWriter(&logs))
+	in := strings.NewReader("not json\n" + `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n")
+	require.NoError(t, server.Listen(context.Background(), in, &out))
+
+	assert.Contains(t, logs.String(), `msg="failed to handle message" session=stdio`)
+	// Only messages reach the output
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+		assert.NotContains(t, line, "failed to handle message")
+	}
+}
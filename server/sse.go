@@ -1,58 +1,217 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/google/uuid"
+	"github.com/huangyul/go-mcp/mcp"
 )
 
 type SSEServer struct {
 	mcpServer MCPServer
 	baseURL   string
-	sessions  sync.Map
-	srv       *http.Server
+	// basePath prefixes both endpoints, see WithBasePath
+	basePath        string
+	sseEndpoint     string
+	messageEndpoint string
+	sessions        sync.Map
+	srv             *http.Server
+	drain           drain
+	// maxBodySize bounds the size of a posted message, see WithMaxBodySize
+	maxBodySize   int64
+	authenticator Authenticator
+	cors          CORSConfig
+	tlsConfig     *tls.Config
+	keepAlive     KeepAliveConfig
+	eventQueue    EventQueueConfig
+	replay        EventReplayConfig
+	contextFunc   SSEContextFunc
+	// forwardedHeaders are the canonical names of the headers set with
+	// WithForwardedHeaders
+	forwardedHeaders []string
+	// onSessionRegistered and onSessionClosed are set with
+	// WithOnSessionRegistered and WithOnSessionClosed
+	onSessionRegistered func(sessionID string)
+	onSessionClosed     func(sessionID string)
+	maxSessions         int64
+	responseDelivery    ResponseDelivery
+	compression         *CompressionConfig
+	openSessions        atomic.Int64
+	// healthEndpoint is empty unless set with WithHealthEndpoint
+	healthEndpoint string
+	// logger is nil unless set with WithSSELogger
+	logger *slog.Logger
 }
 
 type sseSession struct {
+	// writer and flusher belong to the stream attached to the session.
+	// The flusher reaches through middleware that wraps the writer, as
+	// long as the wrapper has an Unwrap method.
 	writer  http.ResponseWriter
-	flusher http.Flusher
+	flusher *http.ResponseController
 	done    chan struct{}
+	id      string
+	// values is the context of the request that opened the session, for
+	// the values put on it
+	values context.Context
+	// identity the client authenticated with when it opened the session
+	identity any
+	// queue holds the events of concurrent requests and notifications
+	// until the stream handler, the only goroutine writing to the stream,
+	// writes them
+	queue     chan *encodedMessage
+	policy    QueueFullPolicy
+	closeOnce sync.Once
+	// closeReason is sent in the close event, see closeWith
+	closeReason string
+	// lastEventID is the ID of the last event written
+	lastEventID uint64
+	// replay keeps the recent events, nil without WithEventReplay
+	replay *eventReplay
+	// attachMu serializes streams taking over the session, see attach
+	attachMu   sync.Mutex
+	generation int
+	stop       chan struct{}
+	released   chan struct{}
+	endOnce    sync.Once
+	end        func()
 }
 
-func NewSSEServer(server MCPServer, baseURL string) *SSEServer {
-	return &SSEServer{
-		mcpServer: server,
-		baseURL:   baseURL,
+// writeEvent writes one message event on the session stream. Only the
+// stream handler calls it.
+func (s *sseSession) writeEvent(message *encodedMessage) error {
+	s.lastEventID++
+	if s.replay != nil {
+		// Events are kept whole to be written again on a new stream
+		var data bytes.Buffer
+		if err := message.writeTo(&data); err != nil {
+			return err
+		}
+		s.replay.add(s.lastEventID, data.Bytes())
+		return s.writeData(s.lastEventID, data.Bytes())
 	}
+
+	fmt.Fprintf(s.writer, "id: %d\nevent: message\ndata: ", s.lastEventID)
+	err := message.writeTo(s.writer)
+	fmt.Fprint(s.writer, "\n\n")
+	if flushErr := s.flusher.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// writeData writes a message event already encoded
+func (s *sseSession) writeData(id uint64, data []byte) error {
+	fmt.Fprintf(s.writer, "id: %d\nevent: message\ndata: %s\n\n", id, data)
+	return s.flusher.Flush()
+}
+
+func (s *sseSession) close() {
+	s.closeWith("")
+}
+
+// closeWith closes the session, telling its client why with a final close
+// event when reason is set
+func (s *sseSession) closeWith(reason string) {
+	s.closeOnce.Do(func() {
+		s.closeReason = reason
+		close(s.done)
+	})
+}
+
+// finish removes the session from the server once it is over
+func (s *sseSession) finish() {
+	s.endOnce.Do(func() {
+		s.discardQueued()
+		s.end()
+	})
+}
+
+// SSEOption configures an SSEServer
+type SSEOption func(*SSEServer)
+
+// NewSSEServer returns a server announcing its message endpoint under
+// baseURL. When baseURL is empty, the endpoint is derived from the request
+// of each stream.
+func NewSSEServer(server MCPServer, baseURL string, opts ...SSEOption) *SSEServer {
+	s := &SSEServer{
+		mcpServer:       server,
+		baseURL:         baseURL,
+		sseEndpoint:     "/sse",
+		messageEndpoint: "/message",
+		maxBodySize:     defaultMaxMessageSize,
+		cors:            defaultCORS,
+		eventQueue:      EventQueueConfig{Size: defaultEventQueueSize},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithBasePath serves both endpoints under path, such as /api/v1/mcp, for
+// mounting the server on a router without stripping the prefix. The base
+// URL must not include it.
+func WithBasePath(path string) SSEOption {
+	return func(s *SSEServer) {
+		s.basePath = strings.TrimSuffix(normalizePath(path), "/")
+	}
+}
+
+// WithSSEEndpoint sets the path of the SSE stream, /sse by default
+func WithSSEEndpoint(path string) SSEOption {
+	return func(s *SSEServer) {
+		s.sseEndpoint = normalizePath(path)
+	}
+}
+
+// WithMessageEndpoint sets the path that accepts client messages, /message
+// by default
+func WithMessageEndpoint(path string) SSEOption {
+	return func(s *SSEServer) {
+		s.messageEndpoint = normalizePath(path)
+	}
+}
+
+// normalizePath gives path a leading slash
+func normalizePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
+
+// SSEPath returns the path of the SSE stream, including the base path
+func (s *SSEServer) SSEPath() string {
+	return s.basePath + s.sseEndpoint
+}
+
+// MessagePath returns the path that accepts client messages, including the
+// base path
+func (s *SSEServer) MessagePath() string {
+	return s.basePath + s.messageEndpoint
 }
 
 // NewTestServer creates a test server for testing purposes
 // It returns the SSEServer and a test server that can be closed when done
-func NewTestServer(mcpServer MCPServer) (*SSEServer, *httptest.Server) {
+func NewTestServer(mcpServer MCPServer, opts ...SSEOption) (*SSEServer, *httptest.Server) {
 	// Create SSE server with test server's URL as base
-	sseServer := &SSEServer{
-		mcpServer: mcpServer,
-	}
+	sseServer := NewSSEServer(mcpServer, "", opts...)
 
 	// Create test HTTP server
-	testServer := httptest.NewServer(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.URL.Path {
-			case "/sse":
-				sseServer.handleSSE(w, r)
-
-			case "/message":
-				sseServer.handleMessage(w, r)
-			default:
-				http.NotFound(w, r)
-			}
-		}),
-	)
+	testServer := httptest.NewServer(sseServer)
 
 	// Set base URL from test server
 	sseServer.baseURL = testServer.URL
@@ -60,114 +219,454 @@ func NewTestServer(mcpServer MCPServer) (*SSEServer, *httptest.Server) {
 	return sseServer, testServer
 }
 
+// Shutdown stops accepting messages, answering new ones with 503, and
+// waits for the messages being handled to be answered before it closes
+// the sessions and, when started with Start, the HTTP server. Each stream
+// gets the events already queued and a final close event with the data
+// "shutdown", so clients can tell the shutdown from a network failure.
+// Once ctx ends it stops waiting and closes everything right away. The
+// workers of a DefaultServer with WithWorkerPool are stopped last.
 func (s *SSEServer) Shutdown(ctx context.Context) error {
-	if s.srv != nil {
+	drainErr := s.drain.wait(ctx)
 
-		s.sessions.Range(func(key, value any) bool {
-			if session, ok := value.(*sseSession); ok {
-				close(session.done)
-			}
-			s.sessions.Delete(key)
-			return true
-		})
+	s.sessions.Range(func(key, value any) bool {
+		if session, ok := value.(*sseSession); ok {
+			session.closeWith("shutdown")
+		}
+		s.sessions.Delete(key)
+		return true
+	})
 
-		return s.srv.Shutdown(ctx)
+	if s.srv != nil {
+		if err := s.srv.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if server, ok := s.mcpServer.(shutdowner); ok {
+		if err := server.Shutdown(ctx); err != nil {
+			return err
+		}
 	}
+	return drainErr
+}
 
-	return nil
+// WithTLSConfig sets the TLS configuration used by StartTLS
+func WithTLSConfig(config *tls.Config) SSEOption {
+	return func(s *SSEServer) {
+		s.tlsConfig = config
+	}
 }
 
 func (s *SSEServer) Start(addr string) error {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/sse", s.handleSSE)
-	mux.HandleFunc("/message", s.handleMessage)
-
 	s.srv = &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: s,
 	}
 
 	return s.srv.ListenAndServe()
 }
 
+// StartTLS is like Start but serves HTTPS with the certificate and key in
+// the given files. They may be empty when the TLS configuration set with
+// WithTLSConfig provides the certificates.
+func (s *SSEServer) StartTLS(addr, certFile, keyFile string) error {
+	s.srv = &http.Server{
+		Addr:      addr,
+		Handler:   s,
+		TLSConfig: s.tlsConfig,
+	}
+
+	return s.srv.ListenAndServeTLS(certFile, keyFile)
+}
+
+// ServeHTTP serves the SSE stream on SSEPath, client messages on
+// MessagePath and, when enabled, the health endpoint on HealthPath, so the
+// server can be wrapped in middleware or mounted on another mux. When
+// mounted under a prefix, either strip it with http.StripPrefix, which an
+// empty base URL picks up, or set it with WithBasePath. The request
+// context, with any values middleware put on it, is passed on to the
+// MCPServer.
+func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case s.SSEPath():
+		s.handleSSE(w, r)
+	case s.MessagePath():
+		s.handleMessage(w, r)
+	case s.HealthPath():
+		s.handleHealth(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Handler returns the server as an http.Handler serving both endpoints,
+// for mounting on a router in place of Start
+func (s *SSEServer) Handler() http.Handler {
+	return s
+}
+
+// SSEHandler returns the handler for the SSE stream, for routing it
+// separately from the message endpoint
+func (s *SSEServer) SSEHandler() http.Handler {
+	return http.HandlerFunc(s.handleSSE)
+}
+
+// MessageHandler returns the handler that accepts client messages. It must
+// be reachable at the base URL followed by MessagePath.
+func (s *SSEServer) MessageHandler() http.Handler {
+	return http.HandlerFunc(s.handleMessage)
+}
+
 func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if s.applyCORS(w, r, http.MethodGet) {
+		return
+	}
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	r, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	if s.contextFunc != nil {
+		r = r.WithContext(s.contextFunc(r.Context(), r))
+	}
+
+	// A client reconnecting within the replay window gets its session
+	// back, with the events it missed
+	var resumed *sseSession
+	if s.replay.Size > 0 {
+		resumed, _ = s.resumableSession(r)
+	}
+	if resumed != nil && !s.authorizeSession(w, r, resumed) {
+		return
+	}
+	if resumed == nil && !s.acquireSession(w) {
+		return
+	}
+
+	if s.compression != nil && s.compression.Streams {
+		var finish func()
+		w, finish = s.compress(w, r)
+		defer finish()
+	}
 
 	// set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	flusher := http.NewResponseController(w)
+	if err := flusher.Flush(); err != nil {
+		if resumed == nil {
+			s.releaseSession()
+		}
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if resumed != nil {
+		s.serveStream(w, r, resumed)
+		return
 	}
 
 	session := &sseSession{
-		writer:  w,
-		flusher: flusher,
-		done:    make(chan struct{}),
+		values:   context.WithoutCancel(r.Context()),
+		identity: IdentityFromContext(r.Context()),
+		done:     make(chan struct{}),
+		queue:    make(chan *encodedMessage, s.eventQueue.Size),
+		policy:   s.eventQueue.Policy,
+		id:       uuid.New().String(),
+	}
+	if s.replay.Size > 0 {
+		session.replay = newEventReplay(s.replay.Size)
 	}
-	sessionID := uuid.New().String()
+	sessionID := session.id
+	unregister := func() {}
 
+	// Messages sent before the endpoint event is out wait in the queue
 	s.sessions.Store(sessionID, session)
-	defer s.sessions.Delete(sessionID)
+	// Register before the client learns the endpoint, so its first
+	// subscription cannot arrive ahead of the session
+	if tracker, ok := s.mcpServer.(sessionTracker); ok {
+		send := func(message any) error {
+			return s.SendEventToSession(sessionID, message)
+		}
+		if withOptions, ok := tracker.(sessionOptionsTracker); ok {
+			withOptions.registerSessionWith(sessionID, send, sessionOptions{
+				identity: session.identity,
+				close:    session.close,
+			})
+		} else {
+			tracker.registerSession(sessionID, send)
+		}
+		unregister = func() { tracker.unregisterSession(sessionID) }
+	}
+	session.end = func() {
+		s.releaseSession()
+		s.sessions.CompareAndDelete(sessionID, session)
+		unregister()
+		if s.onSessionClosed != nil {
+			s.onSessionClosed(sessionID)
+		}
+	}
+	if s.onSessionRegistered != nil {
+		s.onSessionRegistered(sessionID)
+	}
+
+	s.serveStream(w, r, session)
+}
+
+// serveStream attaches the stream of r to session and writes the events of
+// the session until the stream or the session ends
+func (s *SSEServer) serveStream(w http.ResponseWriter, r *http.Request, session *sseSession) {
+	stop, release := session.attach(w, http.NewResponseController(w))
+	defer release()
 
 	// send endpoint event
-	endpointEvent := fmt.Sprintf("event: endpoint\ndata: %s/message?sessionId=%s\n\n", s.baseURL, sessionID)
+	endpointEvent := fmt.Sprintf("event: endpoint\ndata: %s?sessionId=%s\n\n", s.messageURL(r), session.id)
 
 	fmt.Fprint(w, endpointEvent)
-	flusher.Flush()
+	_ = session.flusher.Flush()
 
-	<-r.Context().Done()
-	close(session.done)
+	if session.replay != nil {
+		if err := session.replayAfter(r.Header.Get("Last-Event-ID")); err != nil {
+			s.disconnect(session)
+			return
+		}
+	}
+
+	keepAlive, stopKeepAlive := s.keepAlive.ticker()
+	defer stopKeepAlive()
+
+	// Shutdown closes the session to end the stream, after the events
+	// already queued are written
+	for {
+		select {
+		case <-r.Context().Done():
+			s.disconnect(session)
+			return
+		case <-stop:
+			// Another stream took over the session
+			return
+		case <-session.done:
+			session.flush()
+			if session.closeReason != "" {
+				_ = session.writeFrame(fmt.Sprintf("event: close\ndata: %s\n\n", session.closeReason))
+			}
+			session.finish()
+			return
+		case message := <-session.queue:
+			if err := session.writeEvent(message); err != nil {
+				if s.logger != nil {
+					s.logger.Warn("failed to write event", "session", session.id, "error", err)
+				}
+				s.disconnect(session)
+				return
+			}
+		case <-keepAlive:
+			if err := session.writeFrame(s.keepAlive.frame()); err != nil {
+				s.disconnect(session)
+				return
+			}
+		}
+	}
+}
+
+// messageURL returns the URL clients post messages to. Without a base URL
+// it is derived from the request, honoring X-Forwarded-Proto and
+// X-Forwarded-Host set by proxies and any prefix stripped by
+// http.StripPrefix.
+func (s *SSEServer) messageURL(r *http.Request) string {
+	if s.baseURL != "" {
+		return s.baseURL + s.MessagePath()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := forwardedValue(r, "X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwarded := forwardedValue(r, "X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+
+	prefix := ""
+	if requestURI, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		prefix, _ = strings.CutSuffix(requestURI.Path, r.URL.Path)
+		if prefix == requestURI.Path {
+			prefix = ""
+		}
+	}
+	return scheme + "://" + host + prefix + s.MessagePath()
+}
+
+// forwardedValue returns the first value of a header set by proxies
+func forwardedValue(r *http.Request, header string) string {
+	value, _, _ := strings.Cut(r.Header.Get(header), ",")
+	return strings.TrimSpace(value)
+}
+
+// disconnect ends a session whose stream is gone, or with event replay
+// keeps it for its client to reconnect to
+func (s *SSEServer) disconnect(session *sseSession) {
+	if session.replay == nil {
+		session.close()
+		session.finish()
+		return
+	}
+	go session.expire(s.replay.Window)
 }
 
 func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if s.applyCORS(w, r, http.MethodPost) {
+		return
+	}
+	w, finish := s.compress(w, r)
+	defer finish()
 	if r.Method != http.MethodPost {
-		s.writeJSONRPCError(w, nil, -32600, "Method not allowed")
+		s.writeJSONRPCError(w, nil, mcp.InvalidRequest, "Method not allowed")
 		return
 	}
+	r, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	if s.contextFunc != nil {
+		r = r.WithContext(s.contextFunc(r.Context(), r))
+	}
+	if len(s.forwardedHeaders) > 0 {
+		r = r.WithContext(withRequestHeaders(r.Context(), r.Header, s.forwardedHeaders))
+	}
 
 	sessionId := r.URL.Query().Get("sessionId")
 	if sessionId == "" {
-		s.writeJSONRPCError(w, nil, -32602, "Missing sessionId")
+		s.writeJSONRPCError(w, nil, mcp.InvalidParams, "Missing sessionId")
 		return
 	}
 
 	sessionI, ok := s.sessions.Load(sessionId)
 	if !ok {
-		s.writeJSONRPCError(w, nil, -32602, "Invalid session ID")
+		s.writeJSONRPCError(w, nil, mcp.InvalidParams, "Invalid session ID")
 		return
 	}
 	session := sessionI.(*sseSession)
+	if !s.authorizeSession(w, r, session) {
+		return
+	}
 
-	var request JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		s.writeJSONRPCError(w, nil, -32700, "Parse error")
+	if !s.drain.enter() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: mcp.ServerUnavailable, Message: errDraining.Error()},
+		})
 		return
 	}
+	defer s.drain.leave()
 
-	response := s.mcpServer.Request(r.Context(), request)
+	if s.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+	}
+	var message json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(JSONRPCResponse{
+				JSONRPC: "2.0",
+				Error:   &JSONRPCError{Code: mcp.InvalidRequest, Message: errMessageTooLarge.Error()},
+			})
+			return
+		}
+		s.writeJSONRPCError(w, nil, mcp.ParseError, "Parse error")
+		return
+	}
 
-	data, _ := json.Marshal(response)
-	fmt.Fprintf(session.writer, "event: message\ndata: %s\n\n", data)
-	session.flusher.Flush()
+	var response any
+	if batch, isBatch, err := splitBatch(message); isBatch {
+		if err != nil {
+			s.writeJSONRPCError(w, nil, mcp.ParseError, "Parse error")
+			return
+		}
+		response = handleBatch(batch, func(request JSONRPCRequest, message json.RawMessage) *JSONRPCResponse {
+			return s.handleRequest(withValues(r.Context(), session.values), sessionId, request, message)
+		})
+	} else {
+		var request JSONRPCRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			s.writeJSONRPCError(w, nil, mcp.ParseError, "Parse error")
+			return
+		}
+		if single := s.handleRequest(withValues(r.Context(), session.values), sessionId, request, message); single != nil {
+			response = single
+		}
+	}
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(response)
+	encoded, err := encodeMessage(response)
+	if err == nil {
+		err = s.deliver(w, session, encoded)
+	}
+	if err != nil && s.logger != nil {
+		s.logger.Warn("failed to write response", "session", sessionId, "error", err)
+	}
+}
 
+// handleRequest dispatches one message of a session. Responses to requests
+// of the server are handed back to it, and they and notifications get no
+// response.
+func (s *SSEServer) handleRequest(
+	ctx context.Context,
+	sessionID string,
+	request JSONRPCRequest,
+	message json.RawMessage,
+) *JSONRPCResponse {
+	// Responses to requests of the server carry no method
+	if request.Method == "" && request.ID != nil {
+		if tracker, ok := s.mcpServer.(sessionTracker); ok {
+			tracker.handleClientResponse(sessionID, message)
+		}
+		return nil
+	}
+	// Messages without a method or an ID are not JSON-RPC messages
+	if request.Method == "" {
+		return invalidRequest(nil)
+	}
+
+	ctx = withSessionID(ctx, sessionID)
+	ctx = withNotifier(ctx, s.notifier(sessionID))
+	response := s.mcpServer.Request(ctx, request)
+	// Notifications are handled but get no response
+	if isNotification(request) {
+		return nil
+	}
+	return &response
+}
+
+// notifier sends notifications on the stream of a session
+func (s *SSEServer) notifier(sessionID string) notifyFunc {
+	return func(method string, params any) error {
+		return s.SendEventToSession(sessionID, notification{
+			JSONRPC: "2.0",
+			Method:  method,
+			Params:  params,
+		})
+	}
 }
 
 func (s *SSEServer) writeJSONRPCError(
 	w http.ResponseWriter,
 	id any,
-	code int,
+	code mcp.ErrorCode,
 	message string,
 ) {
 	response := JSONRPCResponse{
@@ -179,6 +678,7 @@ func (s *SSEServer) writeJSONRPCError(
 		},
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusBadRequest)
 	json.NewEncoder(w).Encode(response)
 }
@@ -193,17 +693,10 @@ func (s *SSEServer) SendEventToSession(
 	}
 	session := sessionI.(*sseSession)
 
-	data, err := json.Marshal(event)
+	message, err := encodeMessage(event)
 	if err != nil {
 		return fmt.Errorf("failed to parse event: %w", err)
 	}
 
-	select {
-	case <-session.done:
-		return fmt.Errorf("session closed")
-	default:
-		fmt.Fprintf(session.writer, "event: message\ndata: %s", data)
-		session.flusher.Flush()
-		return nil
-	}
+	return session.enqueue(message)
 }
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net/http"
+)
+
+// SSEContextFunc derives the context of a request to an SSEServer, for
+// passing data from the HTTP request, such as tokens, tenant IDs or trace
+// headers, on to handlers
+type SSEContextFunc func(ctx context.Context, r *http.Request) context.Context
+
+// WithSSEContextFunc applies fn to the request that opens each SSE stream
+// and to every message posted. Handlers see the values fn put on the
+// context of the message, and otherwise those it put on the context of
+// the stream of the session.
+func WithSSEContextFunc(fn SSEContextFunc) SSEOption {
+	return func(s *SSEServer) {
+		s.contextFunc = fn
+	}
+}
+
+// WithForwardedHeaders copies the named headers of every message posted,
+// such as X-Request-Id, Traceparent or a tenant header, into its context.
+// Handlers read them with RequestHeaders; other headers are not exposed.
+func WithForwardedHeaders(names ...string) SSEOption {
+	return func(s *SSEServer) {
+		for _, name := range names {
+			s.forwardedHeaders = append(s.forwardedHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+type requestHeadersKey struct{}
+
+// RequestHeaders returns the headers of the HTTP request of the message
+// being handled that were allowed with WithForwardedHeaders, or nil
+// outside of such a request
+func RequestHeaders(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return header
+}
+
+// withRequestHeaders puts a copy of the headers of header named in names
+// on ctx
+func withRequestHeaders(ctx context.Context, header http.Header, names []string) context.Context {
+	forwarded := make(http.Header, len(names))
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			forwarded[name] = append([]string(nil), values...)
+		}
+	}
+	return context.WithValue(ctx, requestHeadersKey{}, forwarded)
+}
+
+// sessionValuesContext looks values up in the context of a message and
+// then in the context that opened its session
+type sessionValuesContext struct {
+	context.Context
+	session context.Context
+}
+
+func (c sessionValuesContext) Value(key any) any {
+	if value := c.Context.Value(key); value != nil {
+		return value
+	}
+	return c.session.Value(key)
+}
+
+// withValues returns ctx falling back to the values of session
+func withValues(ctx, session context.Context) context.Context {
+	if session == nil {
+		return ctx
+	}
+	return sessionValuesContext{Context: ctx, session: session}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+type traceHeaderKey struct{}
+
+func TestSSEServerContextFunc(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "whereami", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			trace, _ := ctx.Value(traceHeaderKey{}).(string)
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(tenant + "/" + trace)}}, nil
+		})
+
+	_, testServer := NewTestServer(mcpServer, WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+		if tenant := r.Header.Get("X-Tenant"); tenant != "" {
+			ctx = context.WithValue(ctx, tenantKey{}, tenant)
+		}
+		if trace := r.Header.Get("X-Trace"); trace != "" {
+			ctx = context.WithValue(ctx, traceHeaderKey{}, trace)
+		}
+		return ctx
+	}), WithResponseDelivery(ResponseInBody))
+	t.Cleanup(testServer.Close)
+
+	header := http.Header{}
+	header.Set("X-Tenant", "acme")
+	stream := openSSEStream(t, testServer.URL+"/sse", header)
+	endpoint := stream.next(t)["data"]
+
+	call := func(header http.Header) string {
+		request, err := http.NewRequest(http.MethodPost, endpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whereami"}}`))
+		require.NoError(t, err)
+		request.Header = header
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var response struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.NoError(t, mcp.DecodeContents(&response.Result))
+		require.Len(t, response.Result.Content, 1)
+		return response.Result.Content[0].(mcp.TextContent).Text
+	}
+
+	// Values of the stream reach handlers unless the message overrides them
+	header = http.Header{}
+	header.Set("X-Trace", "abc")
+	assert.Equal(t, "acme/abc", call(header))
+	header.Set("X-Tenant", "other")
+	assert.Equal(t, "other/abc", call(header))
+}
+
+func TestSSEServerForwardedHeaders(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "headers", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			data, err := json.Marshal(RequestHeaders(ctx))
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(string(data))}}, nil
+		})
+	_, testServer := NewTestServer(mcpServer,
+		WithForwardedHeaders("x-request-id", "Traceparent"),
+		WithResponseDelivery(ResponseInBody))
+	t.Cleanup(testServer.Close)
+
+	stream := openSSEStream(t, testServer.URL+"/sse", nil)
+	request, err := http.NewRequest(http.MethodPost, stream.next(t)["data"],
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"headers"}}`))
+	require.NoError(t, err)
+	request.Header.Set("X-Request-Id", "req-1")
+	request.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.NoError(t, mcp.DecodeContents(&response.Result))
+	require.Len(t, response.Result.Content, 1)
+	// Only allowed headers that were sent are exposed
+	assert.JSONEq(t, `{"X-Request-Id": ["req-1"]}`,
+		response.Result.Content[0].(mcp.TextContent).Text)
+
+	assert.Nil(t, RequestHeaders(context.Background()))
+}
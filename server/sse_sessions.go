@@ -0,0 +1,31 @@
+package server
+
+import "slices"
+
+// WithOnSessionRegistered calls fn when a client opens a session, before
+// it learns the message endpoint
+func WithOnSessionRegistered(fn func(sessionID string)) SSEOption {
+	return func(s *SSEServer) {
+		s.onSessionRegistered = fn
+	}
+}
+
+// WithOnSessionClosed calls fn once a session is over, whether its client
+// went away, it was evicted or the server shut down. With event replay a
+// dropped stream ends its session only once the replay window passes.
+func WithOnSessionClosed(fn func(sessionID string)) SSEOption {
+	return func(s *SSEServer) {
+		s.onSessionClosed = fn
+	}
+}
+
+// SessionIDs returns the IDs of the active sessions, sorted
+func (s *SSEServer) SessionIDs() []string {
+	var ids []string
+	s.sessions.Range(func(key, value any) bool {
+		ids = append(ids, key.(string))
+		return true
+	})
+	slices.Sort(ids)
+	return ids
+}
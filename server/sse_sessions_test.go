@@ -0,0 +1,40 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEServerSessionCallbacks(t *testing.T) {
+	registered := make(chan string, 2)
+	closed := make(chan string, 2)
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"),
+		WithOnSessionRegistered(func(sessionID string) { registered <- sessionID }),
+		WithOnSessionClosed(func(sessionID string) { closed <- sessionID }),
+	)
+	t.Cleanup(testServer.Close)
+
+	first := openSSEStream(t, testServer.URL+"/sse", nil)
+	firstID := strings.Split(first.next(t)["data"], "sessionId=")[1]
+	second := openSSEStream(t, testServer.URL+"/sse", nil)
+	secondID := strings.Split(second.next(t)["data"], "sessionId=")[1]
+
+	assert.ElementsMatch(t, []string{firstID, secondID}, []string{<-registered, <-registered})
+	expected := []string{firstID, secondID}
+	if secondID < firstID {
+		expected = []string{secondID, firstID}
+	}
+	assert.Equal(t, expected, sseServer.SessionIDs())
+
+	first.cancel()
+	select {
+	case sessionID := <-closed:
+		assert.Equal(t, firstID, sessionID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("session not closed")
+	}
+	assert.Equal(t, []string{secondID}, sseServer.SessionIDs())
+}
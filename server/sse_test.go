@@ -3,14 +3,21 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/huangyul/go-mcp/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSSEConnection(t *testing.T) {
@@ -134,12 +141,13 @@ func TestInvalidSession(t *testing.T) {
 	assert.NoError(t, err)
 	defer resp.Body.Close()
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 
 	var response JSONRPCResponse
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.NotNil(t, response.Error)
-	assert.Equal(t, -32602, response.Error.Code)
+	assert.Equal(t, mcp.InvalidParams, response.Error.Code)
 
 	// Test invalid session ID
 	resp, err = http.Post(
@@ -154,7 +162,7 @@ func TestInvalidSession(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.NotNil(t, response.Error)
-	assert.Equal(t, -32602, response.Error.Code)
+	assert.Equal(t, mcp.InvalidParams, response.Error.Code)
 }
 
 func TestMethodNotAllowed(t *testing.T) {
@@ -172,7 +180,225 @@ func TestMethodNotAllowed(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.NotNil(t, response.Error)
-	assert.Equal(t, -32600, response.Error.Code)
+	assert.Equal(t, mcp.InvalidRequest, response.Error.Code)
+}
+
+type userKey struct{}
+
+// unflushableWriter hides http.Flusher like many logging middlewares do,
+// but can be unwrapped
+type unflushableWriter struct {
+	http.ResponseWriter
+}
+
+func (w unflushableWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestSSEServerMiddleware(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	mcpServer.HandleCallTool(func(
+		ctx context.Context,
+		name string,
+		arguments map[string]interface{},
+	) (*mcp.CallToolResult, error) {
+		user, _ := ctx.Value(userKey{}).(string)
+		return &mcp.CallToolResult{
+			Content: []interface{}{mcp.TextContent{Type: "text", Text: user}},
+		}, nil
+	})
+
+	sseServer := NewSSEServer(mcpServer, "", WithResponseDelivery(ResponseInBody))
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "https://example.com")
+			ctx := context.WithValue(r.Context(), userKey{}, "alice")
+			next.ServeHTTP(unflushableWriter{w}, r.WithContext(ctx))
+		})
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp/", http.StripPrefix("/mcp", auth(sseServer)))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+	sseServer.baseURL = testServer.URL + "/mcp"
+
+	resp, err := http.Get(testServer.URL + "/mcp/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	reader := bufio.NewReader(resp.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	endpoint := strings.TrimSpace(strings.TrimPrefix(dataLine, "data: "))
+	assert.True(t, strings.HasPrefix(endpoint, testServer.URL+"/mcp/message?sessionId="))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`
+	postResp, err := http.Post(endpoint, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer postResp.Body.Close()
+
+	var response struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(postResp.Body).Decode(&response))
+	require.NoError(t, mcp.DecodeContents(&response.Result))
+	require.Len(t, response.Result.Content, 1)
+	assert.Equal(t, "alice", response.Result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestSSEServerEndpointPaths(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	sseServer, testServer := NewTestServer(mcpServer,
+		WithBasePath("api/v1/mcp/"),
+		WithSSEEndpoint("events"),
+		WithMessageEndpoint("/messages"),
+	)
+	defer testServer.Close()
+	assert.Equal(t, "/api/v1/mcp/events", sseServer.SSEPath())
+	assert.Equal(t, "/api/v1/mcp/messages", sseServer.MessagePath())
+
+	resp, err := http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(testServer.URL + "/api/v1/mcp/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	endpoint := strings.TrimSpace(strings.TrimPrefix(dataLine, "data: "))
+	assert.True(t, strings.HasPrefix(endpoint, testServer.URL+"/api/v1/mcp/messages?sessionId="))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	postResp, err := http.Post(endpoint, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer postResp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, postResp.StatusCode)
+}
+
+func TestSSEServerSeparateHandlers(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	sseServer := NewSSEServer(mcpServer, "", WithMessageEndpoint("/rpc"))
+	mux := http.NewServeMux()
+	mux.Handle("GET /stream", sseServer.SSEHandler())
+	mux.Handle("POST /rpc", sseServer.MessageHandler())
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+	sseServer.baseURL = testServer.URL
+
+	resp, err := http.Get(testServer.URL + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	endpoint := strings.TrimSpace(strings.TrimPrefix(dataLine, "data: "))
+	assert.True(t, strings.HasPrefix(endpoint, testServer.URL+"/rpc?sessionId="))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	postResp, err := http.Post(endpoint, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer postResp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, postResp.StatusCode)
+
+	// Handler serves both endpoints
+	assert.Equal(t, http.Handler(sseServer), sseServer.Handler())
+}
+
+func TestSSEServerStartTLS(t *testing.T) {
+	// Borrow the certificate of an httptest server and the client trusting it
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	tlsConfig := &tls.Config{Certificates: certServer.TLS.Certificates}
+	httpClient := certServer.Client()
+	certServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	sseServer := NewSSEServer(NewDefaultServer("test", "1.0.0"), "https://"+addr, WithTLSConfig(tlsConfig))
+	started := make(chan error, 1)
+	go func() { started <- sseServer.StartTLS(addr, "", "") }()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		sseServer.Shutdown(ctx)
+		assert.ErrorIs(t, <-started, http.ErrServerClosed)
+	}()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = httpClient.Get("https://" + addr + "/sse")
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	_, _ = reader.ReadString('\n')
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, dataLine, "https://"+addr+"/message?sessionId=")
+}
+
+func TestSSEServerDerivedEndpoint(t *testing.T) {
+	sseServer := NewSSEServer(NewDefaultServer("test", "1.0.0"), "")
+	mux := http.NewServeMux()
+	mux.Handle("/mcp/", http.StripPrefix("/mcp", sseServer))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	endpoint := func(header http.Header) string {
+		request, err := http.NewRequest(http.MethodGet, testServer.URL+"/mcp/sse", nil)
+		require.NoError(t, err)
+		request.Header = header
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		_, _ = reader.ReadString('\n')
+		dataLine, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return strings.TrimSpace(strings.TrimPrefix(dataLine, "data: "))
+	}
+
+	assert.True(t, strings.HasPrefix(endpoint(http.Header{}), testServer.URL+"/mcp/message?sessionId="))
+
+	forwarded := http.Header{}
+	forwarded.Set("X-Forwarded-Proto", "https")
+	forwarded.Set("X-Forwarded-Host", "mcp.example.com, proxy.internal")
+	assert.True(t, strings.HasPrefix(endpoint(forwarded), "https://mcp.example.com/mcp/message?sessionId="))
+}
+
+func TestSSEServerShutdownCloseEvent(t *testing.T) {
+	sseServer, testServer := NewTestServer(NewDefaultServer("test", "1.0.0"))
+	t.Cleanup(testServer.Close)
+
+	stream := openSSEStream(t, testServer.URL+"/sse", nil)
+	sessionID := strings.Split(stream.next(t)["data"], "sessionId=")[1]
+
+	// Events queued before the shutdown still go out ahead of the close
+	require.NoError(t, sseServer.SendEventToSession(sessionID, notification{JSONRPC: "2.0", Method: "notifications/test"}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, sseServer.Shutdown(ctx))
+
+	assert.Equal(t, "message", stream.next(t)["event"])
+	assert.Equal(t, map[string]string{"event": "close", "data": "shutdown"}, stream.next(t))
+	_, err := stream.reader.ReadString('\n')
+	assert.ErrorIs(t, err, io.EOF)
 }
 
 // Helper functions
@@ -205,13 +431,11 @@ func sendJSONRPCRequest(
 	assert.NoError(t, err)
 	defer resp.Body.Close()
 
+	// The response arrives on the stream
 	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
-
-	var response JSONRPCResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	body, err := io.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.Equal(t, request.ID, response.ID)
-	assert.Nil(t, response.Error)
+	assert.Empty(t, body)
 }
 
 func verifyJSONRPCResponse(
@@ -236,3 +460,114 @@ func verifyJSONRPCResponse(
 		t.Fatal("Timeout waiting for SSE message")
 	}
 }
+
+func TestSSEServerShutdownDrains(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	sseServer, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			<-release
+			return &mcp.CallToolResult{}, nil
+		})
+
+	sessionID, _ := connectSSE(t, testServer.URL)
+	post := func(body string) int {
+		resp, err := http.Post(
+			fmt.Sprintf("%s/message?sessionId=%s", testServer.URL, sessionID),
+			"application/json",
+			strings.NewReader(body),
+		)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	called := make(chan int, 1)
+	go func() {
+		called <- post(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`)
+	}()
+	<-started
+
+	// A deadline cuts the drain short
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- sseServer.Shutdown(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return post(`{"jsonrpc":"2.0","id":2,"method":"ping"}`) == http.StatusServiceUnavailable
+	}, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, <-shutdown, context.DeadlineExceeded)
+
+	close(release)
+	assert.Equal(t, http.StatusAccepted, <-called)
+}
+
+func TestSSEServerShutdownWaitsForHandlers(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	sseServer, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			<-release
+			return &mcp.CallToolResult{}, nil
+		})
+
+	sessionID, messages := connectSSE(t, testServer.URL)
+	go postMessage(t, testServer.URL, sessionID,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`)
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- sseServer.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the handler finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, float64(1), nextMessage(t, messages)["id"])
+	assert.NoError(t, <-shutdown)
+}
+
+func TestSSEServerNotifications(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	received := make(chan any, 1)
+	mcpServer.HandleNotification("custom", func(ctx context.Context, args any) (any, error) {
+		received <- args
+		return nil, nil
+	})
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	sessionID, messages := connectSSE(t, testServer.URL)
+	postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/custom","params":{"a":1}}`)
+	select {
+	case args := <-received:
+		assert.JSONEq(t, `{"a":1}`, string(args.(json.RawMessage)))
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification did not reach its handler")
+	}
+
+	// The next message on the stream answers the ping, not the notification
+	postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","id":"ping","method":"ping"}`)
+	assert.Equal(t, "ping", nextMessage(t, messages)["id"])
+
+	// Messages with neither a method nor an ID are invalid
+	postMessage(t, testServer.URL, sessionID, `{"jsonrpc":"2.0","params":{}}`)
+	response := nextMessage(t, messages)
+	assert.Nil(t, response["id"])
+	assert.Equal(t, float64(-32600), response["error"].(map[string]any)["code"])
+}
@@ -7,103 +7,363 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
 )
 
+// stdioDrainTimeout bounds how long a stdio server waits for running
+// handlers once it stops reading
+const stdioDrainTimeout = 10 * time.Second
+
+// errStdioNotListening is returned for messages sent before Listen
+var errStdioNotListening = errors.New("stdio server is not listening")
+
+// stdioOrderedQueue is how many requests of a sequential session may wait
+// for the one being handled before reading blocks
+const stdioOrderedQueue = 64
+
+// StdioServer serves an MCPServer over a pair of streams, one JSON-RPC
+// message per line or framed with Content-Length headers, see
+// WithStdioFraming
 type StdioServer struct {
-	server    MCPServer
-	signChan  chan os.Signal
-	errLogger *log.Logger
-	done      chan struct{}
+	server   MCPServer
+	logger   *slog.Logger
+	out      io.Writer
+	done     chan struct{}
+	writeMu  sync.Mutex
+	stopOnce sync.Once
+	drain    drain
+	// maxLineLength bounds the size of a message, see WithMaxLineLength
+	maxLineLength int
+	// concurrency bounds the requests running at once, see
+	// WithStdioConcurrency
+	concurrency int
+	contextFunc StdioContextFunc
+	framing     StdioFraming
+	// contentLength switches writes to Content-Length framing
+	contentLength atomic.Bool
 }
 
-func ServeStdio(server MCPServer) error {
-	s := &StdioServer{
-		server:    server,
-		signChan:  make(chan os.Signal, 1),
-		errLogger: log.New(os.Stderr, "", log.LstdFlags),
-		done:      make(chan struct{}),
-	}
+// StdioOption configures a StdioServer
+type StdioOption func(*StdioServer)
 
-	signal.Notify(s.signChan, syscall.SIGINT, syscall.SIGTERM)
+// StdioContextFunc derives the context of a message read by a
+// StdioServer, for passing per-process data such as credentials,
+// environment or feature flags on to handlers
+type StdioContextFunc func(ctx context.Context) context.Context
 
-	go func() {
-		<-s.signChan
-		close(s.done)
-	}()
+// WithStdioContextFunc applies fn to the context of every message before
+// it is handled
+func WithStdioContextFunc(fn StdioContextFunc) StdioOption {
+	return func(s *StdioServer) {
+		s.contextFunc = fn
+	}
+}
 
-	return s.serve()
+// NewStdioServer returns a server for serving server with Listen
+func NewStdioServer(server MCPServer, opts ...StdioOption) *StdioServer {
+	s := &StdioServer{
+		server:        server,
+		logger:        defaultStdioLogger(),
+		done:          make(chan struct{}),
+		maxLineLength: defaultMaxMessageSize,
+		concurrency:   defaultStdioConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeStdio serves server on standard input and output until input ends
+// or the process gets SIGINT or SIGTERM
+func ServeStdio(server MCPServer, opts ...StdioOption) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return NewStdioServer(server, opts...).Listen(ctx, os.Stdin, os.Stdout)
 }
 
-func (s *StdioServer) serve() error {
+// Listen reads messages from in and writes the responses to out until in
+// ends or ctx is done, and then waits up to stdioDrainTimeout for the
+// handlers still running to write their responses. It installs no signal
+// handlers; the caller decides when to stop by cancelling ctx. Handlers
+// see the values of ctx. A server listens once.
+func (s *StdioServer) Listen(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.writeMu.Lock()
+	s.out = out
+	s.writeMu.Unlock()
+	reader := bufio.NewReader(in)
 
-	reader := bufio.NewReader(os.Stdin)
+	// Handlers outlive reading, until they are drained, and see the
+	// values of ctx
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
+	if tracker, ok := s.server.(sessionTracker); ok {
+		if withOptions, ok := tracker.(sessionOptionsTracker); ok {
+			withOptions.registerSessionWith(stdioSessionID, s.writeResponse, sessionOptions{close: s.stop})
+		} else {
+			tracker.registerSession(stdioSessionID, s.writeResponse)
+		}
+		defer tracker.unregisterSession(stdioSessionID)
+	}
+	defer func() {
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), stdioDrainTimeout)
+		defer cancelDrain()
+		if err := s.drain.wait(drainCtx); err != nil {
+			s.logger.Error("failed to drain requests", "error", err)
+		}
+	}()
+
+	// Requests of a sequential session are handled one at a time, in the
+	// order they were read
+	ordered := make(chan string, stdioOrderedQueue)
+	defer close(ordered)
 	go func() {
-		<-s.done
-		cancel()
+		for line := range ordered {
+			s.handle(handlerCtx, line)
+			s.drain.leave()
+		}
 	}()
 
+	// Requests of a concurrent session take a slot while they run
+	var slots chan struct{}
+	if s.concurrency > 0 {
+		slots = make(chan struct{}, s.concurrency)
+	}
+
+	lines := s.readLines(ctx, reader)
 	for {
+		var read readResult
 		select {
 		case <-ctx.Done():
 			return nil
-		default:
+		case read = <-lines:
+		}
 
-			readChan := make(chan string, 1)
-			errChan := make(chan error, 1)
+		if errors.Is(read.err, errMessageTooLarge) {
+			s.writeError(nil, mcp.InvalidRequest, read.err.Error())
+			continue
+		}
+		if read.framed && s.framing == FramingAuto {
+			s.contentLength.Store(true)
+		}
+		// A last line may end without a newline
+		if strings.TrimSpace(read.line) != "" {
+			if !s.dispatch(ctx, handlerCtx, read.line, ordered, slots) {
+				return nil
+			}
+		}
+		if errors.Is(read.err, io.EOF) {
+			return nil
+		}
+		if read.err != nil {
+			s.logger.Error("failed to read input", "error", read.err)
+			return read.err
+		}
+	}
+}
 
-			go func() {
-				line, err := reader.ReadString('\n')
-				if err != nil {
-					errChan <- err
-					return
-				}
-				readChan <- line
-			}()
+// readResult is a message read by readLines, or the error that ended
+// reading
+type readResult struct {
+	line   string
+	framed bool
+	err    error
+}
 
+// readLines reads messages in a single goroutine until reading fails or
+// ctx is done. A message too long is reported and reading goes on. The goroutine
+// only outlives ctx while it is blocked reading, until in returns.
+func (s *StdioServer) readLines(ctx context.Context, reader *bufio.Reader) <-chan readResult {
+	lines := make(chan readResult)
+	go func() {
+		for {
+			line, framed, err := readMessage(reader, s.maxLineLength)
 			select {
+			case lines <- readResult{line: line, framed: framed, err: err}:
 			case <-ctx.Done():
-				return nil
-			case err := <-errChan:
-				if errors.Is(err, io.EOF) {
-					return nil
-				}
-				s.errLogger.Printf("Error reading input: %v", err)
-				return err
-			case line := <-readChan:
-				if err := s.handleMessage(ctx, line); err != nil {
-					if err == io.EOF {
-						return nil
-					}
-					s.errLogger.Printf("Error handling message: %v", err)
-				}
+				return
+			}
+			if err != nil && !errors.Is(err, errMessageTooLarge) {
+				return
 			}
 		}
+	}()
+	return lines
+}
+
+// dispatch hands a line over to be handled, taking a slot for it when
+// requests are bounded. It returns false once the server stops.
+func (s *StdioServer) dispatch(
+	ctx, handlerCtx context.Context,
+	line string,
+	ordered chan<- string,
+	slots chan struct{},
+) bool {
+	if !s.drain.enter() {
+		return false
+	}
+	if s.sequential() && isRequest(line) {
+		ordered <- line
+		return true
+	}
+	bounded := slots != nil && isRequest(line)
+	if bounded {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			s.drain.leave()
+			return false
+		}
+	}
+	// Requests run concurrently so that a notifications/cancelled can
+	// reach the request it refers to, and a slow request does not hold up
+	// the others. Responses are written whole, one at a time, in the order
+	// they are ready.
+	go func() {
+		defer s.drain.leave()
+		if bounded {
+			defer func() { <-slots }()
+		}
+		s.handle(handlerCtx, line)
+	}()
+	return true
+}
+
+// stop makes Listen stop reading
+func (s *StdioServer) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// handle handles one line and logs what went wrong
+func (s *StdioServer) handle(ctx context.Context, line string) {
+	if s.contextFunc != nil {
+		ctx = s.contextFunc(ctx)
+	}
+	if err := s.handleMessage(ctx, line); err != nil && !errors.Is(err, io.EOF) {
+		s.logger.Error("failed to handle message", "session", stdioSessionID, "error", err)
+	}
+}
+
+// sequential reports whether requests of the client must be handled one
+// at a time
+func (s *StdioServer) sequential() bool {
+	sequencer, ok := s.server.(sessionSequencer)
+	return ok && sequencer.sequential(stdioSessionID)
+}
+
+// isRequest reports whether line holds a request or a batch, as opposed to
+// a notification or a response to the server
+func isRequest(line string) bool {
+	if _, isBatch, _ := splitBatch([]byte(line)); isBatch {
+		return true
+	}
+	var message struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal([]byte(line), &message); err != nil {
+		return false
 	}
+	return message.Method != "" && message.ID != nil
 }
 
 func (s *StdioServer) handleMessage(ctx context.Context, line string) error {
+	if batch, isBatch, err := splitBatch([]byte(line)); isBatch {
+		if err != nil {
+			s.writeError(nil, mcp.ParseError, "Parse error")
+			return fmt.Errorf("failed to parse JSON-RPC batch: %v", err)
+		}
+		response := handleBatch(batch, func(request JSONRPCRequest, message json.RawMessage) *JSONRPCResponse {
+			return s.handleRequest(ctx, request, message)
+		})
+		if response == nil {
+			return nil
+		}
+		if err := s.writeResponse(response); err != nil {
+			return fmt.Errorf("failed to write batch response: %w", err)
+		}
+		return nil
+	}
+
 	var request JSONRPCRequest
 	if err := json.Unmarshal([]byte(line), &request); err != nil {
-		s.writeError(nil, -32700, "Parse error")
+		s.writeError(nil, mcp.ParseError, "Parse error")
 		return fmt.Errorf("failed to parse JSON-RPC request: %v", err)
 	}
+	correlationID := requestCorrelationID(request.Params)
+	ctx = withCorrelationID(ctx, correlationID)
+	response := s.handleRequest(ctx, request, json.RawMessage(line))
+	if response == nil {
+		return nil
+	}
+	if err := s.writeResponse(response); err != nil {
+		return fmt.Errorf("[%s] failed to write response: %w", correlationID, err)
+	}
+	return nil
+}
+
+// handleRequest dispatches one message. Responses to requests of the
+// server are handed back to it, and they and notifications get no
+// response.
+func (s *StdioServer) handleRequest(
+	ctx context.Context,
+	request JSONRPCRequest,
+	message json.RawMessage,
+) *JSONRPCResponse {
+	// Responses to requests of the server carry no method
+	if request.Method == "" && request.ID != nil {
+		if tracker, ok := s.server.(sessionTracker); ok {
+			tracker.handleClientResponse(stdioSessionID, message)
+		}
+		return nil
+	}
+	// Messages without a method or an ID are not JSON-RPC messages
+	if request.Method == "" {
+		return invalidRequest(nil)
+	}
 
+	ctx = withSessionID(ctx, stdioSessionID)
+	ctx = withNotifier(ctx, s.notify)
 	response := s.server.Request(ctx, request)
+	// Notifications are handled but get no response
+	if isNotification(request) {
+		return nil
+	}
+	return &response
+}
 
-	return s.writeResponse(response)
+// notify sends a notification to the client
+func (s *StdioServer) notify(method string, params any) error {
+	return s.writeResponse(notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
 }
 
 func (s *StdioServer) writeError(
 	id any,
-	code int,
+	code mcp.ErrorCode,
 	message string) {
 	response := JSONRPCResponse{
 		JSONRPC: "2.0",
@@ -117,13 +377,40 @@ func (s *StdioServer) writeError(
 	s.writeResponse(response)
 }
 
-func (s *StdioServer) writeResponse(response JSONRPCResponse) error {
-	responseBytes, err := json.Marshal(response)
+// Send writes a message the server initiates, such as a notification, to
+// the client. Notifications, log entries and requests of DefaultServer,
+// like sampling, reach a stdio client the same way without it.
+func (s *StdioServer) Send(message any) error {
+	if err := s.writeResponse(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// writeResponse writes one message line. Writes are serialized as
+// requests are handled concurrently, and messages the server initiates
+// are written between responses.
+func (s *StdioServer) writeResponse(response any) error {
+	message, err := encodeMessage(response)
 	if err != nil {
 		return err
 	}
 
-	responseBytes = append(responseBytes, '\n')
-	_, err = os.Stdout.Write(responseBytes)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.out == nil {
+		return errStdioNotListening
+	}
+	w := bufio.NewWriter(s.out)
+	if s.contentLength.Load() {
+		err = writeContentLength(w, message)
+	} else {
+		// End the line even when a stream fails, so the next message is read
+		err = message.writeTo(w)
+		w.WriteByte('\n')
+	}
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
 	return err
 }
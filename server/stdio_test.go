@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/huangyul/go-mcp/mcp"
 )
 
 type testStdioServer struct {
@@ -27,7 +30,7 @@ type testStdioServer struct {
 	wg         sync.WaitGroup
 }
 
-func setupTestStdioServer(t *testing.T) *testStdioServer {
+func setupTestStdioServer(t *testing.T, opts ...StdioOption) *testStdioServer {
 	t.Helper()
 
 	origStdin, origStdout, origStderr := os.Stdin, os.Stdout, os.Stderr
@@ -69,7 +72,7 @@ func setupTestStdioServer(t *testing.T) *testStdioServer {
 	ts.wg.Add(1)
 	go func() {
 		defer ts.wg.Done()
-		if err := ServeStdio(server); err != nil {
+		if err := ServeStdio(server, opts...); err != nil {
 			t.Logf("ServeStdio returned error: %v", err)
 		}
 	}()
@@ -225,3 +228,356 @@ func TestStdioServerGracefulShutdown(t *testing.T) {
 		t.Fatal("Server failed to shut down gracefully")
 	}
 }
+
+func TestStdioServerDrainsOnEOF(t *testing.T) {
+	ts := setupTestStdioServer(t)
+	defer ts.cleanup(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ts.server.AddTool(mcp.ToolDefinition{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			<-release
+			return &mcp.CallToolResult{}, ctx.Err()
+		})
+
+	_, err := ts.stdinW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}` + "\n"))
+	if err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+	<-started
+	ts.stdinW.Close()
+
+	done := make(chan struct{})
+	go func() {
+		ts.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("server stopped before the running handler finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	scanner := bufio.NewScanner(ts.stdoutR)
+	if !scanner.Scan() {
+		t.Fatalf("no response: %v", scanner.Err())
+	}
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if err := mcp.DecodeContents(&resp.Result); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("handler failed: %v", resp.Error.Message)
+	}
+	<-done
+}
+
+type listenKey struct{}
+
+func TestStdioServerListen(t *testing.T) {
+	mcpServer := NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "value", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			value, _ := ctx.Value(listenKey{}).(string)
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(value)}}, nil
+		})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), listenKey{}, "embedded"))
+	done := make(chan error, 1)
+	go func() {
+		done <- NewStdioServer(mcpServer).Listen(ctx, inR, outW)
+	}()
+
+	go inW.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"value"}}` + "\n"))
+	scanner := bufio.NewScanner(outR)
+	if !scanner.Scan() {
+		t.Fatalf("no response: %v", scanner.Err())
+	}
+	var resp struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if err := mcp.DecodeContents(&resp.Result); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if len(resp.Result.Content) != 1 ||
+		resp.Result.Content[0].(mcp.TextContent).Text != "embedded" {
+		t.Fatalf("handler did not see the values of the context: %v", resp.Result.Content)
+	}
+
+	// The caller stops the server, the input stays open
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Listen returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen did not return once its context was cancelled")
+	}
+	inW.Close()
+}
+
+type flagKey struct{}
+
+func TestStdioServerContextFunc(t *testing.T) {
+	mcpServer := NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "flag", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			flag, _ := ctx.Value(flagKey{}).(string)
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(flag)}}, nil
+		})
+	server := NewStdioServer(mcpServer, WithStdioContextFunc(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, flagKey{}, "beta")
+	}))
+
+	var out strings.Builder
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"flag"}}` + "\n")
+	if err := server.Listen(context.Background(), in, &out); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+
+	var resp struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if err := mcp.DecodeContents(&resp.Result); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if len(resp.Result.Content) != 1 ||
+		resp.Result.Content[0].(mcp.TextContent).Text != "beta" {
+		t.Fatalf("handler did not see the value of the context func: %v", resp.Result.Content)
+	}
+}
+
+func TestStdioServerConcurrency(t *testing.T) {
+	run := func(t *testing.T, opts ...StdioOption) (release func(), write func(string), lines chan string) {
+		mcpServer := NewDefaultServer("test-server", "1.0.0")
+		started := make(chan struct{})
+		releaseCh := make(chan struct{})
+		mcpServer.AddTool(mcp.ToolDefinition{Name: "slow", InputSchema: mcp.ToolSchema{Type: "object"}},
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				close(started)
+				<-releaseCh
+				return &mcp.CallToolResult{}, nil
+			})
+
+		inR, inW := io.Pipe()
+		outR, outW := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			NewStdioServer(mcpServer, opts...).Listen(context.Background(), inR, outW)
+		}()
+		lines = make(chan string, 10)
+		go func() {
+			scanner := bufio.NewScanner(outR)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		t.Cleanup(func() {
+			inW.Close()
+			<-done
+			outW.Close()
+		})
+
+		write = func(line string) {
+			if _, err := inW.Write([]byte(line + "\n")); err != nil {
+				t.Fatalf("failed to write request: %v", err)
+			}
+		}
+		write(`{"jsonrpc":"2.0","id":"slow","method":"tools/call","params":{"name":"slow"}}`)
+		<-started
+		return func() { close(releaseCh) }, write, lines
+	}
+
+	id := func(t *testing.T, lines chan string) any {
+		t.Helper()
+		select {
+		case line := <-lines:
+			var resp JSONRPCResponse
+			if err := json.Unmarshal([]byte(line), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			return resp.ID
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for response")
+			return nil
+		}
+	}
+
+	t.Run("ping is not held up by a slow call", func(t *testing.T) {
+		release, write, lines := run(t)
+		write(`{"jsonrpc":"2.0","id":"ping","method":"ping"}`)
+		if got := id(t, lines); got != "ping" {
+			t.Fatalf("expected the ping response first, got %v", got)
+		}
+		release()
+		if got := id(t, lines); got != "slow" {
+			t.Fatalf("expected the slow response, got %v", got)
+		}
+	})
+
+	t.Run("requests wait for a slot", func(t *testing.T) {
+		release, write, lines := run(t, WithStdioConcurrency(1))
+		go write(`{"jsonrpc":"2.0","id":"ping","method":"ping"}`)
+		select {
+		case line := <-lines:
+			t.Fatalf("request handled beyond the limit: %s", line)
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		if got := id(t, lines); got != "slow" {
+			t.Fatalf("expected the slow response first, got %v", got)
+		}
+		if got := id(t, lines); got != "ping" {
+			t.Fatalf("expected the ping response, got %v", got)
+		}
+	})
+}
+
+func TestStdioServerOutbound(t *testing.T) {
+	mcpServer := NewDefaultServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.ToolDefinition{Name: "ask", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := RequestSampling(ctx, mcp.CreateMessageRequest{
+				Method: "sampling/createMessage",
+				Params: mcp.CreateMessageRequestParams{MaxTokens: 10, Messages: []mcp.SamplingMessage{}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{Content: []interface{}{mcp.NewTextContent(result.Model)}}, nil
+		})
+	server := NewStdioServer(mcpServer)
+	if err := server.Send(notification{JSONRPC: "2.0", Method: "notifications/message"}); err == nil {
+		t.Fatal("expected an error sending before Listen")
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.Listen(context.Background(), inR, outW)
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		<-done
+		outW.Close()
+	})
+	scanner := bufio.NewScanner(outR)
+	next := func() map[string]any {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatalf("no message: %v", scanner.Err())
+		}
+		var message map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatalf("failed to unmarshal message: %v", err)
+		}
+		return message
+	}
+	write := func(line string) {
+		go inW.Write([]byte(line + "\n"))
+	}
+
+	write(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{"sampling":{}},"protocolVersion":"2024-11-05"}}`)
+	next()
+
+	go server.Send(notification{JSONRPC: "2.0", Method: "notifications/custom"})
+	if message := next(); message["method"] != "notifications/custom" {
+		t.Fatalf("expected the sent notification, got %v", message)
+	}
+
+	write(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ask"}}`)
+	sampling := next()
+	if sampling["method"] != "sampling/createMessage" {
+		t.Fatalf("expected a sampling request, got %v", sampling)
+	}
+	id, _ := json.Marshal(sampling["id"])
+	write(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","model":"test-model","content":{"type":"text","text":"hi"}}}`)
+	result := next()
+	content := result["result"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	if content["text"] != "test-model" {
+		t.Fatalf("expected the sampling result to reach the tool, got %v", result)
+	}
+}
+
+// failingReader returns its data and then err
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestStdioServerReadLoop(t *testing.T) {
+	// Blank lines are skipped and a last line without a newline is handled
+	var out strings.Builder
+	in := strings.NewReader("\n  \n" + `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	if err := NewStdioServer(NewDefaultServer("test-server", "1.0.0")).Listen(context.Background(), in, &out); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	if got := strings.Count(out.String(), "\n"); got != 1 {
+		t.Fatalf("expected one response, got %q", out.String())
+	}
+
+	// Read errors other than EOF end Listen with the error, once the
+	// lines read before are handled
+	out.Reset()
+	readErr := fmt.Errorf("broken pipe")
+	in2 := &failingReader{data: `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n", err: readErr}
+	err := NewStdioServer(NewDefaultServer("test-server", "1.0.0"), WithStdioErrorWriter(io.Discard)).
+		Listen(context.Background(), in2, &out)
+	if err != readErr {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"id":1`) {
+		t.Fatalf("expected the response to the line read, got %q", out.String())
+	}
+}
+
+func TestStdioServerNotifications(t *testing.T) {
+	mcpServer := NewDefaultServer("test-server", "1.0.0")
+	received := make(chan struct{}, 1)
+	mcpServer.HandleNotification("custom", func(ctx context.Context, args any) (any, error) {
+		received <- struct{}{}
+		return nil, nil
+	})
+
+	var out strings.Builder
+	in := strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/custom"}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n")
+	if err := NewStdioServer(mcpServer).Listen(context.Background(), in, &out); err != nil {
+		t.Fatalf("Listen returned error: %v", err)
+	}
+	select {
+	case <-received:
+	default:
+		t.Fatal("notification did not reach its handler")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("notifications were answered: %q", out.String())
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// ResourceStream is the contents of a resource read from Reader while the
+// response is written, instead of being held in memory. It is sent as blob
+// contents. The stdio and SSE transports encode it straight from Reader;
+// marshaling it any other way reads it whole. Reader is closed once read
+// if it is an io.Closer.
+type ResourceStream struct {
+	URI      string
+	MimeType string
+	Reader   io.Reader
+	// Size is the number of bytes to read from Reader, or -1 to read it to
+	// the end
+	Size int64
+
+	// placeholder stands in for the blob while a transport writes it
+	placeholder string
+}
+
+// StreamResourceHandlerFunc reads a resource registered with
+// AddStreamResource
+type StreamResourceHandlerFunc func(ctx context.Context, request mcp.ReadResourceRequest) (*ResourceStream, error)
+
+// AddStreamResource registers a resource that is too large to read into
+// memory, such as a multi-hundred-MB artifact. The stream handler returns
+// a reader that is encoded as the response is written. The stream gets
+// the URI and MIME type of the resource unless it sets its own.
+func (s *DefaultServer) AddStreamResource(resource mcp.Resource, handler StreamResourceHandlerFunc) {
+	s.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		stream, err := handler(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if stream.URI == "" {
+			stream.URI = request.Params.Uri
+		}
+		return &mcp.ReadResourceResult{Contents: []interface{}{stream}}, nil
+	})
+}
+
+func (r *ResourceStream) reader() io.Reader {
+	reader := r.Reader
+	if r.Size >= 0 {
+		reader = io.LimitReader(reader, r.Size)
+	}
+	return reader
+}
+
+func (r *ResourceStream) close() {
+	if closer, ok := r.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// MarshalJSON encodes the stream as blob contents. Unless a transport is
+// writing it, the stream is read whole.
+func (r *ResourceStream) MarshalJSON() ([]byte, error) {
+	blob := r.placeholder
+	if blob == "" {
+		defer r.close()
+		data, err := io.ReadAll(r.reader())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read resource stream: %w", err)
+		}
+		blob = base64.StdEncoding.EncodeToString(data)
+	}
+	return json.Marshal(mcp.BlobResourceContents{Uri: r.URI, MimeType: r.MimeType, Blob: blob})
+}
+
+// encodedMessage is a message marshaled with the blobs of its resource
+// streams left out, to be written in their place
+type encodedMessage struct {
+	data    []byte
+	streams []*ResourceStream
+}
+
+// encodeMessage marshals a message a transport sends
+func encodeMessage(message any) (*encodedMessage, error) {
+	streams := resourceStreams(message)
+	for _, stream := range streams {
+		stream.placeholder = "mcp-stream:" + uuid.New().String()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		for _, stream := range streams {
+			stream.close()
+		}
+		return nil, err
+	}
+	return &encodedMessage{data: data, streams: streams}, nil
+}
+
+// writeTo writes the message, encoding the blobs of its resource streams
+// as they are read
+func (m *encodedMessage) writeTo(w io.Writer) error {
+	defer func() {
+		for _, stream := range m.streams {
+			stream.close()
+		}
+	}()
+
+	data := m.data
+	for _, stream := range m.streams {
+		placeholder := []byte(`"` + stream.placeholder + `"`)
+		i := bytes.Index(data, placeholder)
+		if i < 0 {
+			return fmt.Errorf("resource stream %s missing from message", stream.URI)
+		}
+		// Keep the quotes around the placeholder
+		if _, err := w.Write(data[:i+1]); err != nil {
+			return err
+		}
+		encoder := base64.NewEncoder(base64.StdEncoding, w)
+		if _, err := io.Copy(encoder, stream.reader()); err != nil {
+			return fmt.Errorf("failed to read resource stream: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+		data = data[i+len(placeholder)-1:]
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// discard releases the streams of a message that will not be written
+func (m *encodedMessage) discard() {
+	for _, stream := range m.streams {
+		stream.close()
+	}
+}
+
+// resourceStreams returns the resource streams in the results of a
+// response or batch of responses, in the order they are marshaled
+func resourceStreams(message any) []*ResourceStream {
+	var responses []JSONRPCResponse
+	switch m := message.(type) {
+	case JSONRPCResponse:
+		responses = []JSONRPCResponse{m}
+	case *JSONRPCResponse:
+		responses = []JSONRPCResponse{*m}
+	case []JSONRPCResponse:
+		responses = m
+	}
+
+	var streams []*ResourceStream
+	for _, response := range responses {
+		result, ok := response.Result.(*mcp.ReadResourceResult)
+		if !ok {
+			continue
+		}
+		for _, contents := range result.Contents {
+			if stream, ok := contents.(*ResourceStream); ok {
+				streams = append(streams, stream)
+			}
+		}
+	}
+	return streams
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeTracker records whether it was closed
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestEncodeMessage(t *testing.T) {
+	first := &closeTracker{Reader: strings.NewReader("first resource")}
+	second := &closeTracker{Reader: bytes.NewReader(bytes.Repeat([]byte{0xff}, 100_000))}
+	batch := []JSONRPCResponse{
+		{JSONRPC: "2.0", ID: 1, Result: &mcp.ReadResourceResult{Contents: []interface{}{
+			&ResourceStream{URI: "file:///first", MimeType: "text/plain", Reader: first, Size: 5},
+		}}},
+		{JSONRPC: "2.0", ID: 2, Result: &mcp.ReadResourceResult{Contents: []interface{}{
+			mcp.TextResourceContents{Uri: "file:///inline", Text: "inline"},
+			&ResourceStream{URI: "file:///second", Reader: second, Size: -1},
+		}}},
+	}
+
+	message, err := encodeMessage(batch)
+	require.NoError(t, err)
+	assert.Less(t, len(message.data), 1000, "streams are not marshaled with the message")
+	var out bytes.Buffer
+	require.NoError(t, message.writeTo(&out))
+	assert.True(t, first.closed)
+	assert.True(t, second.closed)
+
+	var decoded []struct {
+		Result struct {
+			Contents []map[string]string `json:"contents"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, map[string]string{
+		"uri":      "file:///first",
+		"mimeType": "text/plain",
+		"blob":     base64.StdEncoding.EncodeToString([]byte("first")),
+	}, decoded[0].Result.Contents[0])
+	assert.Equal(t, "inline", decoded[1].Result.Contents[0]["text"])
+	blob, err := base64.StdEncoding.DecodeString(decoded[1].Result.Contents[1]["blob"])
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{0xff}, 100_000), blob)
+}
+
+func TestResourceStream_MarshalJSON(t *testing.T) {
+	reader := &closeTracker{Reader: strings.NewReader("data")}
+	data, err := json.Marshal(&ResourceStream{URI: "file:///data", Reader: reader, Size: -1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uri":"file:///data","blob":"ZGF0YQ=="}`, string(data))
+	assert.True(t, reader.closed)
+}
+
+func TestDefaultServer_AddStreamResource(t *testing.T) {
+	mcpServer := NewDefaultServer("test", "1.0.0")
+	artifact := bytes.Repeat([]byte("artifact "), 50_000)
+	mcpServer.AddStreamResource(mcp.Resource{
+		Uri:      "file:///artifact.bin",
+		Name:     "artifact",
+		MimeType: "application/octet-stream",
+	}, func(ctx context.Context, request mcp.ReadResourceRequest) (*ResourceStream, error) {
+		return &ResourceStream{Reader: bytes.NewReader(artifact), Size: int64(len(artifact))}, nil
+	})
+	_, testServer := NewTestServer(mcpServer)
+	t.Cleanup(testServer.Close)
+	sessionID, messages := connectSSE(t, testServer.URL)
+
+	resp, err := http.Post(
+		fmt.Sprintf("%s/message?sessionId=%s", testServer.URL, sessionID),
+		"application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"file:///artifact.bin"}}`),
+	)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	message := nextMessage(t, messages)
+	contents := message["result"].(map[string]any)["contents"].([]any)[0].(map[string]any)
+	assert.Equal(t, "file:///artifact.bin", contents["uri"])
+	assert.Equal(t, "application/octet-stream", contents["mimeType"])
+	blob, err := base64.StdEncoding.DecodeString(contents["blob"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, artifact, blob)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// errToolResult ends the span of a tool call whose result reports a failure
+var errToolResult = errors.New("tool call failed")
+
+// ToolHandlerFunc handles a call to a tool registered with AddTool
+type ToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// AddTool registers a tool together with its handler. The tool is listed
+// by tools/list and calls to it go to handler instead of the function set
+// with HandleCallTool. Adding a tool with the name of an existing one
+// replaces it.
+func (s *DefaultServer) AddTool(tool mcp.ToolDefinition, handler ToolHandlerFunc) {
+	s.checkToolName(tool.Name)
+	s.registry.update(RegistryKindTool, func() bool {
+		addTool(s.registry, tool, handler)
+		return true
+	})
+}
+
+// ReplaceTool swaps the definition and handler of a tool added with
+// AddTool. tools/list and tools/call see either both old or both new. It
+// reports false, and adds nothing, when there is no such tool.
+func (s *DefaultServer) ReplaceTool(tool mcp.ToolDefinition, handler ToolHandlerFunc) bool {
+	s.checkToolName(tool.Name)
+	return s.registry.update(RegistryKindTool, func() bool {
+		if _, ok := s.registry.entries[registryKey{RegistryKindTool, tool.Name}]; !ok {
+			return false
+		}
+		addTool(s.registry, tool, handler)
+		return true
+	})
+}
+
+// RemoveTool unregisters a tool added with AddTool. It reports whether the
+// tool was registered.
+func (s *DefaultServer) RemoveTool(name string) bool {
+	return s.registry.update(RegistryKindTool, func() bool {
+		return s.registry.remove(registryKey{RegistryKindTool, name})
+	})
+}
+
+// DeleteTools unregisters tools added with AddTool at once, so clients are
+// told the list changed a single time. Unknown names are ignored.
+func (s *DefaultServer) DeleteTools(names ...string) {
+	_ = s.BatchUpdate(func(batch *Batch) {
+		for _, name := range names {
+			batch.RemoveTool(name)
+		}
+	})
+}
+
+// addTool adds a tool and its handler to r, which the caller holds
+func addTool(r *Registry, tool mcp.ToolDefinition, handler ToolHandlerFunc) {
+	r.add(registryKey{RegistryKindTool, tool.Name}, &registryEntry{value: tool, handler: handler})
+}
+
+// registeredTool returns a tool added with AddTool and its handler
+func (s *DefaultServer) registeredTool(name string) (mcp.ToolDefinition, ToolHandlerFunc, bool) {
+	entry, ok := s.registry.entry(registryKey{RegistryKindTool, name})
+	if !ok {
+		return mcp.ToolDefinition{}, nil, false
+	}
+	handler, ok := entry.handler.(ToolHandlerFunc)
+	return entry.value.(mcp.ToolDefinition), handler, ok
+}
+
+// hasTools reports whether the server has tools of its own to advertise
+// to the session of the request
+func (s *DefaultServer) hasTools(ctx context.Context) bool {
+	return s.diagnostics || len(s.registry.Tools()) > 0 ||
+		clientSessionFromContext(ctx).hasTools()
+}
+
+// callTool calls the tool of the request through the tool middleware, in a
+// span of its own when tracing
+func (s *DefaultServer) callTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (result *mcp.CallToolResult, err error) {
+	ctx, span := s.startToolSpan(ctx, request.Params.Name)
+	defer func() {
+		spanErr := err
+		if spanErr == nil && result != nil && result.IsError {
+			spanErr = errToolResult
+		}
+		span.End(spanErr)
+	}()
+	return s.wrapTool(request.Params.Name, s.dispatchTool)(ctx, request)
+}
+
+// dispatchTool calls a diagnostic tool, a tool of the session of the
+// request or one added with AddTool, in that order, and otherwise the
+// function set with HandleCallTool. Arguments of tools added to the
+// session or with AddTool are validated against their schema first.
+func (s *DefaultServer) dispatchTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	name, arguments := request.Params.Name, request.Params.Arguments
+	if result, ok, err := s.callDiagnosticTool(ctx, name, arguments); ok {
+		return result, err
+	}
+	scoped, ok := clientSessionFromContext(ctx).tool(name)
+	tool, handler := scoped.tool, scoped.handler
+	if !ok {
+		tool, handler, ok = s.registeredTool(name)
+	}
+	if ok {
+		if err := s.validateArguments(tool, arguments); err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+	return s.handlers[mcp.MethodToolsCall].(CallToolFunc)(ctx, name, arguments)
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_AddTool(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	ctx := context.Background()
+
+	s.HandleCallTool(func(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{
+			Content: []interface{}{mcp.TextContent{Type: "text", Text: "fallback " + name}},
+		}, nil
+	})
+	s.AddTool(mcp.ToolDefinition{
+		Name:        "greet",
+		Description: "Greets someone",
+		InputSchema: mcp.ToolSchema{Type: "object"},
+	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{
+			Content: []interface{}{mcp.TextContent{
+				Type: "text",
+				Text: "hello " + request.Params.Arguments["name"].(string),
+			}},
+		}, nil
+	})
+
+	init := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, init.Error)
+	assert.NotNil(t, init.Result.(*mcp.InitializeResult).Capabilities.Tools)
+
+	list := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "tools/list"})
+	require.Nil(t, list.Error)
+	tools := list.Result.(*mcp.ListToolDefinitionsResult).Tools
+	require.Len(t, tools, 1)
+	assert.Equal(t, "greet", tools[0].Name)
+
+	greet := callTool(t, s, ctx, `{"name":"greet","arguments":{"name":"world"}}`)
+	require.Nil(t, greet.Error)
+	assert.Equal(t, "hello world", greet.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	other := callTool(t, s, ctx, `{"name":"other"}`)
+	require.Nil(t, other.Error)
+	assert.Equal(t, "fallback other", other.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	assert.True(t, s.RemoveTool("greet"))
+	assert.False(t, s.RemoveTool("greet"))
+	list = s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "tools/list"})
+	require.Nil(t, list.Error)
+	assert.Empty(t, list.Result.(*mcp.ListToolDefinitionsResult).Tools)
+	greet = callTool(t, s, ctx, `{"name":"greet","arguments":{"name":"world"}}`)
+	require.Nil(t, greet.Error)
+	assert.Equal(t, "fallback greet", greet.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+}
+
+func TestDefaultServer_DeleteAndReplaceTools(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	text := func(text string) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []interface{}{mcp.TextContent{Type: "text", Text: text}}}, nil
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		s.AddTool(mcp.ToolDefinition{Name: name, InputSchema: mcp.ToolSchema{Type: "object"}}, text(name))
+	}
+
+	var client recorder
+	s.registerSession("client", client.send)
+	ctx := withSessionID(context.Background(), "client")
+	init := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: json.RawMessage(`{
+			"capabilities": {},
+			"clientInfo": {"name": "test-client", "version": "1.0.0"},
+			"protocolVersion": "2024-11-05"
+		}`),
+	})
+	require.Nil(t, init.Error)
+
+	s.DeleteTools("a", "b", "missing")
+	assert.Equal(t, []string{"c"}, toolNames(t, s, ctx))
+	assert.Equal(t, []string{"notifications/tools/list_changed"}, client.methods())
+
+	assert.False(t, s.ReplaceTool(mcp.ToolDefinition{Name: "a", InputSchema: mcp.ToolSchema{Type: "object"}}, text("a2")))
+	assert.Equal(t, []string{"c"}, toolNames(t, s, ctx))
+
+	require.True(t, s.ReplaceTool(mcp.ToolDefinition{
+		Name:        "c",
+		Description: "Version two",
+		InputSchema: mcp.ToolSchema{Type: "object"},
+	}, text("c2")))
+	response := callTool(t, s, ctx, `{"name":"c"}`)
+	require.Nil(t, response.Error)
+	assert.Equal(t, "c2", response.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+	tool, _ := s.registry.Tool("c")
+	assert.Equal(t, "Version two", tool.Description)
+	assert.Len(t, client.methods(), 2)
+}
+
+func TestDefaultServer_ReplaceToolWhileServing(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	tool := func(version string) (mcp.ToolDefinition, ToolHandlerFunc) {
+		return mcp.ToolDefinition{Name: "tool", Description: version, InputSchema: mcp.ToolSchema{Type: "object"}},
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return &mcp.CallToolResult{Content: []interface{}{mcp.TextContent{Type: "text", Text: version}}}, nil
+			}
+	}
+	s.AddTool(tool("v0"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			s.ReplaceTool(tool(fmt.Sprintf("v%d", i)))
+		}
+	}()
+	for range 100 {
+		response := callTool(t, s, context.Background(), `{"name":"tool"}`)
+		require.Nil(t, response.Error)
+	}
+	<-done
+}
+
+func TestDefaultServer_AddRemoveToolRace(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	tool := mcp.ToolDefinition{Name: "tool", InputSchema: mcp.ToolSchema{Type: "object"}}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+
+	for range 200 {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.AddTool(tool, handler)
+		}()
+		go func() {
+			defer wg.Done()
+			s.RemoveTool("tool")
+		}()
+		wg.Wait()
+
+		// The listed tools and the callable ones agree
+		_, listed := s.registry.Tool("tool")
+		_, _, callable := s.registeredTool("tool")
+		require.Equal(t, listed, callable)
+	}
+}
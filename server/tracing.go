@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Tracer starts a span for every request the server handles and for every
+// tool it runs. Like the client Tracer it covers the small part of the
+// OpenTelemetry API the server needs, so an adapter over
+// go.opentelemetry.io/otel takes a few lines and the server itself does
+// not depend on it.
+type Tracer interface {
+	// Extract returns ctx with the trace context the client sent in the
+	// request _meta, for example a W3C traceparent entry, so the span of
+	// the request joins the trace of the client
+	Extract(ctx context.Context, carrier map[string]string) context.Context
+	// Start begins a span as a child of the span in ctx, if any
+	Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span is a single traced request or tool call
+type Span interface {
+	// End finishes the span, marking it failed when err is not nil
+	End(err error)
+}
+
+// WithTracer traces every request and tool call with t
+func WithTracer(t Tracer) ServerOption {
+	return func(s *DefaultServer) {
+		s.tracer = t
+	}
+}
+
+// startRequestSpan starts the span of a request, joined to the trace
+// context the client sent in _meta
+func (s *DefaultServer) startRequestSpan(
+	ctx context.Context,
+	request JSONRPCRequest,
+) (context.Context, Span) {
+	if s.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	if carrier := requestMeta(request.Params); len(carrier) > 0 {
+		ctx = s.tracer.Extract(ctx, carrier)
+	}
+	attributes := map[string]string{"mcp.method": request.Method}
+	if request.ID != nil {
+		attributes["mcp.request.id"] = fmt.Sprint(request.ID)
+	}
+	if sessionID := sessionIDFromContext(ctx); sessionID != "" {
+		attributes["mcp.session.id"] = sessionID
+	}
+	return s.tracer.Start(ctx, request.Method, attributes)
+}
+
+// startToolSpan starts the span of a tool call, as a child of the span of
+// the request
+func (s *DefaultServer) startToolSpan(ctx context.Context, name string) (context.Context, Span) {
+	if s.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return s.tracer.Start(ctx, "tool "+name, map[string]string{"mcp.tool.name": name})
+}
+
+// responseError returns the error a response failed with, for ending a span
+func responseError(response JSONRPCResponse) error {
+	if response.Error == nil {
+		return nil
+	}
+	return errors.New(response.Error.Message)
+}
+
+// requestMeta returns the string fields of the request _meta
+func requestMeta(params json.RawMessage) map[string]string {
+	var request struct {
+		Meta map[string]any `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &request) != nil {
+		return nil
+	}
+	meta := make(map[string]string)
+	for key, value := range request.Meta {
+		if s, ok := value.(string); ok {
+			meta[key] = s
+		}
+	}
+	return meta
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type traceKey struct{}
+
+type fakeSpan struct {
+	name       string
+	parent     string
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+func (s *fakeSpan) End(err error) {
+	s.err, s.ended = err, true
+}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return context.WithValue(ctx, traceKey{}, carrier["traceparent"])
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	parent, _ := ctx.Value(traceKey{}).(string)
+	span := &fakeSpan{name: name, parent: parent, attributes: attributes}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, traceKey{}, name), span
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	s := NewDefaultServer("test", "1.0.0", WithTracer(tracer)).(*DefaultServer)
+	s.AddTool(mcp.ToolDefinition{Name: "fail", InputSchema: mcp.ToolSchema{Type: "object"}},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{IsError: true}, nil
+		})
+	s.registerSession("a", (&recorder{}).send)
+	ctx := withSessionID(context.Background(), "a")
+
+	response := s.Request(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      7,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"fail","_meta":{"traceparent":"00-client-span-01"}}`),
+	})
+	require.Nil(t, response.Error)
+	s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 8, Method: "unknown"})
+
+	require.Len(t, tracer.spans, 3)
+	request, tool, unknown := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	assert.Equal(t, "tools/call", request.name)
+	assert.Equal(t, "00-client-span-01", request.parent)
+	assert.Equal(t, map[string]string{
+		"mcp.method":     "tools/call",
+		"mcp.request.id": "7",
+		"mcp.session.id": "a",
+	}, request.attributes)
+	assert.True(t, request.ended)
+	assert.NoError(t, request.err)
+
+	assert.Equal(t, "tool fail", tool.name)
+	assert.Equal(t, "tools/call", tool.parent)
+	assert.Equal(t, "fail", tool.attributes["mcp.tool.name"])
+	assert.ErrorIs(t, tool.err, errToolResult)
+
+	assert.Empty(t, unknown.parent)
+	assert.EqualError(t, unknown.err, "method not found: unknown")
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// AddToolTyped registers a tool whose arguments are decoded into Args, a
+// struct. The input schema is derived from the exported fields of Args:
+// property names come from json tags, descriptions from description tags
+// and allowed values from comma separated enum tags. Fields without
+// omitempty and that are not pointers are required. Calls missing a
+// required top level field or passing values of the wrong type fail before
+// handler runs.
+func AddToolTyped[Args any](
+	s MCPServer,
+	name, description string,
+	handler func(ctx context.Context, args Args) (*mcp.CallToolResult, error),
+) error {
+	argsType := reflect.TypeFor[Args]()
+	for argsType.Kind() == reflect.Pointer {
+		argsType = argsType.Elem()
+	}
+	if argsType.Kind() != reflect.Struct {
+		return fmt.Errorf("arguments of tool %s must be a struct, got %s", name, argsType)
+	}
+
+	properties, required := structSchema(argsType)
+	s.AddTool(mcp.ToolDefinition{
+		Name:        name,
+		Description: description,
+		InputSchema: mcp.ToolSchema{
+			Type:       "object",
+			Properties: properties,
+		},
+	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.Params.Arguments
+		for _, field := range required {
+			if _, ok := arguments[field]; !ok {
+				return nil, invalidParams("invalid arguments: missing required field %s", field)
+			}
+		}
+
+		data, err := json.Marshal(arguments)
+		if err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		var args Args
+		if err := json.Unmarshal(data, &args); err != nil {
+			return nil, invalidParams("invalid arguments: %v", err)
+		}
+		return handler(ctx, args)
+	})
+	return nil
+}
+
+// structSchema returns the JSON Schema properties of the fields of t and
+// the names of the required ones
+func structSchema(t reflect.Type) (mcp.ToolInputSchemaProperties, []string) {
+	properties := mcp.ToolInputSchemaProperties{}
+	required := []string{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, omitempty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		schema := typeSchema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := []interface{}{}
+			for _, value := range strings.Split(enum, ",") {
+				values = append(values, value)
+			}
+			schema["enum"] = values
+		}
+		properties[name] = schema
+
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+	return properties, required
+}
+
+// jsonFieldName returns the name encoding/json uses for field
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// typeSchema returns the JSON Schema of values of type t
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		properties, required := structSchema(t)
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetArgs struct {
+	Name     string   `json:"name" description:"Who to greet"`
+	Language string   `json:"language,omitempty" enum:"en,fr"`
+	Times    *int     `json:"times"`
+	Tags     []string `json:"tags,omitempty"`
+	internal string
+}
+
+func TestAddToolTyped(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0")
+	ctx := context.Background()
+
+	err := AddToolTyped(s, "greet", "Greets someone",
+		func(ctx context.Context, args greetArgs) (*mcp.CallToolResult, error) {
+			times := 1
+			if args.Times != nil {
+				times = *args.Times
+			}
+			return &mcp.CallToolResult{
+				Content: []interface{}{mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("hello %s x%d %s", args.Name, times, args.Language),
+				}},
+			}, nil
+		})
+	require.NoError(t, err)
+
+	list := s.Request(ctx, JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.Nil(t, list.Error)
+	tools := list.Result.(*mcp.ListToolDefinitionsResult).Tools
+	require.Len(t, tools, 1)
+	assert.Equal(t, mcp.ToolInputSchemaProperties{
+		"name":     {"type": "string", "description": "Who to greet"},
+		"language": {"type": "string", "enum": []interface{}{"en", "fr"}},
+		"times":    {"type": "integer"},
+		"tags":     {"type": "array", "items": map[string]interface{}{"type": "string"}},
+	}, tools[0].InputSchema.Properties)
+
+	greet := callTool(t, s, ctx, `{"name":"greet","arguments":{"name":"world","times":2,"language":"fr"}}`)
+	require.Nil(t, greet.Error)
+	assert.Equal(t, "hello world x2 fr", greet.Result.(*mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	missing := callTool(t, s, ctx, `{"name":"greet","arguments":{"times":2}}`)
+	require.NotNil(t, missing.Error)
+	assert.Contains(t, missing.Error.Message, "missing required field name")
+
+	wrongType := callTool(t, s, ctx, `{"name":"greet","arguments":{"name":"world","times":"two"}}`)
+	require.NotNil(t, wrongType.Error)
+	assert.Contains(t, wrongType.Error.Message, "invalid arguments")
+
+	assert.Error(t, AddToolTyped(s, "bad", "", func(ctx context.Context, args string) (*mcp.CallToolResult, error) {
+		return nil, nil
+	}))
+}
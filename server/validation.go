@@ -0,0 +1,255 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// SchemaValidator checks the arguments of a tools/call against the input
+// schema of the tool before its handler runs
+type SchemaValidator interface {
+	Validate(schema mcp.ToolSchema, arguments map[string]interface{}) error
+}
+
+// SchemaValidatorFunc adapts a function to a SchemaValidator
+type SchemaValidatorFunc func(schema mcp.ToolSchema, arguments map[string]interface{}) error
+
+func (f SchemaValidatorFunc) Validate(schema mcp.ToolSchema, arguments map[string]interface{}) error {
+	return f(schema, arguments)
+}
+
+// WithSchemaValidator replaces the validator that checks the arguments of
+// tools added with AddTool or to a session. The default one understands
+// the type, enum, properties, required, items, additionalProperties,
+// minimum, maximum, minLength and maxLength keywords. A nil validator
+// turns validation off.
+func WithSchemaValidator(validator SchemaValidator) ServerOption {
+	return func(s *DefaultServer) {
+		s.validator = validator
+	}
+}
+
+// ValidationError reports tool arguments that do not match the input
+// schema of the tool. It is answered with the invalid params error code
+// and the problems in the error data.
+type ValidationError struct {
+	Tool string
+	// Problems describes each mismatch, prefixed with the path of the
+	// argument at fault
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %s: %s", e.Tool, strings.Join(e.Problems, "; "))
+}
+
+// validateArguments runs the validator of the server over the arguments
+// of a call to tool
+func (s *DefaultServer) validateArguments(tool mcp.ToolDefinition, arguments map[string]interface{}) error {
+	if s.validator == nil {
+		return nil
+	}
+	err := s.validator.Validate(tool.InputSchema, arguments)
+	if err == nil {
+		return nil
+	}
+	if validationErr, ok := err.(*ValidationError); ok {
+		validationErr.Tool = tool.Name
+		return validationErr
+	}
+	return &ValidationError{Tool: tool.Name, Problems: []string{err.Error()}}
+}
+
+// basicValidator is the default SchemaValidator, covering the JSON Schema
+// keywords tools commonly declare
+type basicValidator struct{}
+
+func (basicValidator) Validate(schema mcp.ToolSchema, arguments map[string]interface{}) error {
+	// Calls may leave out the arguments of tools that take none
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	var problems []string
+	validateValue("arguments", schema.Map(), toJSONValue(arguments), &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateValue appends to problems every way value at path breaks schema
+func validateValue(path string, schema map[string]interface{}, value interface{}, problems *[]string) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := stringList(schema["type"]); len(types) > 0 &&
+		!slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		fail("expected %s, got %s", strings.Join(types, " or "), typeName(value))
+		return
+	}
+	if enum, ok := schema["enum"]; ok {
+		allowed := reflect.ValueOf(enum)
+		if allowed.Kind() == reflect.Slice && !slices.ContainsFunc(sliceValues(allowed), func(v interface{}) bool {
+			return reflect.DeepEqual(toJSONValue(v), value)
+		}) {
+			fail("must be one of %v", enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if limit, ok := number(schema["minLength"]); ok && length < limit {
+			fail("must be at least %v characters", limit)
+		}
+		if limit, ok := number(schema["maxLength"]); ok && length > limit {
+			fail("must be at most %v characters", limit)
+		}
+	case float64:
+		if limit, ok := number(schema["minimum"]); ok && v < limit {
+			fail("must be at least %v", limit)
+		}
+		if limit, ok := number(schema["maximum"]); ok && v > limit {
+			fail("must be at most %v", limit)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateValue(fmt.Sprintf("%s[%d]", path, i), items, item, problems)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %s", name)
+			}
+		}
+		properties := schemaMap(schema["properties"])
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name]; ok {
+				validateValue(path+"."+name, property, v[name], problems)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("unexpected property %s", name)
+				}
+			case map[string]interface{}:
+				validateValue(path+"."+name, additional, v[name], problems)
+			}
+		}
+	}
+}
+
+// toJSONValue turns value into what decoding its JSON encoding gives, so
+// arguments built in Go compare like those that came over the wire
+func toJSONValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if json.Unmarshal(data, &decoded) != nil {
+		return value
+	}
+	return decoded
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return typeName(value) == t
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// stringList reads a keyword that holds a string or a list of them, as
+// written in Go or decoded from JSON
+func stringList(keyword interface{}) []string {
+	switch k := keyword.(type) {
+	case string:
+		if k == "" {
+			return nil
+		}
+		return []string{k}
+	case []string:
+		return k
+	case []interface{}:
+		list := make([]string, 0, len(k))
+		for _, item := range k {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// schemaMap reads the properties keyword, as written in Go or decoded
+// from JSON
+func schemaMap(keyword interface{}) map[string]map[string]interface{} {
+	switch k := keyword.(type) {
+	case map[string]map[string]interface{}:
+		return k
+	case map[string]interface{}:
+		properties := make(map[string]map[string]interface{}, len(k))
+		for name, property := range k {
+			if p, ok := property.(map[string]interface{}); ok {
+				properties[name] = p
+			}
+		}
+		return properties
+	}
+	return nil
+}
+
+func sliceValues(v reflect.Value) []interface{} {
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+func number(keyword interface{}) (float64, bool) {
+	n, ok := toJSONValue(keyword).(float64)
+	return n, ok
+}
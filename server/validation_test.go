@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicValidator(t *testing.T) {
+	schema := mcp.ToolSchema{
+		Type: "object",
+		Properties: mcp.ToolInputSchemaProperties{
+			"name":  {"type": "string", "minLength": 1, "maxLength": 5},
+			"count": {"type": "integer", "minimum": 1, "maximum": 10},
+			"mode":  {"type": "string", "enum": []interface{}{"fast", "slow"}},
+			"tags":  {"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"point": {
+				"type":                 "object",
+				"required":             []string{"x"},
+				"properties":           map[string]interface{}{"x": map[string]interface{}{"type": "number"}},
+				"additionalProperties": false,
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		problems  []string
+	}{
+		{
+			name: "Valid",
+			arguments: map[string]interface{}{
+				"name":  "abc",
+				"count": 3,
+				"mode":  "fast",
+				"tags":  []string{"a", "b"},
+				"point": map[string]interface{}{"x": 1.5},
+				"other": true,
+			},
+		},
+		{
+			name: "NoArguments",
+		},
+		{
+			name:      "WrongType",
+			arguments: map[string]interface{}{"name": 1, "count": 1.5},
+			problems: []string{
+				"arguments.count: expected integer, got number",
+				"arguments.name: expected string, got number",
+			},
+		},
+		{
+			name:      "Bounds",
+			arguments: map[string]interface{}{"name": "", "count": 11},
+			problems: []string{
+				"arguments.count: must be at most 10",
+				"arguments.name: must be at least 1 characters",
+			},
+		},
+		{
+			name:      "Enum",
+			arguments: map[string]interface{}{"mode": "medium"},
+			problems:  []string{"arguments.mode: must be one of [fast slow]"},
+		},
+		{
+			name:      "Items",
+			arguments: map[string]interface{}{"tags": []interface{}{"a", 2}},
+			problems:  []string{"arguments.tags[1]: expected string, got number"},
+		},
+		{
+			name:      "Nested",
+			arguments: map[string]interface{}{"point": map[string]interface{}{"y": 1}},
+			problems: []string{
+				"arguments.point: missing required property x",
+				"arguments.point: unexpected property y",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := basicValidator{}.Validate(schema, tt.arguments)
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.problems, validationErr.Problems)
+		})
+	}
+}
+
+func TestDefaultServer_ValidatesArguments(t *testing.T) {
+	add := func(s MCPServer) {
+		s.AddTool(mcp.ToolDefinition{
+			Name: "repeat",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: mcp.ToolInputSchemaProperties{"times": {"type": "integer"}},
+			},
+		}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		})
+	}
+	ctx := context.Background()
+
+	s := NewDefaultServer("test", "1.0.0")
+	add(s)
+	response := callTool(t, s, ctx, `{"name":"repeat","arguments":{"times":"twice"}}`)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, mcp.InvalidParams, response.Error.Code)
+	assert.Equal(t, "invalid arguments for tool repeat: arguments.times: expected integer, got string", response.Error.Message)
+	assert.Equal(t, []string{"arguments.times: expected integer, got string"},
+		response.Error.Data.(map[string]any)["problems"])
+	response = callTool(t, s, ctx, `{"name":"repeat","arguments":{"times":2}}`)
+	assert.Nil(t, response.Error)
+
+	// Keywords of decoded schemas besides type and properties are checked
+	var strict mcp.ToolDefinition
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "strict", "inputSchema": {
+		"type": "object",
+		"properties": {"times": {"type": "integer"}},
+		"required": ["times"],
+		"additionalProperties": false
+	}}`), &strict))
+	s.AddTool(strict, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	response = callTool(t, s, ctx, `{"name":"strict","arguments":{"other":1}}`)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, []string{
+		"arguments: missing required property times",
+		"arguments: unexpected property other",
+	}, response.Error.Data.(map[string]any)["problems"])
+
+	s = NewDefaultServer("test", "1.0.0", WithSchemaValidator(SchemaValidatorFunc(
+		func(schema mcp.ToolSchema, arguments map[string]interface{}) error {
+			return errors.New("rejected")
+		},
+	)))
+	add(s)
+	response = callTool(t, s, ctx, `{"name":"repeat","arguments":{"times":2}}`)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, mcp.InvalidParams, response.Error.Code)
+	assert.Equal(t, []string{"rejected"}, response.Error.Data.(map[string]any)["problems"])
+
+	s = NewDefaultServer("test", "1.0.0", WithSchemaValidator(nil))
+	add(s)
+	response = callTool(t, s, ctx, `{"name":"repeat","arguments":{"times":"twice"}}`)
+	assert.Nil(t, response.Error)
+}
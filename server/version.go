@@ -0,0 +1,44 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/huangyul/go-mcp/mcp"
+)
+
+// WithProtocolVersions sets the protocol versions the server speaks,
+// newest first. By default it speaks mcp.SupportedProtocolVersions.
+func WithProtocolVersions(versions ...string) ServerOption {
+	return func(s *DefaultServer) {
+		s.protocolVersions = versions
+	}
+}
+
+// negotiateProtocolVersion returns the version the client requested when
+// the server speaks it, and the newest version the server speaks otherwise
+func (s *DefaultServer) negotiateProtocolVersion(requested string) string {
+	versions := s.protocolVersions
+	if len(versions) == 0 {
+		versions = mcp.SupportedProtocolVersions
+	}
+	if slices.Contains(versions, requested) {
+		return requested
+	}
+	return versions[0]
+}
+
+// ProtocolVersion returns the protocol version agreed on when the client
+// initialized, or an empty string before that
+func (s *Session) ProtocolVersion() string {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	return s.client.protocolVersion
+}
+
+// supportsProtocolVersion reports whether the version agreed on with the
+// client is min or newer
+func (c *clientSession) supportsProtocolVersion(min string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return mcp.ProtocolVersionAtLeast(c.protocolVersion, min)
+}
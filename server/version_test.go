@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/huangyul/go-mcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultServer_ProtocolVersionNegotiation(t *testing.T) {
+	s := NewDefaultServer("test", "1.0.0").(*DefaultServer)
+	s.registerSession("a", func(message any) error { return nil })
+	ctx := withSessionID(context.Background(), "a")
+
+	initialize := func(s MCPServer, requested string) string {
+		response := s.Request(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params: json.RawMessage(`{
+				"capabilities": {},
+				"clientInfo": {"name": "test-client", "version": "1.0.0"},
+				"protocolVersion": "` + requested + `"
+			}`),
+		})
+		require.Nil(t, response.Error)
+		return response.Result.(*mcp.InitializeResult).ProtocolVersion
+	}
+
+	assert.Equal(t, mcp.ProtocolVersion20241105, initialize(s, mcp.ProtocolVersion20241105))
+	session, _ := s.Session("a")
+	assert.Equal(t, mcp.ProtocolVersion20241105, session.ProtocolVersion())
+
+	assert.Equal(t, mcp.ProtocolVersion20250326, initialize(s, mcp.ProtocolVersion20250326))
+	assert.Equal(t, mcp.ProtocolVersion20250326, session.ProtocolVersion())
+	assert.Equal(t, mcp.LatestProtocolVersion, initialize(s, "2099-01-01"))
+
+	limited := NewDefaultServer("test", "1.0.0", WithProtocolVersions(mcp.ProtocolVersion20241105))
+	assert.Equal(t, mcp.ProtocolVersion20241105, initialize(limited, mcp.ProtocolVersion20250618))
+
+	// Handlers that leave the version out get the negotiated one
+	limited.HandleInitialize(func(ctx context.Context, capabilities mcp.ClientCapabilities, clientInfo mcp.Implementation, protocolVersion string) (*mcp.InitializeResult, error) {
+		return &mcp.InitializeResult{ServerInfo: clientInfo}, nil
+	})
+	assert.Equal(t, mcp.ProtocolVersion20241105, initialize(limited, mcp.ProtocolVersion20250618))
+}
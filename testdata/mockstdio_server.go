@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type JSONRPCRequest struct {
@@ -25,6 +28,13 @@ type JSONRPCResponse struct {
 }
 
 func main() {
+	// --lsp switches to Content-Length framing, like servers built on LSP
+	// plumbing
+	if len(os.Args) > 1 && os.Args[1] == "--lsp" {
+		serveLSP()
+		return
+	}
+
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
 		var request JSONRPCRequest
@@ -32,12 +42,98 @@ func main() {
 			continue
 		}
 
+		// Notifications never get a response
+		if strings.HasPrefix(request.Method, "notifications/") {
+			continue
+		}
+
+		// The crash tool makes the server exit without answering, for
+		// testing restarts
+		if request.Method == "tools/call" && strings.Contains(string(request.Params), `"crash"`) {
+			os.Exit(1)
+		}
+
+		// ping_client pings the client and returns its answer as the result
+		if request.Method == "tools/call" && strings.Contains(string(request.Params), `"ping_client"`) {
+			fmt.Fprintf(os.Stdout, "%s\n", `{"jsonrpc":"2.0","id":"server-1","method":"ping"}`)
+			answer := ""
+			if scanner.Scan() {
+				answer = scanner.Text()
+			}
+			responseBytes, _ := json.Marshal(JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      request.ID,
+				Result: map[string]interface{}{
+					"content": []map[string]interface{}{
+						{"type": "text", "text": answer},
+					},
+				},
+			})
+			fmt.Fprintf(os.Stdout, "%s\n", responseBytes)
+			continue
+		}
+
+		// duplicate answers twice, like a misbehaving server
+		if request.Method == "tools/call" && strings.Contains(string(request.Params), `"duplicate"`) {
+			responseBytes, _ := json.Marshal(handleRequest(request))
+			fmt.Fprintf(os.Stdout, "%s\n%s\n", responseBytes, responseBytes)
+			continue
+		}
+
+		// Confirm a new log level with a log entry, like a real server would
+		if request.Method == "logging/setLevel" {
+			fmt.Fprintf(
+				os.Stdout,
+				"%s\n",
+				`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"warning","logger":"mock","data":"level changed"}}`,
+			)
+		}
+
 		response := handleRequest(request)
 		responseBytes, _ := json.Marshal(response)
 		fmt.Fprintf(os.Stdout, "%s\n", responseBytes)
 	}
 }
 
+func serveLSP() {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		length := -1
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				break
+			}
+			if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+				length, _ = strconv.Atoi(strings.TrimSpace(value))
+			}
+		}
+		if length < 0 {
+			return
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			continue
+		}
+		if strings.HasPrefix(request.Method, "notifications/") {
+			continue
+		}
+
+		responseBytes, _ := json.Marshal(handleRequest(request))
+		fmt.Fprintf(os.Stdout, "Content-Length: %d\r\n\r\n%s", len(responseBytes), responseBytes)
+	}
+}
+
 func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 	response := JSONRPCResponse{
 		JSONRPC: "2.0",
@@ -76,6 +172,15 @@ func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 				},
 			},
 		}
+	case "resources/templates/list":
+		response.Result = map[string]interface{}{
+			"resourceTemplates": []map[string]interface{}{
+				{
+					"name":        "test-template",
+					"uriTemplate": "test://{id}",
+				},
+			},
+		}
 	case "resources/read":
 		response.Result = map[string]interface{}{
 			"contents": []map[string]interface{}{
@@ -130,9 +235,17 @@ func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 	case "logging/setLevel":
 		response.Result = struct{}{}
 	case "completion/complete":
+		// Echo the reference back so clients can check how it was built
+		var params struct {
+			Ref map[string]string `json:"ref"`
+		}
+		json.Unmarshal(request.Params, &params)
 		response.Result = map[string]interface{}{
 			"completion": map[string]interface{}{
-				"values": []string{"test completion"},
+				"values": []string{
+					"test completion",
+					params.Ref["type"] + " " + params.Ref["name"] + params.Ref["uri"],
+				},
 			},
 		}
 	default:
@@ -0,0 +1,274 @@
+// Package transporttest is a conformance suite for client.Transport
+// implementations. A transport under test is connected to a peer that
+// echoes every message back unchanged; the suite checks connecting and
+// disconnecting, ordering, concurrency, cancellation and large messages.
+package transporttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huangyul/go-mcp/client"
+)
+
+// NewEchoTransport returns a transport that has not been started yet and
+// is connected to a peer echoing every message it receives
+type NewEchoTransport func(t *testing.T) client.Transport
+
+// Timeout bounds how long the suite waits for any single message
+var Timeout = 5 * time.Second
+
+// LargeMessageSize is the size of the payload of the large message test
+var LargeMessageSize = 4 << 20
+
+// Run runs the whole suite as subtests of t
+func Run(t *testing.T, newTransport NewEchoTransport) {
+	t.Run("StartAndClose", func(t *testing.T) { testStartAndClose(t, newTransport) })
+	t.Run("CloseWithoutStart", func(t *testing.T) { testCloseWithoutStart(t, newTransport) })
+	t.Run("Echo", func(t *testing.T) { testEcho(t, newTransport) })
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, newTransport) })
+	t.Run("ConcurrentSend", func(t *testing.T) { testConcurrentSend(t, newTransport) })
+	t.Run("CancelledSend", func(t *testing.T) { testCancelledSend(t, newTransport) })
+	t.Run("SendAfterClose", func(t *testing.T) { testSendAfterClose(t, newTransport) })
+	t.Run("LargeMessage", func(t *testing.T) { testLargeMessage(t, newTransport) })
+}
+
+func start(t *testing.T, newTransport NewEchoTransport) client.Transport {
+	t.Helper()
+	transport := newTransport(t)
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := transport.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	return transport
+}
+
+func send(t *testing.T, transport client.Transport, message string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := transport.Send(ctx, json.RawMessage(message)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+}
+
+func receive(t *testing.T, transport client.Transport) json.RawMessage {
+	t.Helper()
+	select {
+	case message, ok := <-transport.Receive():
+		if !ok {
+			t.Fatal("Receive channel closed early")
+		}
+		return message
+	case <-time.After(Timeout):
+		t.Fatal("timed out waiting for a message")
+		return nil
+	}
+}
+
+// sameJSON compares messages by value, transports may reformat them
+func sameJSON(t *testing.T, want string, got json.RawMessage) {
+	t.Helper()
+	var wantValue, gotValue any
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected message: %v", err)
+	}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("received invalid JSON: %v", err)
+	}
+	if fmt.Sprint(wantValue) != fmt.Sprint(gotValue) {
+		t.Fatalf("received %s, want %s", got, want)
+	}
+}
+
+func message(id int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id)
+}
+
+func testStartAndClose(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	if err := transport.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := transport.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	waitClosed(t, transport)
+}
+
+func testCloseWithoutStart(t *testing.T, newTransport NewEchoTransport) {
+	transport := newTransport(t)
+	if err := transport.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	waitClosed(t, transport)
+}
+
+// waitClosed drains Receive until it is closed
+func waitClosed(t *testing.T, transport client.Transport) {
+	t.Helper()
+	deadline := time.After(Timeout)
+	for {
+		select {
+		case _, ok := <-transport.Receive():
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Receive channel not closed after Close")
+		}
+	}
+}
+
+func testEcho(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	pretty := "{\n  \"jsonrpc\": \"2.0\",\n  \"id\": 1,\n  \"method\": \"ping\"\n}"
+	send(t, transport, pretty)
+	sameJSON(t, pretty, receive(t, transport))
+}
+
+func testOrdering(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	const count = 100
+
+	// Receive alongside sending, a transport may not buffer them all
+	received := make(chan json.RawMessage, count)
+	go func() {
+		for range count {
+			select {
+			case message, ok := <-transport.Receive():
+				if !ok {
+					return
+				}
+				received <- message
+			case <-time.After(Timeout):
+				return
+			}
+		}
+	}()
+
+	for i := range count {
+		send(t, transport, message(i))
+	}
+	for i := range count {
+		select {
+		case got := <-received:
+			sameJSON(t, message(i), got)
+		case <-time.After(Timeout):
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+}
+
+func testConcurrentSend(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	const senders, perSender = 8, 25
+
+	seen := make(map[string]bool)
+	var mu sync.Mutex
+	receiverDone := make(chan struct{})
+	go func() {
+		defer close(receiverDone)
+		for range senders * perSender {
+			select {
+			case message, ok := <-transport.Receive():
+				if !ok {
+					return
+				}
+				var decoded struct {
+					ID int `json:"id"`
+				}
+				if json.Unmarshal(message, &decoded) == nil {
+					mu.Lock()
+					seen[fmt.Sprint(decoded.ID)] = true
+					mu.Unlock()
+				}
+			case <-time.After(Timeout):
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, senders*perSender)
+	for s := range senders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perSender {
+				err := transport.Send(context.Background(), json.RawMessage(message(s*perSender+i)))
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent Send failed: %v", err)
+	}
+
+	<-receiverDone
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != senders*perSender {
+		t.Fatalf("received %d distinct messages, want %d", len(seen), senders*perSender)
+	}
+}
+
+func testCancelledSend(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := transport.Send(ctx, json.RawMessage(message(1))); err == nil {
+		t.Fatal("Send with a cancelled context succeeded")
+	}
+
+	// The transport keeps working for other callers
+	send(t, transport, message(2))
+	sameJSON(t, message(2), receive(t, transport))
+}
+
+func testSendAfterClose(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	if err := transport.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := transport.Send(context.Background(), json.RawMessage(message(1))); err == nil {
+		t.Fatal("Send after Close succeeded")
+	}
+}
+
+func testLargeMessage(t *testing.T, newTransport NewEchoTransport) {
+	transport := start(t, newTransport)
+	large := fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"result":{"text":%q}}`,
+		strings.Repeat("x", LargeMessageSize),
+	)
+
+	// Receive alongside sending, pipes may not hold the whole message
+	received := make(chan json.RawMessage, 1)
+	go func() {
+		select {
+		case message := <-transport.Receive():
+			received <- message
+		case <-time.After(Timeout):
+			received <- nil
+		}
+	}()
+
+	send(t, transport, large)
+	got := <-received
+	if len(got) == 0 {
+		t.Fatal("large message was not echoed")
+	}
+	sameJSON(t, large, got)
+}
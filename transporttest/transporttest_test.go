@@ -0,0 +1,36 @@
+package transporttest
+
+import (
+	"bufio"
+	"io"
+	"testing"
+
+	"github.com/huangyul/go-mcp/client"
+)
+
+func TestPipeTransport(t *testing.T) {
+	Run(t, func(t *testing.T) client.Transport {
+		toPeer, fromClient := io.Pipe()
+		toClient, fromPeer := io.Pipe()
+
+		// The peer echoes every line until the client closes its end
+		go func() {
+			defer fromPeer.Close()
+			reader := bufio.NewReader(toPeer)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if len(line) > 0 {
+					if _, werr := fromPeer.Write(line); werr != nil {
+						return
+					}
+				}
+				if err != nil {
+					toPeer.CloseWithError(err)
+					return
+				}
+			}
+		}()
+
+		return client.NewPipeTransport(toClient, fromClient)
+	})
+}